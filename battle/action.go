@@ -1,6 +1,10 @@
 package battle
 
-import "github.com/hajimehoshi/ebiten/v2"
+import (
+	"github.com/jessdwitch/spiders/engine/audio"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
 
 type (
 	// actioner : Performs a combat action
@@ -13,9 +17,69 @@ type (
 		icon ebiten.Image
 		executor *pawn
 		targets []*pawn
+		// sound : Played when the action is carried out. Optional.
+		sound audio.ClipID
+		// shake : How hard the camera shakes when the action lands, in screen pixels, as for big
+		//	hits. Optional.
+		shake float64
 	}
 )
 
+const (
+	// hitShakeTicks : How long a shake takes to settle
+	hitShakeTicks = 15
+	// shakePerDamage : How hard each point of damage shakes the camera, in screen pixels, so
+	//	bigger hits land harder
+	shakePerDamage = 1.5
+	// sfxHit : Played when an attack lands
+	sfxHit audio.ClipID = "hit"
+)
+
+// newAttack : An action dealing damage to each target. The camera shakes harder the more it deals.
+func newAttack(executor *pawn, damage int, targets ...*pawn) *queuedAction {
+	return &queuedAction{
+		act: func(b *BattleScene, _ *pawn, targets ...*pawn) error {
+			for _, t := range targets {
+				t.takeDamage(damage)
+				t.showDamage(b, damage)
+			}
+			return nil
+		},
+		executor: executor,
+		targets:  targets,
+		sound:    sfxHit,
+		shake:    float64(damage) * shakePerDamage,
+	}
+}
+
+// action : Carry out the action. If the executor has a sprite, it lunges at its first target, and
+// the action lands at the end of the lunge.
 func (q *queuedAction) action(b *BattleScene) error {
-	return q.act(b, q.executor, q.targets...)
+	land := func() error {
+		if q.sound != "" {
+			if err := b.gameState.PlaySFX(q.sound); err != nil {
+				return err
+			}
+		}
+		if q.shake > 0 {
+			b.camera.Shake(q.shake, hitShakeTicks)
+		}
+		if err := q.act(b, q.executor, q.targets...); err != nil {
+			return err
+		}
+		for _, t := range q.targets {
+			if err := t.showHit(b); err != nil {
+				return err
+			}
+			if err := t.showSparks(b); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if q.executor == nil || q.executor.sprite == nil || len(q.targets) == 0 || q.targets[0].sprite == nil {
+		return land()
+	}
+	b.tweens.Add(q.executor.lunge(q.targets[0].sprite.GetPosition(), land))
+	return nil
 }
@@ -1,5 +1,15 @@
 package battle
 
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/fs"
+	"strconv"
+
+	"github.com/jessdwitch/spiders/engine/render"
+)
+
 type (
 	// routiner : Given the current battle state, choose a course an action
 	routiner interface {
@@ -7,21 +17,120 @@ type (
 	}
 	routineReason int
 	enemy struct {
-		id int
+		id   int
 		name string
 	}
+	// EnemyMeta : Static data for an AI pawn
+	EnemyMeta struct {
+		ID        int
+		Name      string
+		Sprite    render.SpriteID
+		MaxHealth int
+	}
+	// EnemyTable : Static data for every AI pawn, by ID
+	EnemyTable map[int]EnemyMeta
 )
 
 func (e *enemy) routine(b *BattleScene, event turnEvent) (actioner, error) {
 	return nil, nil
 }
 
-func newEnemiesFromIDs(ids []int) ([]pawn, error) {
+// Sprites : The sprites a battle against the given AI pawns uses, for preloading
+func (t EnemyTable) Sprites(ids []int) []render.SpriteID {
+	result := []render.SpriteID{}
+	seen := map[render.SpriteID]bool{}
+	for _, id := range ids {
+		if meta, ok := t[id]; ok && !seen[meta.Sprite] {
+			seen[meta.Sprite] = true
+			result = append(result, meta.Sprite)
+		}
+	}
+	return result
+}
+
+// NewEnemyTable : Read AI pawn data from a pawn manifest
+func NewEnemyTable(manifest *csv.Reader) (EnemyTable, error) {
+	result := EnemyTable{}
+	// strip header
+	_, err := manifest.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("pawn manifest is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+	for {
+		record, err := manifest.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		err = result.processManifestCsvRecord(record)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (t EnemyTable) processManifestCsvRecord(record []string) error {
+	// record: id, name, sprite, maxHealth
+	if len(record) != 4 {
+		return fmt.Errorf("pawn manifest record %v has %d columns, want 4", record, len(record))
+	}
+	id, err := strconv.Atoi(record[0])
+	if err != nil {
+		return err
+	}
+	if _, ok := t[id]; ok {
+		return fmt.Errorf("pawn %d is defined more than once", id)
+	}
+	maxHealth, err := strconv.Atoi(record[3])
+	if err != nil {
+		return err
+	}
+	t[id] = EnemyMeta{
+		ID:        id,
+		Name:      record[1],
+		Sprite:    render.SpriteID(record[2]),
+		MaxHealth: maxHealth,
+	}
+	return nil
+}
+
+// LoadEnemyTable : Read a pawn manifest from a path in fsys
+func LoadEnemyTable(fsys fs.FS, path string) (EnemyTable, error) {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return NewEnemyTable(csv.NewReader(f))
+}
+
+// Reload : Replace the table's contents with a fresh read of the manifest. If the manifest can't
+// be read, the table is left as it was.
+func (t EnemyTable) Reload(fsys fs.FS, path string) error {
+	fresh, err := LoadEnemyTable(fsys, path)
+	if err != nil {
+		return err
+	}
+	for id := range t {
+		delete(t, id)
+	}
+	for id, e := range fresh {
+		t[id] = e
+	}
+	return nil
+}
+
+func (t EnemyTable) newEnemiesFromIDs(ids []int, sprites render.SpriteGetter) ([]pawn, error) {
 	var err error
 	result := make([]pawn, len(ids))
 	for i, id := range ids {
-		// TODO: Depending on how we store enemy data, this may want batching (as in reducing calls to Open by caching them in a map)
-		result[i], err = newEnemyFromID(id)
+		result[i], err = t.newEnemyFromID(id, sprites)
 		if err != nil {
 			return nil, err
 		}
@@ -29,6 +138,25 @@ func newEnemiesFromIDs(ids []int) ([]pawn, error) {
 	return result, nil
 }
 
-func newEnemyFromID(id int) (pawn, error) {
-	return pawn{}, nil
+func (t EnemyTable) newEnemyFromID(id int, sprites render.SpriteGetter) (pawn, error) {
+	meta, ok := t[id]
+	if !ok {
+		return pawn{}, fmt.Errorf("pawn %d not found", id)
+	}
+	sprite, err := sprites.GetSprite(meta.Sprite)
+	if err != nil {
+		return pawn{}, err
+	}
+	sprite.Scale(pawnScale, pawnScale)
+	if _, err = sprite.Animate("idle"); err != nil {
+		return pawn{}, err
+	}
+	return pawn{
+		name:          meta.Name,
+		maxHealth:     meta.MaxHealth,
+		currentHealth: meta.MaxHealth,
+		statuses:      []status{},
+		sprite:        sprite,
+		spriteID:      meta.Sprite,
+	}, nil
 }
@@ -6,15 +6,35 @@ import (
 
 	"github.com/jessdwitch/spiders/deck"
 	"github.com/jessdwitch/spiders/engine"
+	"github.com/jessdwitch/spiders/engine/audio"
 	"github.com/jessdwitch/spiders/engine/render"
+	"github.com/jessdwitch/spiders/engine/tween"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
-const maxPlayerPawns = 3
+const (
+	maxPlayerPawns = 3
+	// MusicID : Background music for battles
+	MusicID audio.ClipID = "battle_theme"
+)
+
+// Topics battles publish on
+const (
+	// TopicCardPlayed : The player played a card. The payload is its CardID, as a string.
+	TopicCardPlayed engine.Topic = "card played"
+	// TopicDeck : A card in the player's deck changed piles, or the deck reshuffled. The payload
+	//	is the deck.EventKind's name.
+	TopicDeck engine.Topic = "deck"
+	// TopicBattleOver : Every pawn on one side is knocked out. The payload is true if the player
+	//	won.
+	TopicBattleOver engine.Topic = "battle over"
+)
 
 type (
 	BattleScene struct {
+		gameState      *engine.GameState
+		sprites        render.SpriteGetter
 		background     *ebiten.Image
 		playerDeck     deck.Deck
 		playerHandSize int
@@ -24,6 +44,22 @@ type (
 		isPlayerTurn   bool
 		turnNumber     int
 		state          turnEvent
+		// handCursor, target : the card in hand and the AI pawn the player has picked
+		handCursor int
+		target     int
+		// tweens : motion in progress, as in pawns lunging
+		tweens tween.Runner
+		// graph : everything drawn, in order
+		graph render.SceneGraph
+		// camera : the view of the battlefield
+		camera *render.Camera
+		// deckEvents : changes to the player's deck, waiting on their triggers
+		deckEvents   []deck.Event
+		deckTriggers map[deck.EventKind][]deckTrigger
+		// recentDeckEvents : the last few deck events resolved, for the debug overlay
+		recentDeckEvents []deck.Event
+		// back : where confirm goes once the battle's over. If nil, the outcome stays up.
+		back engine.Scene
 	}
 	turnEvent int
 )
@@ -35,8 +71,29 @@ const (
 	turnResolving
 	turnEnd
 	takeDamage
+	// battleWon, battleLost : The battle's over, and stays that way
+	battleWon
+	battleLost
 )
 
+var turnEventNames = map[turnEvent]string{
+	invalidEvent:   "invalid",
+	turnStart:      "turn start",
+	turnInProgress: "turn in progress",
+	turnResolving:  "turn resolving",
+	turnEnd:        "turn end",
+	takeDamage:     "take damage",
+	battleWon:      "won",
+	battleLost:     "lost",
+}
+
+func (t turnEvent) String() string {
+	if name, ok := turnEventNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("turnEvent(%d)", int(t))
+}
+
 func (t turnEvent) next() turnEvent {
 	switch t {
 	case turnStart:
@@ -47,6 +104,8 @@ func (t turnEvent) next() turnEvent {
 		return turnEnd
 	case turnEnd:
 		return turnStart
+	case battleWon, battleLost:
+		return t
 	default:
 		return invalidEvent
 	}
@@ -55,16 +114,19 @@ func (t turnEvent) next() turnEvent {
 // NewBattleScene : Generate a new combat instance
 func NewBattleScene(
 	gameState *engine.GameState,
+	enemies EnemyTable,
+	sprites render.SpriteGetter,
 	background *ebiten.Image,
 	aiIDs []int,
 	playerStarts bool,
-	playerCards map[deck.CardID]int,
+	playerCards deck.DeckList,
+	back engine.Scene,
 ) (*BattleScene, error) {
 	// preflight checks
 	if len(aiIDs) == 0 {
 		return nil, errors.New("battle must have at least one AI pawn")
 	}
-	b := &BattleScene{}
+	b := &BattleScene{gameState: gameState, sprites: sprites, back: back}
 	playerPawns, err := newPawnsFromParty(gameState.PlayerParty)
 	if err != nil {
 		return nil, err
@@ -78,36 +140,106 @@ func NewBattleScene(
 	b.playerHandSize = 5
 	b.state = turnStart
 	b.background = background // TODO: Scale to screen
-	b.playerDeck, err = deck.NewDeckFromIDs(playerCards)
+	b.playerDeck, err = playerCards.Build(gameState.Cards)
 	if err != nil {
 		return nil, err
 	}
-	b.aiPawns, err = newEnemiesFromIDs(aiIDs)
+	b.playerDeck.Observe(deck.ObserverFunc(b.queueDeckEvent))
+	b.onDeck(deck.DeckReshuffled, showReshuffle)
+	b.aiPawns, err = enemies.newEnemiesFromIDs(aiIDs, sprites)
 	if err != nil {
 		return nil, err
 	}
+	if err = b.playerDeck.DrawCards(b.playerHandSize); err != nil {
+		return nil, err
+	}
 	b.isPlayerTurn = playerStarts
 	playerAxisStart, playerAxisEnd, aiAxisStart, aiAxisEnd := computeAxes(
 		gameState.Config.ScreenWidth, gameState.Config.ScreenHeight)
 	b.playerPawns.arrange(playerAxisStart, playerAxisEnd)
 	b.aiPawns.arrange(aiAxisStart, aiAxisEnd)
+	b.camera = render.NewCamera(gameState.Config.ScreenWidth, gameState.Config.ScreenHeight)
+	b.graph.SetCamera(b.camera)
+	b.graph.Add(render.DrawFunc(func(screen *ebiten.Image) {
+		screen.DrawImage(b.background, nil)
+	}), render.LayerBackground, 0)
+	b.playerPawns.addTo(&b.graph)
+	b.aiPawns.addTo(&b.graph)
 	return b, nil
 }
 
+// computeAxes : The lines each side's pawns stand along. The player's side is lower and to the
+// left, the AI's higher and to the right.
 func computeAxes(width, height int) (render.Point, render.Point, render.Point, render.Point) {
-	panic("not implemented") // TODO: Implement
+	w, h := float64(width), float64(height)
+	return render.Point{X: w * 0.05, Y: h * 0.55}, render.Point{X: w * 0.45, Y: h * 0.55},
+		render.Point{X: w * 0.5, Y: h * 0.25}, render.Point{X: w * 0.95, Y: h * 0.25}
 }
 
 // Update :
 func (b *BattleScene) Update(state *engine.GameState) error {
+	if err := b.resolveDeckEvents(); err != nil {
+		return err
+	}
+	switch {
+	case b.over():
+		if err := b.outcomeInput(state); err != nil {
+			return err
+		}
+	case b.isPlayerTurn:
+		if err := b.playerInput(state); err != nil {
+			return err
+		}
+	}
+	if err := b.tweens.Update(); err != nil {
+		return err
+	}
+	if err := b.camera.Update(); err != nil {
+		return err
+	}
+	if err := b.playerPawns.update(); err != nil {
+		return err
+	}
+	if err := b.aiPawns.update(); err != nil {
+		return err
+	}
+	return b.checkOver()
+}
+
+// Music : Battle theme
+func (b *BattleScene) Music() audio.ClipID {
+	return MusicID
+}
+
+// Leave : Release the pawns' sprites, so their sheets can be evicted
+func (b *BattleScene) Leave(_ *engine.GameState) error {
+	releaser, ok := b.sprites.(render.SpriteReleaser)
+	if !ok {
+		return nil
+	}
+	for _, side := range []pawns{b.playerPawns, b.aiPawns} {
+		for i := range side {
+			if side[i].spriteID == "" {
+				continue
+			}
+			if err := releaser.Release(side[i].spriteID); err != nil {
+				return err
+			}
+			side[i].spriteID = ""
+		}
+	}
 	return nil
 }
 
 // Draw : Render the BattleScene, including player and AI pawns, and player UI
 func (b *BattleScene) Draw(screen *ebiten.Image) {
 	screen.Clear()
-	screen.DrawImage(b.background, nil)
-	b.playerPawns.draw(screen)
+	b.graph.Draw(screen)
+	if b.over() {
+		b.drawOutcome(screen)
+		return
+	}
+	b.drawHand(screen)
 }
 
 func (b *BattleScene) transitionState() error {
@@ -0,0 +1,154 @@
+package battle
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/jessdwitch/spiders/deck"
+	"github.com/jessdwitch/spiders/engine"
+	"github.com/jessdwitch/spiders/engine/render"
+	"github.com/jessdwitch/spiders/engine/tween"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font/basicfont"
+)
+
+const (
+	// handTop : How far up from the bottom of the screen the hand is drawn
+	handTop = 40
+	// handCardSpace : Room for each card's name in the hand
+	handCardSpace = 120
+	// cardFlyTicks : How long a played card takes to fly from the hand to its target
+	cardFlyTicks = 20
+)
+
+var (
+	handColor   color.Color = color.White
+	cursorColor color.Color = color.RGBA{0xf0, 0xb1, 0xb1, 0xff}
+)
+
+// playerInput : Choose a card and a target, then play it; cancel ends the turn. Waits on any
+// motion in progress.
+func (b *BattleScene) playerInput(state *engine.GameState) error {
+	in := state.Input
+	if b.tweens.Busy() || !in.AnyJustPressed() {
+		return nil
+	}
+	hand := b.playerDeck.Len(deck.PileHand)
+	switch {
+	case in.IsJustPressed(engine.ButtonLeft) && hand > 0:
+		b.handCursor = (b.handCursor - 1 + hand) % hand
+		return state.PlaySFX(engine.SFXMove)
+	case in.IsJustPressed(engine.ButtonRight) && hand > 0:
+		b.handCursor = (b.handCursor + 1) % hand
+		return state.PlaySFX(engine.SFXMove)
+	case in.IsJustPressed(engine.ButtonUp):
+		b.moveTarget(-1)
+		return state.PlaySFX(engine.SFXMove)
+	case in.IsJustPressed(engine.ButtonDown):
+		b.moveTarget(1)
+		return state.PlaySFX(engine.SFXMove)
+	case in.IsJustPressed(engine.ButtonConfirm):
+		if hand == 0 || b.aiPawns[b.target].knockedOut() {
+			return nil
+		}
+		if err := state.PlaySFX(engine.SFXConfirm); err != nil {
+			return err
+		}
+		return b.playCard(b.handCursor, &b.aiPawns[b.target])
+	case in.IsJustPressed(engine.ButtonCancel):
+		if err := state.PlaySFX(engine.SFXCancel); err != nil {
+			return err
+		}
+		return b.endPlayerTurn()
+	}
+	return nil
+}
+
+// moveTarget : Step the target to the next AI pawn still standing
+func (b *BattleScene) moveTarget(dir int) {
+	n := len(b.aiPawns)
+	for range b.aiPawns {
+		b.target = (b.target + dir + n) % n
+		if !b.aiPawns[b.target].knockedOut() {
+			return
+		}
+	}
+}
+
+// playCard : Play the card at i in the hand on target. It goes to the discard, or the exhaust
+// pile if it exhausts.
+func (b *BattleScene) playCard(i int, target *pawn) error {
+	hand := b.playerDeck.Pile(deck.PileHand)
+	if i < 0 || i >= len(hand) {
+		return fmt.Errorf("no card %d in a hand of %d", i, len(hand))
+	}
+	card := hand[i]
+	var err error
+	if card.Has(deck.FlagExhaust) {
+		err = b.playerDeck.Exhaust(i)
+	} else {
+		err = b.playerDeck.Discard(i)
+	}
+	if err != nil {
+		return err
+	}
+	if b.handCursor >= b.playerDeck.Len(deck.PileHand) && b.handCursor > 0 {
+		b.handCursor--
+	}
+	if err = b.gameState.Publish(TopicCardPlayed, string(card.ID)); err != nil {
+		return err
+	}
+	attack := newAttack(nil, card.CurrentDamage(), target)
+	if target.sprite == nil {
+		return attack.action(b)
+	}
+	// The card flies from its place in the hand, and the attack lands when it gets there
+	from := b.camera.ScreenToWorld(b.handPosition(i))
+	to := target.sprite.GetPosition()
+	fly := b.newFloatingText(card.Name, from, handColor)
+	b.float(fly, tween.Sequence(
+		tween.Move(fly, to.X+pawnWidth/2-from.X, to.Y-from.Y, cardFlyTicks, tween.InOutQuad),
+		tween.Call(func() error { return attack.action(b) }),
+	))
+	return nil
+}
+
+// handPosition : Where the card at i in the hand is drawn on screen
+func (b *BattleScene) handPosition(i int) render.Point {
+	return render.Point{
+		X: float64(handCardSpace/4 + i*handCardSpace),
+		Y: float64(b.gameState.Config.ScreenHeight - handTop),
+	}
+}
+
+// endPlayerTurn : Discard the hand and draw a new one
+func (b *BattleScene) endPlayerTurn() error {
+	if err := b.playerDeck.DiscardHand(); err != nil {
+		return err
+	}
+	b.handCursor = 0
+	b.turnNumber++
+	return b.playerDeck.DrawCards(b.playerHandSize)
+}
+
+// drawHand : The costs and names of the cards in hand along the bottom of the screen, and a marker
+// over the target
+func (b *BattleScene) drawHand(screen *ebiten.Image) {
+	face := basicfont.Face7x13
+	for i, c := range b.playerDeck.Pile(deck.PileHand) {
+		clr := handColor
+		p := b.handPosition(i)
+		x, y := int(p.X), int(p.Y)
+		if i == b.handCursor {
+			clr = cursorColor
+			text.Draw(screen, "^", face, x, y+face.Height+2, cursorColor)
+		}
+		text.Draw(screen, fmt.Sprintf("[%d] %s", c.CurrentCost(), c.Name), face, x, y, clr)
+	}
+	if t := b.aiPawns[b.target]; t.sprite != nil && !t.knockedOut() {
+		p := b.camera.WorldToScreen(t.sprite.GetPosition())
+		text.Draw(screen, "v", face, int(p.X)+pawnWidth/2, int(p.Y)-face.Height, cursorColor)
+	}
+}
@@ -0,0 +1,140 @@
+// Battle state for the debug overlay, and console commands to bend it
+
+package battle
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jessdwitch/spiders/deck"
+	"github.com/jessdwitch/spiders/engine"
+)
+
+// DebugInfo : Turn state, pawn health and statuses, deck pile sizes, and recent deck events
+func (b *BattleScene) DebugInfo() []string {
+	turn := "ai"
+	if b.isPlayerTurn {
+		turn = "player"
+	}
+	lines := []string{fmt.Sprintf("turn %d (%s): %v", b.turnNumber, turn, b.state)}
+	for _, side := range []struct {
+		name  string
+		pawns pawns
+	}{{"player", b.playerPawns}, {"ai", b.aiPawns}} {
+		for _, p := range side.pawns {
+			lines = append(lines, fmt.Sprintf("%s %s: hp %d/%d%s", side.name, p.name, p.currentHealth, p.maxHealth, p.statusList()))
+		}
+	}
+	d := b.playerDeck
+	lines = append(lines, fmt.Sprintf(
+		"deck: draw %d  hand %d  discard %d  exhaust %d",
+		d.Len(deck.PileDraw), d.Len(deck.PileHand), d.Len(deck.PileDiscard), d.Len(deck.PileExhaust),
+	))
+	for _, e := range b.recentDeckEvents {
+		if e.Card == nil {
+			lines = append(lines, fmt.Sprintf("  %v", e.Kind))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("  %v %s: %v -> %v", e.Kind, e.Card.Name, e.From, e.To))
+	}
+	return lines
+}
+
+func (p *pawn) statusList() string {
+	if len(p.statuses) == 0 {
+		return ""
+	}
+	names := make([]string, len(p.statuses))
+	for i, s := range p.statuses {
+		names[i] = fmt.Sprintf("%T", s)
+	}
+	return " [" + strings.Join(names, ", ") + "]"
+}
+
+// Commands : Console commands for the battle in progress
+func (b *BattleScene) Commands() map[string]engine.Command {
+	return map[string]engine.Command{
+		"give": b.giveCommand,
+		"heal": b.healCommand,
+		"win":  b.winCommand,
+	}
+}
+
+// giveCommand : give card <id> [n]. Puts cards straight into the player's hand.
+func (b *BattleScene) giveCommand(state *engine.GameState, args []string) (string, error) {
+	if len(args) < 2 || len(args) > 3 || args[0] != "card" {
+		return "", fmt.Errorf("usage: give card <id> [n]")
+	}
+	card, err := state.Cards.GetCard(deck.CardID(args[1]))
+	if err != nil {
+		return "", err
+	}
+	n := 1
+	if len(args) == 3 {
+		if n, err = strconv.Atoi(args[2]); err != nil || n < 1 {
+			return "", fmt.Errorf("card count %q should be a positive number", args[2])
+		}
+	}
+	d := b.playerDeck
+	for i := 0; i < n; i++ {
+		// Added to the bottom of the discard, then moved to hand
+		if err = d.AddCard(card.NewInstance(), true); err != nil {
+			return "", err
+		}
+		if err = d.Move(deck.PileDiscard, d.Len(deck.PileDiscard)-1, deck.PileHand, d.Len(deck.PileHand)); err != nil {
+			return "", err
+		}
+	}
+	return fmt.Sprintf("gave %d %s", n, card.Name), nil
+}
+
+// healCommand : heal all|<name>. all is the player's party; a name can be either side's.
+func (b *BattleScene) healCommand(_ *engine.GameState, args []string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("usage: heal all|<pawn>")
+	}
+	healed := 0
+	for _, side := range []struct {
+		pawns  pawns
+		player bool
+	}{{b.playerPawns, true}, {b.aiPawns, false}} {
+		for i := range side.pawns {
+			p := &side.pawns[i]
+			if args[0] == "all" && !side.player || args[0] != "all" && p.name != args[0] {
+				continue
+			}
+			p.currentHealth = p.maxHealth
+			// Undo a knockout's grey
+			if p.sprite != nil {
+				p.sprite.SetEffect(nil)
+			}
+			healed++
+		}
+	}
+	if healed == 0 {
+		return "", fmt.Errorf("no pawn %q", args[0])
+	}
+	return fmt.Sprintf("healed %d", healed), nil
+}
+
+// winCommand : win battle. Knocks out every AI pawn, ending the battle.
+func (b *BattleScene) winCommand(_ *engine.GameState, args []string) (string, error) {
+	if len(args) != 1 || args[0] != "battle" {
+		return "", fmt.Errorf("usage: win battle")
+	}
+	if b.over() {
+		return "", fmt.Errorf("the battle's already %v", b.state)
+	}
+	for i := range b.aiPawns {
+		p := &b.aiPawns[i]
+		p.currentHealth = 0
+		if err := p.showHit(b); err != nil {
+			return "", err
+		}
+	}
+	if err := b.endBattle(true); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("knocked out %d", len(b.aiPawns)), nil
+}
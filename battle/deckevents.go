@@ -0,0 +1,66 @@
+package battle
+
+import (
+	"github.com/jessdwitch/spiders/deck"
+	"github.com/jessdwitch/spiders/engine/render"
+	"github.com/jessdwitch/spiders/engine/tween"
+)
+
+// deckEventHistory : How many deck events the debug overlay shows
+const deckEventHistory = 4
+
+// reshuffleText : What floats over the hand when the discard is shuffled back into the draw
+const reshuffleText = "Reshuffled"
+
+type (
+	// deckTrigger : An effect set off by a change to the player's deck, as in relics gaining
+	//	block whenever the deck reshuffles. Triggers may change the deck themselves; anything they
+	//	set off is resolved in the same update.
+	deckTrigger func(b *BattleScene, e deck.Event) error
+)
+
+// onDeck : Call trigger for every deck event of kind, in the order registered
+func (b *BattleScene) onDeck(kind deck.EventKind, trigger deckTrigger) {
+	if b.deckTriggers == nil {
+		b.deckTriggers = map[deck.EventKind][]deckTrigger{}
+	}
+	b.deckTriggers[kind] = append(b.deckTriggers[kind], trigger)
+}
+
+// queueDeckEvent : Observe the player's deck. The deck is mid-change when this is called, so
+// triggers wait for resolveDeckEvents.
+func (b *BattleScene) queueDeckEvent(e deck.Event) {
+	b.deckEvents = append(b.deckEvents, e)
+}
+
+// resolveDeckEvents : Run the triggers for every queued deck event, including those queued by
+// the triggers themselves
+func (b *BattleScene) resolveDeckEvents() error {
+	for len(b.deckEvents) > 0 {
+		e := b.deckEvents[0]
+		b.deckEvents = b.deckEvents[1:]
+		if b.recentDeckEvents = append(b.recentDeckEvents, e); len(b.recentDeckEvents) > deckEventHistory {
+			b.recentDeckEvents = b.recentDeckEvents[1:]
+		}
+		if err := b.gameState.Publish(TopicDeck, e.Kind.String()); err != nil {
+			return err
+		}
+		for _, trigger := range b.deckTriggers[e.Kind] {
+			if err := trigger(b, e); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// showReshuffle : Float a note up from the hand, so the player sees their discard come back
+func showReshuffle(b *BattleScene, _ deck.Event) error {
+	from := b.camera.ScreenToWorld(b.handPosition(0))
+	note := b.newFloatingText(reshuffleText, render.Point{X: from.X, Y: from.Y - handTop}, handColor)
+	b.float(note, tween.Parallel(
+		tween.Move(note, 0, -damageRise, damageTicks, tween.OutQuad),
+		tween.Sequence(tween.Delay(damageTicks/2), tween.FadeTo(note, 0, damageTicks/2, tween.InQuad)),
+	))
+	return nil
+}
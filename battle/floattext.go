@@ -0,0 +1,93 @@
+package battle
+
+import (
+	"image/color"
+	"strconv"
+
+	"github.com/jessdwitch/spiders/engine/render"
+	"github.com/jessdwitch/spiders/engine/tween"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font/basicfont"
+)
+
+const (
+	// damageRise : How far damage numbers float up, in world pixels
+	damageRise = 24
+	// damageTicks : How long damage numbers take to float up and fade
+	damageTicks = 30
+)
+
+var damageColor color.Color = color.RGBA{0xff, 0xe7, 0x62, 0xff}
+
+type (
+	// floatingText : Words at a point in the world, as in damage numbers and cards in flight.
+	//	Tweens move and fade it. It's drawn in the UI layer, so it's never scaled with the camera,
+	//	but it follows the camera's view.
+	floatingText struct {
+		text   string
+		pos    render.Point
+		clr    color.Color
+		alpha  float64
+		camera *render.Camera
+	}
+)
+
+func (b *BattleScene) newFloatingText(s string, pos render.Point, clr color.Color) *floatingText {
+	return &floatingText{text: s, pos: pos, clr: clr, alpha: 1, camera: b.camera}
+}
+
+// float : Draw t while motion plays, then stop drawing it
+func (b *BattleScene) float(t *floatingText, motion tween.Tween) {
+	node := b.graph.Add(t, render.LayerUI, 0)
+	b.tweens.Add(tween.Sequence(motion, tween.Call(func() error {
+		b.graph.Remove(node)
+		return nil
+	})))
+}
+
+// Translate : Move the text, in world pixels
+func (t *floatingText) Translate(x, y float64) {
+	t.pos.X += x
+	t.pos.Y += y
+}
+
+// Alpha : The text's opacity
+func (t *floatingText) Alpha() float64 {
+	return t.alpha
+}
+
+// SetAlpha : Set the text's opacity, from 0 to 1
+func (t *floatingText) SetAlpha(a float64) {
+	t.alpha = a
+}
+
+// Update : Engine Update hook. Tweens do the moving.
+func (t *floatingText) Update() error {
+	return nil
+}
+
+// Draw : Engine Draw hook
+func (t *floatingText) Draw(screen *ebiten.Image) {
+	p := t.camera.WorldToScreen(t.pos)
+	r, g, b, a := t.clr.RGBA()
+	faded := color.RGBA64{
+		uint16(float64(r) * t.alpha), uint16(float64(g) * t.alpha),
+		uint16(float64(b) * t.alpha), uint16(float64(a) * t.alpha),
+	}
+	text.Draw(screen, t.text, basicfont.Face7x13, int(p.X), int(p.Y), faded)
+}
+
+// showDamage : Float the damage taken up from over the pawn's head, fading as it goes
+func (p *pawn) showDamage(b *BattleScene, n int) {
+	if p.sprite == nil {
+		return
+	}
+	pos := p.sprite.GetPosition()
+	number := b.newFloatingText(strconv.Itoa(n), render.Point{X: pos.X + pawnWidth/2, Y: pos.Y}, damageColor)
+	b.float(number, tween.Parallel(
+		tween.Move(number, 0, -damageRise, damageTicks, tween.OutQuad),
+		tween.Sequence(tween.Delay(damageTicks/2), tween.FadeTo(number, 0, damageTicks/2, tween.InQuad)),
+	))
+}
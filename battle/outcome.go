@@ -0,0 +1,67 @@
+package battle
+
+import (
+	"image/color"
+
+	"github.com/jessdwitch/spiders/engine"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font/basicfont"
+)
+
+var (
+	wonColor  color.Color = color.RGBA{0xff, 0xe7, 0x62, 0xff}
+	lostColor color.Color = color.RGBA{0xff, 0x40, 0x40, 0xff}
+)
+
+// over : Has the battle been won or lost?
+func (b *BattleScene) over() bool {
+	return b.state == battleWon || b.state == battleLost
+}
+
+// checkOver : End the battle once a side is knocked out and the blow that did it has landed
+func (b *BattleScene) checkOver() error {
+	if b.over() || b.tweens.Busy() {
+		return nil
+	}
+	switch {
+	case b.aiPawns.knockedOut():
+		return b.endBattle(true)
+	case b.playerPawns.knockedOut():
+		return b.endBattle(false)
+	}
+	return nil
+}
+
+// endBattle : Stop taking turns and show the outcome
+func (b *BattleScene) endBattle(won bool) error {
+	b.state = battleLost
+	if won {
+		b.state = battleWon
+	}
+	return b.gameState.Publish(TopicBattleOver, won)
+}
+
+// outcomeInput : Confirm leaves the battle, if there's somewhere to go
+func (b *BattleScene) outcomeInput(state *engine.GameState) error {
+	if b.back == nil || !state.Input.IsJustPressed(engine.ButtonConfirm) {
+		return nil
+	}
+	if err := state.PlaySFX(engine.SFXConfirm); err != nil {
+		return err
+	}
+	state.SceneManager.GoTo(b.back)
+	return nil
+}
+
+// drawOutcome : Victory or defeat, across the middle of the screen
+func (b *BattleScene) drawOutcome(screen *ebiten.Image) {
+	face := basicfont.Face7x13
+	w, h := screen.Size()
+	msg, clr := "Defeat", lostColor
+	if b.state == battleWon {
+		msg, clr = "Victory", wonColor
+	}
+	text.Draw(screen, msg, face, w/2-len(msg)*face.Advance/2, h/2, clr)
+}
@@ -3,10 +3,28 @@ package battle
 import (
 	"github.com/jessdwitch/spiders/engine"
 	"github.com/jessdwitch/spiders/engine/render"
+	"github.com/jessdwitch/spiders/engine/tween"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
+const (
+	// pawnWidth : Room each pawn takes up along its side's line
+	pawnWidth = 128
+	// pawnScale : AI pawn sprites are drawn this many times their size, to fill pawnWidth
+	pawnScale = 4
+	// lungeReach : How much of the way to its target a lunging pawn goes
+	lungeReach = 0.3
+	// lungeTicks : Ticks to lunge out, and again to come back
+	lungeTicks = 12
+	// hitFlashTicks : How long a hit pawn's flash takes to fade
+	hitFlashTicks = 10
+	// hitEmitter : The particles a hit pawn bursts into
+	hitEmitter render.EmitterID = "hit"
+	// hitParticles : How many particles a hit bursts into
+	hitParticles = 12
+)
+
 type (
 	pawn struct {
 		name          string
@@ -14,7 +32,9 @@ type (
 		currentHealth int
 		statuses      []status
 		sprite        render.Sprite
-		action        queuedAction
+		// spriteID : what sprite was got from, to release it
+		spriteID render.SpriteID
+		action   queuedAction
 	}
 	pawns          []pawn
 	statusResolver interface {
@@ -73,6 +93,10 @@ func (p pawns) resolveStatuses(b *BattleScene) error {
 func (p pawns) update() error {
 	var err error
 	for _, pawn := range p {
+		// Party members don't have sprites yet
+		if pawn.sprite == nil {
+			continue
+		}
 		if err = pawn.sprite.Update(); err != nil {
 			return err
 		}
@@ -82,18 +106,114 @@ func (p pawns) update() error {
 
 // arrange : sets the locations for each of the pawns equidistant on a given line
 func (p pawns) arrange(start, end render.Point) {
-	// TODO: Rotate the sprite to match the angle of the line or compute the across of the sprite at that angle
-	totalPawnWidth := len(p) * 128
+	// Pawns stand along the line, so they tilt with it
+	angle := start.Angle(end)
+	totalPawnWidth := len(p) * pawnWidth
 	spacer := (start.Dist(end) - float64(totalPawnWidth)) / float64(len(p)+1)
 	for _, pawn := range p {
 		start = start.AddVec(spacer, end)
-		pawn.sprite.Translate(start.X, start.Y)
-		start = start.AddVec(128, end)
+		if pawn.sprite != nil {
+			pawn.sprite.SetPosition(start.X, start.Y)
+			pawn.sprite.GetTransform().SetRotation(angle)
+		}
+		start = start.AddVec(pawnWidth, end)
 	}
 }
 
-func (p pawns) draw(screen *ebiten.Image) {
+// addTo : Draw the pawns in graph. Pawns lower on screen are nearer, so they draw on top.
+func (p pawns) addTo(graph *render.SceneGraph) {
 	for _, pawn := range p {
-		pawn.sprite.Draw(screen)
+		if pawn.sprite != nil {
+			graph.Add(pawn.sprite, render.LayerPawns, int(pawn.sprite.GetPosition().Y))
+		}
+	}
+}
+
+// takeDamage : Lose health, down to zero
+func (p *pawn) takeDamage(n int) {
+	if p.currentHealth -= n; p.currentHealth < 0 {
+		p.currentHealth = 0
 	}
 }
+
+// knockedOut : Has the pawn run out of health?
+func (p *pawn) knockedOut() bool {
+	return p.currentHealth <= 0
+}
+
+// knockedOut : Is every pawn out of health? False if there are none.
+func (p pawns) knockedOut() bool {
+	for i := range p {
+		if !p[i].knockedOut() {
+			return false
+		}
+	}
+	return len(p) > 0
+}
+
+// lunge : Wind up and dash part way towards a point, call hit, then ease back
+func (p *pawn) lunge(toward render.Point, hit func() error) tween.Tween {
+	from := p.sprite.GetPosition()
+	dx, dy := (toward.X-from.X)*lungeReach, (toward.Y-from.Y)*lungeReach
+	return tween.Sequence(
+		tween.Move(p.sprite, dx, dy, lungeTicks, tween.InBack),
+		tween.Call(hit),
+		tween.Move(p.sprite, -dx, -dy, lungeTicks, tween.OutQuad),
+	)
+}
+
+// showHit : Flash the pawn's sprite white, or grey it out if it's been knocked out. Effects are
+// cosmetic, so this does nothing without shaders.
+func (p *pawn) showHit(b *BattleScene) error {
+	shaders := b.gameState.Shaders
+	if p.sprite == nil || shaders == nil {
+		return nil
+	}
+	if p.currentHealth <= 0 {
+		grey, err := shaders.NewEffect(render.ShaderGreyscale)
+		if err != nil {
+			return err
+		}
+		p.sprite.SetEffect(grey)
+		return nil
+	}
+	flash, err := shaders.NewEffect(render.ShaderFlash)
+	if err != nil {
+		return err
+	}
+	p.sprite.SetEffect(flash)
+	b.tweens.Add(tween.Sequence(
+		tween.To(1, 0, hitFlashTicks, tween.OutQuad, flash.SetAmount),
+		tween.Call(func() error {
+			// Unless something else has replaced it since
+			if p.sprite.GetEffect() == flash {
+				p.sprite.SetEffect(nil)
+			}
+			return nil
+		}),
+	))
+	return nil
+}
+
+// showSparks : Burst particles from the middle of the pawn, as when it's hit. Effects are
+// cosmetic, so this does nothing without particles.
+func (p *pawn) showSparks(b *BattleScene) error {
+	particles := b.gameState.Particles
+	if p.sprite == nil || particles == nil {
+		return nil
+	}
+	sparks, err := particles.GetEmitter(hitEmitter)
+	if err != nil {
+		return err
+	}
+	pos := p.sprite.GetPosition()
+	sparks.SetPosition(pos.X+pawnWidth/2, pos.Y+pawnWidth/2)
+	sparks.Burst(hitParticles)
+	node := b.graph.Add(sparks, render.LayerEffects, 0)
+	// The emitter is done once its particles have played out
+	b.tweens.Add(tween.Sequence(sparks, tween.Call(func() error {
+		b.graph.Remove(node)
+		return particles.Release(hitEmitter)
+	})))
+	return nil
+}
@@ -0,0 +1,202 @@
+// atlaspack : Pack sprite sheets and loose frame images into shared atlas pages, and write an
+// atlas manifest the game can load in place of sprite/sheets.csv.
+//
+//	go run ./cmd/atlaspack [-dir content] [-sheets sprite/sheets.csv] [frame dirs...]
+//
+// Every sheet in the sheet manifest is cut into its grid tiles. Each frame dir, relative to -dir,
+// becomes a sheet named after the dir, with one frame per PNG in name order. Frame indices match the sources, so
+// sprites.csv doesn't change.
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+func main() {
+	dir := flag.String("dir", "content", "content directory; other paths are relative to it")
+	sheets := flag.String("sheets", "sprite/sheets.csv", "grid sheet manifest to pack, or empty for none")
+	out := flag.String("out", "img/atlas/atlas", "page path prefix; pages are written as <out><n>.png")
+	manifest := flag.String("manifest", "sprite/atlas.csv", "atlas manifest to write")
+	size := flag.Int("size", 1024, "maximum page width and height")
+	pad := flag.Int("pad", 1, "transparent pixels between frames")
+	flag.Parse()
+
+	groups := []group{}
+	if *sheets != "" {
+		g, err := loadSheets(os.DirFS(*dir), *sheets)
+		if err != nil {
+			log.Fatal(err)
+		}
+		groups = append(groups, g...)
+	}
+	for _, d := range flag.Args() {
+		g, err := loadFrameDir(os.DirFS(*dir), filepath.ToSlash(d))
+		if err != nil {
+			log.Fatal(err)
+		}
+		groups = append(groups, g)
+	}
+	if len(groups) == 0 {
+		log.Fatal("nothing to pack")
+	}
+	placements, extents, err := pack(groups, *size, *pad)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := write(*dir, *out, *manifest, groups, placements, extents); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("packed %d sheets onto %d pages\n", len(groups), len(extents))
+}
+
+// loadSheets : Cut every sheet in a grid sheet manifest into its tiles
+func loadSheets(fsys fs.FS, manifest string) ([]group, error) {
+	b, err := fs.ReadFile(fsys, manifest)
+	if err != nil {
+		return nil, err
+	}
+	records, err := csv.NewReader(bytes.NewReader(b)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", manifest, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%s is empty", manifest)
+	}
+	result := []group{}
+	// strip header
+	for _, record := range records[1:] {
+		// record: name, path, tileX, tileY, sheetX, sheetY
+		if len(record) != 6 {
+			return nil, fmt.Errorf("%s: record %v has %d columns, want 6", manifest, record, len(record))
+		}
+		tileX, errX := strconv.Atoi(record[2])
+		tileY, errY := strconv.Atoi(record[3])
+		if errX != nil || errY != nil || tileX <= 0 || tileY <= 0 {
+			return nil, fmt.Errorf("%s: sheet %s has a bad tile size", manifest, record[0])
+		}
+		img, err := decode(fsys, record[1])
+		if err != nil {
+			return nil, err
+		}
+		g := group{sheet: record[0]}
+		b := img.Bounds()
+		// Row-major, matching the tile indices in sprites.csv
+		for y := b.Min.Y; y+tileY <= b.Max.Y; y += tileY {
+			for x := b.Min.X; x+tileX <= b.Max.X; x += tileX {
+				g.frames = append(g.frames, subImage(img, image.Rect(x, y, x+tileX, y+tileY)))
+			}
+		}
+		result = append(result, g)
+	}
+	return result, nil
+}
+
+// loadFrameDir : Make a sheet of every PNG in a directory of fsys
+func loadFrameDir(fsys fs.FS, dir string) (group, error) {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.png"))
+	if err != nil {
+		return group{}, err
+	}
+	if len(files) == 0 {
+		return group{}, fmt.Errorf("%s has no PNG frames", dir)
+	}
+	sort.Strings(files)
+	g := group{sheet: path.Base(path.Clean(dir))}
+	for _, f := range files {
+		img, err := decode(fsys, f)
+		if err != nil {
+			return group{}, err
+		}
+		g.frames = append(g.frames, img)
+	}
+	return g, nil
+}
+
+func decode(fsys fs.FS, p string) (image.Image, error) {
+	f, err := fsys.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", p, err)
+	}
+	return img, nil
+}
+
+func subImage(img image.Image, r image.Rectangle) image.Image {
+	result := image.NewNRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(result, result.Bounds(), img, r.Min, draw.Src)
+	return result
+}
+
+// write : Draw the pages and write them with their manifest
+func write(dir, out, manifest string, groups []group, placements []placement, extents []image.Point) error {
+	frames := map[string][]image.Image{}
+	for _, g := range groups {
+		frames[g.sheet] = g.frames
+	}
+	pages := make([]*image.NRGBA, len(extents))
+	pagePaths := make([]string, len(extents))
+	for i, e := range extents {
+		pages[i] = image.NewNRGBA(image.Rectangle{Max: e})
+		pagePaths[i] = fmt.Sprintf("%s%d.png", out, i)
+	}
+	// Sheet order, then frame order, so the manifest diffs cleanly between runs
+	sort.SliceStable(placements, func(i, j int) bool {
+		if placements[i].sheet != placements[j].sheet {
+			return placements[i].sheet < placements[j].sheet
+		}
+		return placements[i].frame < placements[j].frame
+	})
+	rows := [][]string{{"sheet", "page", "frame", "x", "y", "w", "h"}}
+	for _, p := range placements {
+		src := frames[p.sheet][p.frame]
+		draw.Draw(pages[p.page], p.rect, src, src.Bounds().Min, draw.Src)
+		rows = append(rows, []string{
+			p.sheet, pagePaths[p.page], strconv.Itoa(p.frame),
+			strconv.Itoa(p.rect.Min.X), strconv.Itoa(p.rect.Min.Y),
+			strconv.Itoa(p.rect.Dx()), strconv.Itoa(p.rect.Dy()),
+		})
+	}
+	for i, pg := range pages {
+		if err := writePNG(filepath.Join(dir, filepath.FromSlash(pagePaths[i])), pg); err != nil {
+			return err
+		}
+	}
+	buf := &bytes.Buffer{}
+	w := csv.NewWriter(buf)
+	if err := w.WriteAll(rows); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, filepath.FromSlash(manifest)), buf.Bytes(), 0o644)
+}
+
+func writePNG(p string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(p)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
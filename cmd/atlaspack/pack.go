@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"sort"
+)
+
+type (
+	// group : Frames that must land on the same page, because they make up one sheet
+	group struct {
+		sheet  string
+		frames []image.Image
+	}
+	// placement : Where a frame landed
+	placement struct {
+		sheet string
+		frame int
+		page  int
+		rect  image.Rectangle
+	}
+	// packer : Shelf packs groups of frames onto pages of at most size x size pixels
+	packer struct {
+		size  int
+		pad   int
+		pages []*page
+	}
+	page struct {
+		shelves []shelf
+		// top : The y where the next shelf would start
+		top int
+		// extent : The bottom-right corner of everything placed so far
+		extent image.Point
+	}
+	// shelf : A row of frames no taller than h
+	shelf struct {
+		y, h, x int
+	}
+)
+
+// pack : Place every frame of every group. A group goes on the first page it fits on whole, or a new
+// page if none have room.
+func pack(groups []group, size, pad int) ([]placement, []image.Point, error) {
+	p := &packer{size: size, pad: pad}
+	result := []placement{}
+	for _, g := range groups {
+		placed, err := p.packGroup(g)
+		if err != nil {
+			return nil, nil, err
+		}
+		result = append(result, placed...)
+	}
+	extents := make([]image.Point, len(p.pages))
+	for i, pg := range p.pages {
+		extents[i] = pg.extent
+	}
+	return result, extents, nil
+}
+
+func (p *packer) packGroup(g group) ([]placement, error) {
+	// Tallest first keeps shelves full
+	order := make([]int, len(g.frames))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return g.frames[order[a]].Bounds().Dy() > g.frames[order[b]].Bounds().Dy()
+	})
+	for i := 0; i <= len(p.pages); i++ {
+		if i == len(p.pages) {
+			p.pages = append(p.pages, &page{})
+		}
+		trial := p.pages[i].clone()
+		result := make([]placement, 0, len(order))
+		fits := true
+		for _, f := range order {
+			size := g.frames[f].Bounds().Size()
+			r, ok := trial.insert(size, p.size, p.pad)
+			if !ok {
+				fits = false
+				break
+			}
+			result = append(result, placement{sheet: g.sheet, frame: f, page: i, rect: r})
+		}
+		if fits {
+			p.pages[i] = trial
+			return result, nil
+		}
+		if p.pages[i].empty() {
+			// Didn't fit on a blank page, so it never will
+			p.pages = p.pages[:i]
+			return nil, fmt.Errorf("sheet %s doesn't fit on a %dx%d page", g.sheet, p.size, p.size)
+		}
+	}
+	// Unreachable: the loop either fits the group or fails on a blank page
+	return nil, fmt.Errorf("sheet %s could not be placed", g.sheet)
+}
+
+func (pg *page) clone() *page {
+	c := *pg
+	c.shelves = append([]shelf(nil), pg.shelves...)
+	return &c
+}
+
+func (pg *page) empty() bool {
+	return len(pg.shelves) == 0
+}
+
+// insert : Find room for a frame of the given size, padded on the right and bottom
+func (pg *page) insert(size image.Point, limit, pad int) (image.Rectangle, bool) {
+	w, h := size.X+pad, size.Y+pad
+	for i := range pg.shelves {
+		s := &pg.shelves[i]
+		if h <= s.h && s.x+w <= limit {
+			return pg.place(s, size, w), true
+		}
+	}
+	if pg.top+h > limit || w > limit {
+		return image.Rectangle{}, false
+	}
+	pg.shelves = append(pg.shelves, shelf{y: pg.top, h: h})
+	pg.top += h
+	return pg.place(&pg.shelves[len(pg.shelves)-1], size, w), true
+}
+
+func (pg *page) place(s *shelf, size image.Point, w int) image.Rectangle {
+	r := image.Rectangle{Min: image.Pt(s.x, s.y), Max: image.Pt(s.x+size.X, s.y+size.Y)}
+	s.x += w
+	if r.Max.X > pg.extent.X {
+		pg.extent.X = r.Max.X
+	}
+	if r.Max.Y > pg.extent.Y {
+		pg.extent.Y = r.Max.Y
+	}
+	return r
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func frames(n, w, h int) []image.Image {
+	result := make([]image.Image, n)
+	for i := range result {
+		result[i] = image.NewNRGBA(image.Rect(0, 0, w, h))
+	}
+	return result
+}
+
+func TestPack(t *testing.T) {
+	groups := []group{
+		{sheet: "small", frames: frames(4, 10, 10)},
+		{sheet: "tall", frames: append(frames(1, 10, 20), frames(1, 10, 5)...)},
+	}
+	placements, extents, err := pack(groups, 64, 1)
+	assert.NoError(t, err)
+	assert.Len(t, placements, 6)
+	assert.Equal(t, []image.Point{{54, 31}}, extents)
+	for i, a := range placements {
+		w, h := a.rect.Dx(), a.rect.Dy()
+		src := groups[0].frames
+		if a.sheet == "tall" {
+			src = groups[1].frames
+		}
+		assert.Equal(t, src[a.frame].Bounds().Size(), image.Pt(w, h))
+		for _, b := range placements[i+1:] {
+			assert.False(t, a.rect.Overlaps(b.rect), "%v overlaps %v", a, b)
+		}
+	}
+}
+
+func TestPackNewPage(t *testing.T) {
+	groups := []group{
+		{sheet: "a", frames: frames(3, 30, 30)},
+		{sheet: "b", frames: frames(3, 30, 30)},
+	}
+	placements, extents, err := pack(groups, 64, 0)
+	assert.NoError(t, err)
+	assert.Len(t, extents, 2)
+	for _, p := range placements {
+		// A sheet never spans pages
+		if p.sheet == "a" {
+			assert.Equal(t, 0, p.page)
+		} else {
+			assert.Equal(t, 1, p.page)
+		}
+	}
+}
+
+func TestPackTooBig(t *testing.T) {
+	_, _, err := pack([]group{{sheet: "huge", frames: frames(1, 100, 10)}}, 64, 0)
+	assert.Error(t, err)
+}
+
+func TestLoadFrameDir(t *testing.T) {
+	encode := func(w, h int) *fstest.MapFile {
+		buf := bytes.Buffer{}
+		assert.NoError(t, png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, w, h))))
+		return &fstest.MapFile{Data: buf.Bytes()}
+	}
+	fsys := fstest.MapFS{
+		"img/hop/1.png": encode(8, 8),
+		"img/hop/0.png": encode(4, 4),
+		"img/empty/x":   {},
+	}
+	g, err := loadFrameDir(fsys, "img/hop/")
+	assert.NoError(t, err)
+	assert.Equal(t, "hop", g.sheet)
+	assert.Len(t, g.frames, 2)
+	assert.Equal(t, image.Pt(4, 4), g.frames[0].Bounds().Size(), "frames in name order")
+	_, err = loadFrameDir(fsys, "img/empty")
+	assert.Error(t, err)
+}
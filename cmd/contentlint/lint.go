@@ -0,0 +1,580 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"image"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jessdwitch/spiders/deck"
+	"github.com/jessdwitch/spiders/engine/render/spritedata"
+)
+
+// Manifest locations, relative to the content root
+const (
+	sheetManifest  = "sprite/sheets.csv"
+	atlasManifest  = "sprite/atlas.csv"
+	spriteManifest = "sprite/sprites.csv"
+	cardManifest   = "cards/data.csv"
+	pawnManifest   = "battle/pawns.csv"
+	battleManifest = "battle/battles.csv"
+	audioManifest  = "audio/audio.csv"
+	textGlob       = "text/*.txt"
+	// asepriteGlob : Sprites exported straight from Aseprite, with their sheet images alongside
+	asepriteGlob = "img/aseprite/*.json"
+	// baseLanguage : Other languages are checked for the same text IDs as this one
+	baseLanguage = "text/eng.txt"
+)
+
+type (
+	// diagnostic : A problem at a place in a manifest
+	diagnostic struct {
+		file string
+		line int
+		msg  string
+	}
+	// linter : Checks every manifest, remembering IDs so later manifests can check references
+	linter struct {
+		fsys    fs.FS
+		diags   []diagnostic
+		sheets  map[string]sheetInfo
+		sprites map[string]bool
+		pawns   map[string]bool
+	}
+	sheetInfo struct {
+		nTiles int
+	}
+	// atlasSheet : A sheet as read from the atlas manifest so far
+	atlasSheet struct {
+		line   int
+		page   string
+		frames map[int]bool
+	}
+	// row : A manifest record and the line it came from
+	row struct {
+		line   int
+		fields []string
+	}
+)
+
+func (d diagnostic) String() string {
+	return fmt.Sprintf("%s:%d: %s", d.file, d.line, d.msg)
+}
+
+func newLinter(fsys fs.FS) *linter {
+	return &linter{
+		fsys:    fsys,
+		sheets:  map[string]sheetInfo{},
+		sprites: map[string]bool{},
+		pawns:   map[string]bool{},
+	}
+}
+
+// lint : Check every manifest. Order matters: references are checked against manifests already
+// read.
+func (l *linter) lint() []diagnostic {
+	l.lintSheets()
+	l.lintAtlas()
+	l.lintAseprite()
+	l.lintSprites()
+	l.lintCards()
+	l.lintPawns()
+	l.lintBattles()
+	l.lintAudio()
+	l.lintText()
+	sort.SliceStable(l.diags, func(i, j int) bool {
+		if l.diags[i].file != l.diags[j].file {
+			return l.diags[i].file < l.diags[j].file
+		}
+		return l.diags[i].line < l.diags[j].line
+	})
+	return l.diags
+}
+
+func (l *linter) errorf(file string, line int, format string, args ...interface{}) {
+	l.diags = append(l.diags, diagnostic{file, line, fmt.Sprintf(format, args...)})
+}
+
+// readManifest : Read every record after the header, checking column counts. Rows with the wrong
+// count are reported and skipped.
+func (l *linter) readManifest(file string, columns int) []row {
+	return l.readManifestRange(file, columns, columns)
+}
+
+// readManifestRange : As readManifest, for manifests with optional trailing columns. Every row
+// must have as many columns as the header.
+func (l *linter) readManifestRange(file string, min, max int) []row {
+	f, err := l.fsys.Open(file)
+	if err != nil {
+		l.errorf(file, 0, "%v", err)
+		return nil
+	}
+	defer f.Close()
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	header, err := r.Read()
+	if err == io.EOF {
+		l.errorf(file, 1, "manifest is empty; it needs at least a header")
+		return nil
+	}
+	if err != nil {
+		l.errorf(file, 1, "%v", err)
+		return nil
+	}
+	columns := len(header)
+	if columns < min || columns > max {
+		l.errorf(file, 1, "header has %d columns, want %s", columns, columnRange(min, max))
+		return nil
+	}
+	result := []row{}
+	// Manifests don't use multi-line fields, so each record is one line
+	for line := 2; ; line++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			l.errorf(file, line, "%v", err)
+			return result
+		}
+		if len(record) != columns {
+			l.errorf(file, line, "has %d columns, want %d", len(record), columns)
+			continue
+		}
+		result = append(result, row{line, record})
+	}
+	return result
+}
+
+func columnRange(min, max int) string {
+	if min == max {
+		return strconv.Itoa(min)
+	}
+	return fmt.Sprintf("%d to %d", min, max)
+}
+
+// atoi : Parse an integer field, reporting it if it isn't one or is below min
+func (l *linter) atoi(file string, r row, col int, name string, min int) (int, bool) {
+	v, err := strconv.Atoi(r.fields[col])
+	if err != nil {
+		l.errorf(file, r.line, "%s %q is not an integer", name, r.fields[col])
+		return 0, false
+	}
+	if v < min {
+		l.errorf(file, r.line, "%s %d must be at least %d", name, v, min)
+		return v, false
+	}
+	return v, true
+}
+
+// checkFile : Report a referenced file that doesn't exist
+func (l *linter) checkFile(file string, r row, name, p string) bool {
+	if _, err := fs.Stat(l.fsys, p); err != nil {
+		l.errorf(file, r.line, "%s %s not found", name, p)
+		return false
+	}
+	return true
+}
+
+// checkUnique : Report an ID seen before
+func (l *linter) checkUnique(file string, r row, seen map[string]int, id string) {
+	if prev, ok := seen[id]; ok {
+		l.errorf(file, r.line, "duplicate ID %s, first defined on line %d", id, prev)
+		return
+	}
+	seen[id] = r.line
+}
+
+func (l *linter) lintSheets() {
+	// record: name, path, tileX, tileY, sheetX, sheetY
+	seen := map[string]int{}
+	for _, r := range l.readManifest(sheetManifest, 6) {
+		id := r.fields[0]
+		l.checkUnique(sheetManifest, r, seen, id)
+		tileX, okX := l.atoi(sheetManifest, r, 2, "tileX", 1)
+		tileY, okY := l.atoi(sheetManifest, r, 3, "tileY", 1)
+		sheetX, okSX := l.atoi(sheetManifest, r, 4, "sheetX", 0)
+		sheetY, okSY := l.atoi(sheetManifest, r, 5, "sheetY", 0)
+		if !l.checkFile(sheetManifest, r, "image", r.fields[1]) {
+			continue
+		}
+		cfg, err := l.decodeConfig(r.fields[1])
+		if err != nil {
+			l.errorf(sheetManifest, r.line, "image %s: %v", r.fields[1], err)
+			continue
+		}
+		if okSX && okSY && sheetX != 0 && (sheetX != cfg.Width || sheetY != cfg.Height) {
+			l.errorf(sheetManifest, r.line, "sheet size %dx%d doesn't match image size %dx%d",
+				sheetX, sheetY, cfg.Width, cfg.Height)
+		}
+		if sheetX == 0 {
+			sheetX, sheetY = cfg.Width, cfg.Height
+		}
+		if !okX || !okY {
+			continue
+		}
+		if sheetX%tileX != 0 || sheetY%tileY != 0 {
+			l.errorf(sheetManifest, r.line, "sheet size %dx%d isn't a whole number of %dx%d tiles",
+				sheetX, sheetY, tileX, tileY)
+		}
+		l.sheets[id] = sheetInfo{nTiles: (sheetX / tileX) * (sheetY / tileY)}
+	}
+}
+
+// lintAtlas : Check the packed atlas, if there is one. The game loads it in place of the loose
+// sheets, so sprites are checked against its sheets instead.
+func (l *linter) lintAtlas() {
+	if _, err := fs.Stat(l.fsys, atlasManifest); err != nil {
+		return
+	}
+	// record: sheet, page, frame, x, y, w, h[, originX, originY, duration]
+	sheets := map[string]*atlasSheet{}
+	pages := map[string]image.Rectangle{}
+	for _, r := range l.readManifestRange(atlasManifest,
+		spritedata.AtlasManifestColumns, spritedata.AtlasManifestFullColumns) {
+		rec, err := spritedata.ParseAtlasRecord(r.fields)
+		if err != nil {
+			l.errorf(atlasManifest, r.line, "%v", err)
+			continue
+		}
+		sheet, ok := sheets[rec.Sheet]
+		if !ok {
+			sheet = &atlasSheet{line: r.line, page: rec.Page, frames: map[int]bool{}}
+			sheets[rec.Sheet] = sheet
+		} else if sheet.page != rec.Page {
+			l.errorf(atlasManifest, r.line, "sheet %s spans pages %s and %s",
+				rec.Sheet, sheet.page, rec.Page)
+		}
+		if sheet.frames[rec.Frame] {
+			l.errorf(atlasManifest, r.line, "sheet %s has frame %d twice", rec.Sheet, rec.Frame)
+		}
+		sheet.frames[rec.Frame] = true
+		bounds, ok := pages[rec.Page]
+		if !ok {
+			if !l.checkFile(atlasManifest, r, "page", rec.Page) {
+				continue
+			}
+			cfg, err := l.decodeConfig(rec.Page)
+			if err != nil {
+				l.errorf(atlasManifest, r.line, "page %s: %v", rec.Page, err)
+				continue
+			}
+			bounds = image.Rect(0, 0, cfg.Width, cfg.Height)
+			pages[rec.Page] = bounds
+		}
+		if !rec.Rect.In(bounds) {
+			l.errorf(atlasManifest, r.line, "frame %v is outside page %s", rec.Rect, rec.Page)
+		}
+	}
+	l.sheets = map[string]sheetInfo{}
+	for id, sheet := range sheets {
+		nTiles := 0
+		for f := range sheet.frames {
+			if f >= nTiles {
+				nTiles = f + 1
+			}
+		}
+		for f := 0; f < nTiles; f++ {
+			if !sheet.frames[f] {
+				l.errorf(atlasManifest, sheet.line, "sheet %s is missing frame %d", id, f)
+			}
+		}
+		l.sheets[id] = sheetInfo{nTiles: nTiles}
+	}
+}
+
+// lintAseprite : Check each Aseprite export. Each is a sheet and a sprite, named after the file.
+// JSON has no useful line numbers, so problems are reported against the whole file.
+func (l *linter) lintAseprite() {
+	files, err := fs.Glob(l.fsys, asepriteGlob)
+	if err != nil {
+		l.errorf(asepriteGlob, 0, "%v", err)
+		return
+	}
+	for _, file := range files {
+		b, err := fs.ReadFile(l.fsys, file)
+		if err != nil {
+			l.errorf(file, 0, "%v", err)
+			continue
+		}
+		ase, err := spritedata.ParseAseprite(b)
+		if err != nil {
+			l.errorf(file, 0, "%v", err)
+			continue
+		}
+		name := strings.TrimSuffix(path.Base(file), path.Ext(file))
+		l.sheets[name] = sheetInfo{nTiles: len(ase.Frames)}
+		l.sprites[name] = true
+		page := path.Join(path.Dir(file), ase.Image)
+		if _, err := fs.Stat(l.fsys, page); err != nil {
+			l.errorf(file, 0, "image %s not found", page)
+			continue
+		}
+		cfg, err := l.decodeConfig(page)
+		if err != nil {
+			l.errorf(file, 0, "image %s: %v", page, err)
+			continue
+		}
+		bounds := image.Rect(0, 0, cfg.Width, cfg.Height)
+		for i, f := range ase.Frames {
+			if !f.Frame.Rectangle().In(bounds) {
+				l.errorf(file, 0, "frame %d %v is outside image %s", i, f.Frame.Rectangle(), page)
+			}
+		}
+	}
+}
+
+func (l *linter) decodeConfig(p string) (image.Config, error) {
+	f, err := l.fsys.Open(p)
+	if err != nil {
+		return image.Config{}, err
+	}
+	defer f.Close()
+	cfg, _, err := image.DecodeConfig(f)
+	return cfg, err
+}
+
+func (l *linter) lintSprites() {
+	// record: name, sheet, mode, start, nFrames, dimX, dimY, delay[, playback[, markers[, palette]]]
+	seen := map[string]int{}
+	palettes := map[string]string{}
+	for _, r := range l.readManifestRange(spriteManifest, 8, 11) {
+		id, sheetID := r.fields[0], r.fields[1]
+		l.sprites[id] = true
+		l.checkUnique(spriteManifest, r, seen, id+"/"+r.fields[2])
+		start, okStart := l.atoi(spriteManifest, r, 3, "start", 0)
+		nFrames, okN := l.atoi(spriteManifest, r, 4, "nFrames", 1)
+		if okN {
+			l.checkDelays(r, nFrames)
+		}
+		if len(r.fields) > 8 {
+			if _, err := spritedata.ParsePlaybackMode(r.fields[8]); err != nil {
+				l.errorf(spriteManifest, r.line, "playback %q must be loop, once, pingpong, or hold", r.fields[8])
+			}
+		}
+		if len(r.fields) > 9 && okN {
+			l.checkMarkers(r, nFrames)
+		}
+		if len(r.fields) > 10 && r.fields[10] != "" {
+			l.checkPalette(r)
+			if p, ok := palettes[id]; ok && p != r.fields[10] {
+				l.errorf(spriteManifest, r.line, "sprite %s has two palettes", id)
+			}
+			palettes[id] = r.fields[10]
+		}
+		for col, name := range map[int]string{5: "dimX", 6: "dimY"} {
+			if _, err := strconv.ParseFloat(r.fields[col], 64); err != nil {
+				l.errorf(spriteManifest, r.line, "%s %q is not a number", name, r.fields[col])
+			}
+		}
+		sheet, ok := l.sheets[sheetID]
+		if !ok {
+			l.errorf(spriteManifest, r.line, "sprite %s uses unknown sheet %s", id, sheetID)
+			continue
+		}
+		if okStart && okN && start+nFrames > sheet.nTiles {
+			l.errorf(spriteManifest, r.line, "frames %d-%d are outside sheet %s, which has %d tiles",
+				start, start+nFrames-1, sheetID, sheet.nTiles)
+		}
+	}
+}
+
+// checkDelays : The delay is one number for every frame, or a semicolon-separated one per frame
+func (l *linter) checkDelays(r row, nFrames int) {
+	delays := strings.Split(r.fields[7], ";")
+	if len(delays) > 1 && len(delays) != nFrames {
+		l.errorf(spriteManifest, r.line, "%d delays given for %d frames", len(delays), nFrames)
+	}
+	for _, d := range delays {
+		if v, err := strconv.Atoi(d); err != nil || v < 0 {
+			l.errorf(spriteManifest, r.line, "delay %q is not a non-negative integer", d)
+		}
+	}
+}
+
+// checkMarkers : Markers are semicolon-separated name@frame pairs, within the animation
+func (l *linter) checkMarkers(r row, nFrames int) {
+	if r.fields[9] == "" {
+		return
+	}
+	seen := map[int]bool{}
+	for _, m := range strings.Split(r.fields[9], ";") {
+		i := strings.LastIndex(m, "@")
+		frame, err := strconv.Atoi(m[i+1:])
+		if i <= 0 || err != nil {
+			l.errorf(spriteManifest, r.line, "marker %q should be name@frame", m)
+			continue
+		}
+		if frame < 0 || frame >= nFrames {
+			l.errorf(spriteManifest, r.line, "marker %q is outside the %d frames", m, nFrames)
+		}
+		if seen[frame] {
+			l.errorf(spriteManifest, r.line, "frame %d is marked twice", frame)
+		}
+		seen[frame] = true
+	}
+}
+
+// checkPalette : Palettes are semicolon-separated #from>#to pairs of opaque hex colors
+func (l *linter) checkPalette(r row) {
+	if _, err := spritedata.ParsePalette(r.fields[10]); err != nil {
+		l.errorf(spriteManifest, r.line, "%v", err)
+	}
+}
+
+func (l *linter) lintCards() {
+	// record: id, name, img, desc, effect[, upgrade[, flags[, cost[, damage]]]]
+	seen := map[string]int{}
+	rows := l.readManifestRange(cardManifest, 5, 9)
+	for _, r := range rows {
+		l.checkUnique(cardManifest, r, seen, r.fields[0])
+		if r.fields[1] == "" {
+			l.errorf(cardManifest, r.line, "card %s has no name", r.fields[0])
+		}
+		l.checkFile(cardManifest, r, "image", r.fields[2])
+		if len(r.fields) > 6 {
+			if _, err := deck.ParseCardFlags(r.fields[6]); err != nil {
+				l.errorf(cardManifest, r.line, "%v", err)
+			}
+		}
+		for i, stat := range []string{"cost", "damage"} {
+			if len(r.fields) <= 7+i {
+				break
+			}
+			if _, err := deck.ParseCardStat(r.fields[7+i]); err != nil {
+				l.errorf(cardManifest, r.line, "card %s %s %v", r.fields[0], stat, err)
+			}
+		}
+	}
+	// Upgrades can point forward, so check them once every card is known
+	upgradeOf := map[string]string{}
+	for _, r := range rows {
+		if len(r.fields) < 6 || r.fields[5] == "" {
+			continue
+		}
+		id, to := r.fields[0], r.fields[5]
+		if _, ok := seen[to]; !ok {
+			l.errorf(cardManifest, r.line, "card %s upgrades to unknown card %s", id, to)
+		} else if other, ok := upgradeOf[to]; ok {
+			l.errorf(cardManifest, r.line, "card %s is the upgrade of both %s and %s", to, other, id)
+		}
+		upgradeOf[to] = id
+	}
+}
+
+func (l *linter) lintPawns() {
+	// record: id, name, sprite, maxHealth
+	seen := map[string]int{}
+	for _, r := range l.readManifest(pawnManifest, 4) {
+		id := r.fields[0]
+		l.atoi(pawnManifest, r, 0, "id", 0)
+		l.checkUnique(pawnManifest, r, seen, id)
+		l.pawns[id] = true
+		if !l.sprites[r.fields[2]] {
+			l.errorf(pawnManifest, r.line, "pawn %s uses unknown sprite %s", id, r.fields[2])
+		}
+		l.atoi(pawnManifest, r, 3, "maxHealth", 1)
+	}
+}
+
+func (l *linter) lintBattles() {
+	// record: id, pawns (semicolon separated pawn IDs), background
+	seen := map[string]int{}
+	for _, r := range l.readManifest(battleManifest, 3) {
+		l.checkUnique(battleManifest, r, seen, r.fields[0])
+		if r.fields[1] == "" {
+			l.errorf(battleManifest, r.line, "battle %s has no pawns", r.fields[0])
+		}
+		for _, p := range strings.Split(r.fields[1], ";") {
+			if p != "" && !l.pawns[p] {
+				l.errorf(battleManifest, r.line, "battle %s uses unknown pawn %s", r.fields[0], p)
+			}
+		}
+		if r.fields[2] != "" {
+			l.checkFile(battleManifest, r, "background", r.fields[2])
+		}
+	}
+}
+
+func (l *linter) lintAudio() {
+	// record: id, bus, path, volume
+	seen := map[string]int{}
+	for _, r := range l.readManifest(audioManifest, 4) {
+		l.checkUnique(audioManifest, r, seen, r.fields[0])
+		if r.fields[1] != "music" && r.fields[1] != "sfx" {
+			l.errorf(audioManifest, r.line, "bus %q must be music or sfx", r.fields[1])
+		}
+		l.checkFile(audioManifest, r, "clip", r.fields[2])
+		v, err := strconv.ParseFloat(r.fields[3], 64)
+		if err != nil || v < 0 || v > 1 {
+			l.errorf(audioManifest, r.line, "volume %q must be a number from 0 to 1", r.fields[3])
+		}
+	}
+}
+
+// lintText : Text files hold one id=text pair per line. Blank lines and lines starting with #
+// are ignored.
+func (l *linter) lintText() {
+	files, err := fs.Glob(l.fsys, textGlob)
+	if err != nil {
+		l.errorf(path.Dir(textGlob), 0, "%v", err)
+		return
+	}
+	ids := map[string]map[string]int{}
+	for _, file := range files {
+		ids[file] = l.lintTextFile(file)
+	}
+	base, ok := ids[baseLanguage]
+	if !ok {
+		l.errorf(baseLanguage, 0, "base language file is missing")
+		return
+	}
+	for _, file := range files {
+		if file == baseLanguage {
+			continue
+		}
+		for id := range base {
+			if _, ok := ids[file][id]; !ok {
+				l.errorf(file, 0, "missing text %s from %s", id, baseLanguage)
+			}
+		}
+		for id, line := range ids[file] {
+			if _, ok := base[id]; !ok {
+				l.errorf(file, line, "text %s isn't in %s", id, baseLanguage)
+			}
+		}
+	}
+}
+
+func (l *linter) lintTextFile(file string) map[string]int {
+	seen := map[string]int{}
+	f, err := l.fsys.Open(file)
+	if err != nil {
+		l.errorf(file, 0, "%v", err)
+		return seen
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	for line := 1; s.Scan(); line++ {
+		t := strings.TrimSpace(s.Text())
+		if t == "" || strings.HasPrefix(t, "#") {
+			continue
+		}
+		i := strings.Index(t, "=")
+		if i <= 0 {
+			l.errorf(file, line, "expected id=text")
+			continue
+		}
+		l.checkUnique(file, row{line: line}, seen, strings.TrimSpace(t[:i]))
+	}
+	if err := s.Err(); err != nil {
+		l.errorf(file, 0, "%v", err)
+	}
+	return seen
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func pngData(t *testing.T, w, h int) []byte {
+	buf := &bytes.Buffer{}
+	assert.NoError(t, png.Encode(buf, image.NewRGBA(image.Rect(0, 0, w, h))))
+	return buf.Bytes()
+}
+
+func sampleContent(t *testing.T) fstest.MapFS {
+	return fstest.MapFS{
+		"img/sheet.png":      {Data: pngData(t, 64, 32)},
+		"img/card.png":       {Data: pngData(t, 8, 8)},
+		"sprite/sheets.csv":  {Data: []byte("name,path,tileX,tileY,sheetX,sheetY\nslimes,img/sheet.png,32,32,64,32\n")},
+		"sprite/sprites.csv": {Data: []byte("name,sheet,mode,start,nFrames,dimX,dimY,delay\nslime,slimes,idle,0,2,32,32,8\n")},
+		"cards/data.csv":     {Data: []byte("id,name,img,desc,effect\nduck,Duck,img/card.png,Quack,\n")},
+		"battle/pawns.csv":   {Data: []byte("id,name,sprite,maxHealth\n0,Slime,slime,12\n")},
+		"battle/battles.csv": {Data: []byte("id,pawns,background\n0,0;0,\n")},
+		"audio/audio.csv":    {Data: []byte("id,bus,path,volume\n")},
+		"text/eng.txt":       {Data: []byte("# comment\nhello=Hello\n")},
+	}
+}
+
+func messages(diags []diagnostic) []string {
+	result := []string{}
+	for _, d := range diags {
+		result = append(result, d.String())
+	}
+	return result
+}
+
+func TestLintClean(t *testing.T) {
+	assert.Empty(t, messages(newLinter(sampleContent(t)).lint()))
+}
+
+func TestLintPlayback(t *testing.T) {
+	fsys := sampleContent(t)
+	fsys["sprite/sprites.csv"] = &fstest.MapFile{Data: []byte(
+		"name,sheet,mode,start,nFrames,dimX,dimY,delay,playback,markers\n" +
+			"slime,slimes,idle,0,2,32,32,8,,\n" +
+			"slime,slimes,attack,0,2,32,32,8,once,hit@1\n" +
+			"slime,slimes,die,0,2,32,32,8,forever,hit@2;@0\n")}
+	assert.Equal(t, []string{
+		"sprite/sprites.csv:4: playback \"forever\" must be loop, once, pingpong, or hold",
+		"sprite/sprites.csv:4: marker \"hit@2\" is outside the 2 frames",
+		"sprite/sprites.csv:4: marker \"@0\" should be name@frame",
+	}, messages(newLinter(fsys).lint()))
+}
+
+func TestLintPalette(t *testing.T) {
+	fsys := sampleContent(t)
+	fsys["sprite/sprites.csv"] = &fstest.MapFile{Data: []byte(
+		"name,sheet,mode,start,nFrames,dimX,dimY,delay,playback,markers,palette\n" +
+			"slime,slimes,idle,0,2,32,32,8,,,\n" +
+			"red,slimes,idle,0,2,32,32,8,,,#3e8948>#e43b44;#63c74d>#f6757a\n" +
+			"red,slimes,attack,0,2,32,32,8,once,,\n" +
+			"red,slimes,die,0,2,32,32,8,hold,,#3e8948>#000000\n" +
+			"odd,slimes,idle,0,2,32,32,8,,,#3e8948\n" +
+			"short,slimes,idle,0,2,32,32,8,,,#3e8948>#000000;#fff>#000000\n" +
+			"clear,slimes,idle,0,2,32,32,8,,,#3e894800>#000000\n")}
+	assert.Equal(t, []string{
+		"sprite/sprites.csv:5: sprite red has two palettes",
+		"sprite/sprites.csv:6: palette entry \"#3e8948\" should be #from>#to",
+		"sprite/sprites.csv:7: color \"#fff\" should be #rrggbb or #rrggbbaa",
+		"sprite/sprites.csv:8: palette color \"#3e894800\" can't be transparent",
+	}, messages(newLinter(fsys).lint()))
+}
+
+func TestLintAtlas(t *testing.T) {
+	fsys := sampleContent(t)
+	fsys["img/atlas.png"] = &fstest.MapFile{Data: pngData(t, 64, 32)}
+	fsys["sprite/atlas.csv"] = &fstest.MapFile{Data: []byte("sheet,page,frame,x,y,w,h\n" +
+		"slimes,img/atlas.png,0,0,0,32,32\n" +
+		"slimes,img/atlas.png,2,32,0,32,32\n" +
+		"slimes,img/atlas.png,2,32,0,32,32\n" +
+		"bats,img/atlas.png,0,48,0,32,32\n" +
+		"bats,img/gone.png,1,0,0,8,8\n" +
+		"bats,img/atlas.png,2,0,0,0,8\n")}
+	fsys["sprite/sprites.csv"] = &fstest.MapFile{Data: []byte("name,sheet,mode,start,nFrames,dimX,dimY,delay\n" +
+		"slime,slimes,idle,0,3,32,32,8\n" +
+		"bat,bats,idle,0,2,32,32,8\n" +
+		"ghost,ghosts,idle,0,1,32,32,8\n")}
+
+	assert.Equal(t, []string{
+		"sprite/atlas.csv:2: sheet slimes is missing frame 1",
+		"sprite/atlas.csv:4: sheet slimes has frame 2 twice",
+		"sprite/atlas.csv:5: frame (48,0)-(80,32) is outside page img/atlas.png",
+		"sprite/atlas.csv:6: sheet bats spans pages img/atlas.png and img/gone.png",
+		"sprite/atlas.csv:6: page img/gone.png not found",
+		"sprite/atlas.csv:7: atlas manifest record [bats img/atlas.png 2 0 0 0 8] has a bad frame index, size, or duration",
+		"sprite/sprites.csv:4: sprite ghost uses unknown sheet ghosts",
+	}, messages(newLinter(fsys).lint()))
+}
+
+func TestLintAseprite(t *testing.T) {
+	frame := func(x int) string {
+		return fmt.Sprintf(`{"frame": {"x": %d, "y": 0, "w": 32, "h": 32}, "sourceSize": {"w": 32, "h": 32}}`, x)
+	}
+	fsys := sampleContent(t)
+	fsys["img/aseprite/bat.png"] = &fstest.MapFile{Data: pngData(t, 64, 32)}
+	fsys["img/aseprite/bat.json"] = &fstest.MapFile{Data: []byte(`{"frames": [` + frame(0) + `, ` +
+		frame(32) + `], "meta": {"image": "bat.png"}}`)}
+	fsys["img/aseprite/wide.json"] = &fstest.MapFile{Data: []byte(`{"frames": [` + frame(48) +
+		`], "meta": {"image": "bat.png"}}`)}
+	fsys["img/aseprite/lost.json"] = &fstest.MapFile{Data: []byte(`{"frames": [` + frame(0) +
+		`], "meta": {"image": "lost.png"}}`)}
+	fsys["img/aseprite/loop.json"] = &fstest.MapFile{Data: []byte(`{"frames": [` + frame(0) +
+		`], "meta": {"image": "bat.png", "frameTags": [{"name": "idle", "from": 0, "to": 0, "repeat": "x"}]}}`)}
+	fsys["battle/pawns.csv"] = &fstest.MapFile{Data: []byte("id,name,sprite,maxHealth\n0,Slime,slime,12\n1,Bat,bat,6\n")}
+
+	assert.Equal(t, []string{
+		"img/aseprite/loop.json:0: tag idle has repeat \"x\"; want a count, or empty for forever",
+		"img/aseprite/lost.json:0: image img/aseprite/lost.png not found",
+		"img/aseprite/wide.json:0: frame 0 (48,0)-(80,32) is outside image img/aseprite/bat.png",
+	}, messages(newLinter(fsys).lint()))
+}
+
+func TestLintCardUpgrades(t *testing.T) {
+	fsys := sampleContent(t)
+	fsys["cards/data.csv"] = &fstest.MapFile{Data: []byte("id,name,img,desc,effect,upgrade,flags,cost,damage\n" +
+		"duck,Duck,img/card.png,Quack,,duck+,,1,2\n" +
+		"duck+,Duck+,img/card.png,Quack!,,,retain;sticky,1,3\n" +
+		"goose,Goose,img/card.png,Honk,,duck+,,,\n" +
+		"swan,Swan,img/card.png,Hiss,,cygnet,,,\n" +
+		"heron,Heron,img/card.png,Stab,,,,-1,lots\n")}
+	assert.Equal(t, []string{
+		"cards/data.csv:3: \"sticky\" is not a card flag",
+		"cards/data.csv:4: card duck+ is the upgrade of both duck and goose",
+		"cards/data.csv:5: card swan upgrades to unknown card cygnet",
+		"cards/data.csv:6: card heron cost \"-1\" is not a whole number of at least 0",
+		"cards/data.csv:6: card heron damage \"lots\" is not a whole number of at least 0",
+	}, messages(newLinter(fsys).lint()))
+}
+
+func TestLintProblems(t *testing.T) {
+	fsys := sampleContent(t)
+	fsys["sprite/sprites.csv"] = &fstest.MapFile{Data: []byte("name,sheet,mode,start,nFrames,dimX,dimY,delay\n" +
+		"slime,slimes,idle,1,2,32,32,8\n" +
+		"slime,slimes,idle,0,x,32,32,8\n" +
+		"ghost,nope,idle,0,1,32,32,8\n" +
+		"short,slimes,idle\n" +
+		"blink,slimes,blink,0,2,32,32,5;x;5\n")}
+	fsys["cards/data.csv"] = &fstest.MapFile{Data: []byte("id,name,img,desc,effect\nduck,Duck,img/gone.png,Quack,\n")}
+	fsys["battle/battles.csv"] = &fstest.MapFile{Data: []byte("id,pawns,background\n0,0;7,\n")}
+	fsys["text/fra.txt"] = &fstest.MapFile{Data: []byte("hello=Bonjour\nhello=Salut\nbroken\n")}
+
+	assert.Equal(t, []string{
+		"battle/battles.csv:2: battle 0 uses unknown pawn 7",
+		"cards/data.csv:2: image img/gone.png not found",
+		"sprite/sprites.csv:2: frames 1-2 are outside sheet slimes, which has 2 tiles",
+		"sprite/sprites.csv:3: duplicate ID slime/idle, first defined on line 2",
+		"sprite/sprites.csv:3: nFrames \"x\" is not an integer",
+		"sprite/sprites.csv:4: sprite ghost uses unknown sheet nope",
+		"sprite/sprites.csv:5: has 3 columns, want 8",
+		"sprite/sprites.csv:6: 3 delays given for 2 frames",
+		"sprite/sprites.csv:6: delay \"x\" is not a non-negative integer",
+		"text/fra.txt:2: duplicate ID hello, first defined on line 1",
+		"text/fra.txt:3: expected id=text",
+	}, messages(newLinter(fsys).lint()))
+}
@@ -0,0 +1,27 @@
+// contentlint : Check every content manifest for mistakes that would otherwise show up as runtime
+// errors. Prints file:line diagnostics and exits nonzero if there are any.
+//
+//	go run ./cmd/contentlint [-dir content]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	_ "image/png"
+)
+
+func main() {
+	dir := flag.String("dir", "content", "content directory to check")
+	flag.Parse()
+	diags := newLinter(os.DirFS(*dir)).lint()
+	for _, d := range diags {
+		d.file = *dir + "/" + d.file
+		fmt.Fprintln(os.Stderr, d)
+	}
+	if len(diags) > 0 {
+		fmt.Fprintf(os.Stderr, "%d problems found\n", len(diags))
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,37 @@
+// Package content : Game assets, and the filesystems they're loaded from. Paths in manifests are
+// relative to the root of this directory.
+package content
+
+import (
+	"embed"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+//go:embed audio battle cards img particles sprite text
+var embedded embed.FS
+
+// Embedded : The content compiled into the binary
+func Embedded() fs.FS {
+	return embedded
+}
+
+// WithMods : Overlay every mod folder in modsDir on top of base. Mods are applied in name order,
+// so later mods win. A missing modsDir means no mods.
+func WithMods(base fs.FS, modsDir string) (fs.FS, error) {
+	entries, err := os.ReadDir(modsDir)
+	if os.IsNotExist(err) {
+		return base, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	layers := []fs.FS{base}
+	for _, e := range entries {
+		if e.IsDir() {
+			layers = append(layers, os.DirFS(filepath.Join(modsDir, e.Name())))
+		}
+	}
+	return Overlay(layers...), nil
+}
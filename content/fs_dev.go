@@ -0,0 +1,29 @@
+//go:build dev
+// +build dev
+
+package content
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// Dev : Is this a development build, reading content from disk?
+const Dev = true
+
+// Dir : The content directory on disk. SPIDERS_CONTENT overrides the source checkout's directory.
+func Dir() string {
+	if dir := os.Getenv("SPIDERS_CONTENT"); dir != "" {
+		return dir
+	}
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}
+
+// FS : The content for this build. Dev builds read straight from disk, so edits show up without
+// a rebuild.
+func FS() fs.FS {
+	return os.DirFS(Dir())
+}
@@ -0,0 +1,15 @@
+//go:build !dev
+// +build !dev
+
+package content
+
+import "io/fs"
+
+// Dev : Is this a development build, reading content from disk?
+const Dev = false
+
+// FS : The content for this build. Release builds use the embedded content, so the game runs from
+// any directory.
+func FS() fs.FS {
+	return Embedded()
+}
@@ -0,0 +1,60 @@
+package content
+
+import (
+	"errors"
+	"io/fs"
+	"sort"
+)
+
+// overlayFS : Layered filesystems. Files in later layers shadow those in earlier ones.
+type overlayFS []fs.FS
+
+// Overlay : Stack filesystems, with later layers shadowing earlier ones. Directories are merged.
+func Overlay(layers ...fs.FS) fs.FS {
+	return overlayFS(layers)
+}
+
+// Open : Open the file from the topmost layer that has it
+func (o overlayFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	for i := len(o) - 1; i >= 0; i-- {
+		f, err := o[i].Open(name)
+		if err == nil {
+			return f, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadDir : List a directory across every layer
+func (o overlayFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	merged := map[string]fs.DirEntry{}
+	found := false
+	for _, layer := range o {
+		entries, err := fs.ReadDir(layer, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		found = true
+		for _, e := range entries {
+			merged[e.Name()] = e
+		}
+	}
+	if !found {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	result := make([]fs.DirEntry, 0, len(merged))
+	for _, e := range merged {
+		result = append(result, e)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name() < result[j].Name() })
+	return result, nil
+}
@@ -0,0 +1,42 @@
+package content_test
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/jessdwitch/spiders/content"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOverlay(t *testing.T) {
+	base := fstest.MapFS{
+		"sprite/sheets.csv": {Data: []byte("base")},
+		"text/eng.txt":      {Data: []byte("hello")},
+	}
+	mod := fstest.MapFS{
+		"sprite/sheets.csv": {Data: []byte("mod")},
+		"text/fra.txt":      {Data: []byte("bonjour")},
+	}
+	o := content.Overlay(base, mod)
+
+	b, err := fs.ReadFile(o, "sprite/sheets.csv")
+	assert.NoError(t, err)
+	assert.Equal(t, "mod", string(b))
+	b, err = fs.ReadFile(o, "text/eng.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(b))
+	_, err = o.Open("text/deu.txt")
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+
+	langs, err := fs.Glob(o, "text/*.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"text/eng.txt", "text/fra.txt"}, langs)
+}
+
+func TestEmbedded(t *testing.T) {
+	_, err := fs.Stat(content.Embedded(), "sprite/sheets.csv")
+	assert.NoError(t, err)
+}
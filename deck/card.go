@@ -1,26 +1,311 @@
 package deck
 
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/fs"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// DummyCardID : The ID of the placeholder card
+const DummyCardID CardID = "dummy"
+
 var (
 	// DummyCard : For when you need a placeholder card
 	DummyCard = Card{
-		// TODO: When we have card lookups, replace with const of Dummy card ID
-		ID: 0,
-		Name: "Dummy",
+		ID:          DummyCardID,
+		Name:        "Dummy",
 		Description: "This card does nothing!",
 	}
 )
 
-
-
 type (
 	// CardID : An identifier for pulling static card data. The preferred way to communicate card
 	//	data between systems.
-	CardID int
+	CardID string
 
-	// Card : Data for a card.
+	// Card : Data for a card. The CardTable holds the static data; each copy in a Deck is an
+	//	instance of it, with its own InstanceID and battle state. See NewInstance.
 	Card struct {
-		ID CardID
-		Name string
+		ID          CardID
+		Name        string
 		Description string
+		// Image : Path to the card art in the content filesystem
+		Image string
+		// Effect : Unparsed effect script
+		Effect string
+		// UpgradesTo : The card this becomes when upgraded, if it can be
+		UpgradesTo CardID
+		// Level : How many upgrades this is from a card that isn't an upgrade of anything
+		Level int
+		// Flags : Keywords the card has. Instances may gain or lose them, as from effects.
+		Flags CardFlags
+		// Cost, Damage : What the card takes to play, and deals when played
+		Cost   int
+		Damage int
+
+		// InstanceID : Tells apart copies of the same card. Zero in the CardTable.
+		InstanceID InstanceID
+		// CostModifier, DamageModifier : Added to Cost and Damage. Decks are built fresh for each
+		//	battle, so these only last the battle. See CurrentCost and CurrentDamage.
+		CostModifier   int
+		DamageModifier int
+	}
+
+	// InstanceID : Unique to each card instance made while the game runs
+	InstanceID uint64
+
+	// CardFlags : Keywords changing how a card moves between piles, as a bit set
+	CardFlags uint8
+
+	// CardTable : Static data for every card, by ID
+	CardTable map[CardID]Card
+)
+
+const (
+	// FlagRetain : Stays in hand at the end of the turn
+	FlagRetain CardFlags = 1 << iota
+	// FlagEthereal : Exhausted if still in hand at the end of the turn
+	FlagEthereal
+	// FlagExhaust : Exhausted when played
+	FlagExhaust
+)
+
+var cardFlagNames = map[CardFlags]string{
+	FlagRetain:   "retain",
+	FlagEthereal: "ethereal",
+	FlagExhaust:  "exhaust",
+}
+
+// lastInstanceID : The last InstanceID handed out
+var lastInstanceID uint64
+
+// ParseCardFlags : Parse a card manifest's flags: semicolon-separated names, as in
+// "retain;exhaust". Empty is no flags.
+func ParseCardFlags(field string) (CardFlags, error) {
+	var result CardFlags
+	if field == "" {
+		return result, nil
+	}
+	for _, name := range strings.Split(field, ";") {
+		found := false
+		for f, n := range cardFlagNames {
+			if n == name {
+				result |= f
+				found = true
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("%q is not a card flag", name)
+		}
+	}
+	return result, nil
+}
+
+// ParseCardStat : Parse a card manifest's cost or damage: a whole number, at least zero. Empty
+// is zero.
+func ParseCardStat(field string) (int, error) {
+	if field == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(field)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%q is not a whole number of at least 0", field)
+	}
+	return n, nil
+}
+
+func (f CardFlags) String() string {
+	names := []string{}
+	for flag := FlagRetain; flag <= FlagExhaust; flag <<= 1 {
+		if f&flag != 0 {
+			names = append(names, cardFlagNames[flag])
+		}
+	}
+	return strings.Join(names, ";")
+}
+
+// NewInstance : A copy of the card with its own InstanceID, to put in a deck
+func (c Card) NewInstance() *Card {
+	c.InstanceID = InstanceID(atomic.AddUint64(&lastInstanceID, 1))
+	return &c
+}
+
+// Has : Does the card have every flag in f?
+func (c *Card) Has(f CardFlags) bool {
+	return c.Flags&f == f
+}
+
+// CurrentCost : Cost with its modifier, never below zero
+func (c *Card) CurrentCost() int {
+	return clampStat(c.Cost + c.CostModifier)
+}
+
+// CurrentDamage : Damage with its modifier, never below zero
+func (c *Card) CurrentDamage() int {
+	return clampStat(c.Damage + c.DamageModifier)
+}
+
+func clampStat(n int) int {
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// Upgrade : Turn the card into its upgraded version from table. It stays the same instance, and
+// keeps its modifiers and any flags it gained or lost compared to its own row in table.
+func (c *Card) Upgrade(table CardTable) error {
+	if c.UpgradesTo == "" {
+		return fmt.Errorf("card %s can't be upgraded", c.ID)
+	}
+	upgraded, err := table.GetCard(c.UpgradesTo)
+	if err != nil {
+		return err
+	}
+	if base, err := table.GetCard(c.ID); err == nil {
+		gained, lost := c.Flags&^base.Flags, base.Flags&^c.Flags
+		upgraded.Flags = (upgraded.Flags | gained) &^ lost
+	}
+	upgraded.InstanceID = c.InstanceID
+	upgraded.CostModifier = c.CostModifier
+	upgraded.DamageModifier = c.DamageModifier
+	*c = upgraded
+	return nil
+}
+
+// NewCardTable : Read card data from a card manifest
+func NewCardTable(manifest *csv.Reader) (CardTable, error) {
+	result := CardTable{}
+	// strip header
+	_, err := manifest.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("card manifest is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+	for {
+		record, err := manifest.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		err = result.processManifestCsvRecord(record)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err = result.linkUpgrades(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (t CardTable) processManifestCsvRecord(record []string) error {
+	// record: id, name, img, desc, effect[, upgrade[, flags[, cost[, damage]]]]
+	if len(record) < 5 || len(record) > 9 {
+		return fmt.Errorf("card manifest record %v has %d columns, want 5 to 9", record, len(record))
+	}
+	id := CardID(record[0])
+	if _, ok := t[id]; ok {
+		return fmt.Errorf("card %s is defined more than once", id)
+	}
+	c := Card{
+		ID:          id,
+		Name:        record[1],
+		Image:       record[2],
+		Description: record[3],
+		Effect:      record[4],
+	}
+	if len(record) > 5 {
+		c.UpgradesTo = CardID(record[5])
+	}
+	if len(record) > 6 {
+		flags, err := ParseCardFlags(record[6])
+		if err != nil {
+			return fmt.Errorf("card %s: %w", id, err)
+		}
+		c.Flags = flags
+	}
+	for i, stat := range []*int{&c.Cost, &c.Damage} {
+		if len(record) <= 7+i {
+			break
+		}
+		n, err := ParseCardStat(record[7+i])
+		if err != nil {
+			return fmt.Errorf("card %s: %w", id, err)
+		}
+		*stat = n
+	}
+	t[id] = c
+	return nil
+}
+
+// linkUpgrades : Check every upgrade is a card, and set each card's Level from how many upgrades
+// lead to it
+func (t CardTable) linkUpgrades() error {
+	upgradeOf := map[CardID]CardID{}
+	for id, c := range t {
+		if c.UpgradesTo == "" {
+			continue
+		}
+		if _, ok := t[c.UpgradesTo]; !ok {
+			return fmt.Errorf("card %s upgrades to unknown card %s", id, c.UpgradesTo)
+		}
+		if other, ok := upgradeOf[c.UpgradesTo]; ok {
+			return fmt.Errorf("card %s is the upgrade of both %s and %s", c.UpgradesTo, other, id)
+		}
+		upgradeOf[c.UpgradesTo] = id
+	}
+	for id, c := range t {
+		level := 0
+		for from, ok := upgradeOf[id]; ok; from, ok = upgradeOf[from] {
+			if level++; level > len(t) {
+				return fmt.Errorf("card %s upgrades back into itself", id)
+			}
+		}
+		c.Level = level
+		t[id] = c
+	}
+	return nil
+}
+
+// LoadCardTable : Read a card manifest from a path in fsys
+func LoadCardTable(fsys fs.FS, path string) (CardTable, error) {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return NewCardTable(csv.NewReader(f))
+}
+
+// Reload : Replace the table's contents with a fresh read of the manifest. If the manifest can't
+// be read, the table is left as it was.
+func (t CardTable) Reload(fsys fs.FS, path string) error {
+	fresh, err := LoadCardTable(fsys, path)
+	if err != nil {
+		return err
+	}
+	for id := range t {
+		delete(t, id)
+	}
+	for id, c := range fresh {
+		t[id] = c
+	}
+	return nil
+}
+
+// GetCard : Look up a card's static data
+func (t CardTable) GetCard(id CardID) (Card, error) {
+	if c, ok := t[id]; ok {
+		return c, nil
 	}
-)
\ No newline at end of file
+	return Card{}, fmt.Errorf("card %s not found", id)
+}
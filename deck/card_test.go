@@ -0,0 +1,93 @@
+package deck_test
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/jessdwitch/spiders/deck"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const upgradeManifest = "id,name,img,desc,effect,upgrade,flags,cost,damage\n" +
+	"strike,Strike,,Hit,,strike+,,1,3\n" +
+	"strike+,Strike+,,Hit harder,,strike++,retain,1,5\n" +
+	"strike++,Strike++,,Hit hardest,,,retain;exhaust\n" +
+	"block,Block,,Guard,\n"
+
+func readCardTable(manifest string) (deck.CardTable, error) {
+	r := csv.NewReader(strings.NewReader(manifest))
+	r.FieldsPerRecord = -1
+	return deck.NewCardTable(r)
+}
+
+func TestCardTableUpgrades(t *testing.T) {
+	table, err := readCardTable(upgradeManifest)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, table["strike"].Level)
+	assert.Equal(t, 1, table["strike+"].Level)
+	assert.Equal(t, 2, table["strike++"].Level)
+	assert.Equal(t, deck.FlagRetain|deck.FlagExhaust, table["strike++"].Flags)
+	assert.Equal(t, "retain;exhaust", table["strike++"].Flags.String())
+
+	for name, manifest := range map[string]string{
+		"unknown upgrade": "id,name,img,desc,effect,upgrade,flags\na,A,,,,b,\n",
+		"shared upgrade":  "id,name,img,desc,effect,upgrade,flags\na,A,,,,c,\nb,B,,,,c,\nc,C,,,,,\n",
+		"cycle":           "id,name,img,desc,effect,upgrade,flags\na,A,,,,b,\nb,B,,,,a,\n",
+		"unknown flag":    "id,name,img,desc,effect,upgrade,flags\na,A,,,,,sticky\n",
+		"negative cost":   "id,name,img,desc,effect,upgrade,flags,cost\na,A,,,,,,-1\n",
+		"bad damage":      "id,name,img,desc,effect,upgrade,flags,cost,damage\na,A,,,,,,1,x\n",
+	} {
+		_, err := readCardTable(manifest)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestCardInstance(t *testing.T) {
+	table, err := readCardTable(upgradeManifest)
+	assert.NoError(t, err)
+	a, b := table["strike"].NewInstance(), table["strike"].NewInstance()
+	assert.NotZero(t, a.InstanceID)
+	assert.NotEqual(t, a.InstanceID, b.InstanceID)
+	assert.Zero(t, table["strike"].InstanceID)
+
+	assert.Equal(t, 1, a.CurrentCost())
+	assert.Equal(t, 3, a.CurrentDamage())
+	a.DamageModifier = 2
+	a.CostModifier = -2
+	assert.Equal(t, 0, a.CurrentCost(), "cost doesn't go below zero")
+	id := a.InstanceID
+	assert.NoError(t, a.Upgrade(table))
+	assert.Equal(t, deck.CardID("strike+"), a.ID)
+	assert.Equal(t, 1, a.Level)
+	assert.Equal(t, id, a.InstanceID)
+	assert.Equal(t, 2, a.DamageModifier)
+	assert.Equal(t, 7, a.CurrentDamage())
+	assert.True(t, a.Has(deck.FlagRetain))
+	assert.False(t, a.Has(deck.FlagRetain|deck.FlagExhaust))
+
+	// Flags the instance gained or lost carry over
+	a.Flags = a.Flags&^deck.FlagRetain | deck.FlagEthereal
+	assert.NoError(t, a.Upgrade(table))
+	assert.Equal(t, deck.FlagEthereal|deck.FlagExhaust, a.Flags)
+	assert.Equal(t, deck.CardID("strike"), b.ID)
+	assert.Error(t, table["block"].NewInstance().Upgrade(table))
+}
+
+func TestCollectionUpgrade(t *testing.T) {
+	table, err := readCardTable(upgradeManifest)
+	assert.NoError(t, err)
+	owned := deck.Collection{"strike": 2, "block": 1}
+	list := deck.DeckList{Cards: map[deck.CardID]int{"strike": 1}}
+	// A spare copy is upgraded, leaving the list alone
+	assert.NoError(t, owned.Upgrade(table, "strike", &list))
+	assert.Equal(t, deck.Collection{"strike": 1, "strike+": 1, "block": 1}, owned)
+	assert.Equal(t, map[deck.CardID]int{"strike": 1}, list.Cards)
+	// The last copy is in the list, so the list follows it
+	assert.NoError(t, owned.Upgrade(table, "strike", &list))
+	assert.Equal(t, deck.Collection{"strike+": 2, "block": 1}, owned)
+	assert.Equal(t, map[deck.CardID]int{"strike+": 1}, list.Cards)
+	assert.Error(t, owned.Upgrade(table, "block", nil))
+	assert.Error(t, owned.Upgrade(table, "strike", nil))
+}
@@ -1,7 +1,7 @@
 package deck
 
 import (
-	"math/rand"
+	"fmt"
 	"strings"
 )
 
@@ -9,32 +9,97 @@ type (
 	// Cardlist : A collection of Cards
 	Cardlist []*Card
 
-	// Deck : A collection of Cards, arranged into draw, hand, and discard piles
-	Deck struct {
-		DrawPile    Cardlist
-		DiscardPile Cardlist
-		Hand        Cardlist
-		ExhaustPile Cardlist
+	// Deck : A collection of Cards, arranged into draw, hand, discard, and exhaust piles. Indices
+	//	count down from the top of a pile. SliceDeck is the general purpose implementation;
+	//	LinkedDeck is cheaper to clone and draw from, as for simulating battles.
+	Deck interface {
+		// Pile : The cards in a pile, top first. Changing the list doesn't change the deck.
+		Pile(p Pile) Cardlist
+		// Len : How many cards are in a pile
+		Len(p Pile) int
 		// Count : Total cards in circulation (Draw + Discard + Hand)
-		Count int
-	}
-	// IDEA: A version of Deck where card state (which pile it's in) is stored in an array of ints.
-	//	It's sort of like a linked list. The indices correspond to the indices of the whole
-	//	cardlist. The value stored at each index is the next card in the pile. We store the "head"
-	//	of each pile.
-	// 	Compare to an array of enums which correspond to pile. Want the draw pile? In the enum array
-	//	that's always O(n of all cards), whereas with the linked one, it's O(n of pile). Drawing is
-	//	3 calls: update the draw head, update the hand head, correct reference at the end of the
-	//	drawn cards. Shuffles are much easier than an actual linked list: you pull the list of
-	//	indices, shuffle, and put them back (the catch being, you have to check for self-linked
-	//	nodes).
+		Count() int
+		// Clone : A copy of the deck, with copies of its cards, to change without touching this one
+		Clone() Deck
+		// Observe : Tell an Observer about every change to the deck from here on. Clones don't
+		//	inherit observers.
+		Observe(o Observer)
+
+		// DrawCards : Draw cards into the Hand, shuffling the Discard back in if the Draw runs out
+		//	before enough are drawn
+		DrawCards(howMany int) error
+		// ResetDraw : Shuffle the Draw and Discard piles together. Does nothing if the Discard is
+		//	empty.
+		ResetDraw()
+		// Discard : Move a card from the Hand to the top of the Discard
+		Discard(i int) error
+		// DiscardHand : Discard the Hand at the end of a turn. Cards with FlagRetain, or at an
+		//	index in retain, stay in hand. Ethereal cards that don't stay are exhausted instead.
+		DiscardHand(retain ...int) error
+		// AddCard : Add a card to the deck. If toDiscard is true, it's added to the bottom of the
+		//	discard, else randomly in the draw. A card without an InstanceID is given one.
+		AddCard(c *Card, toDiscard bool) error
+		// RemoveCard : Take a card out of the deck for good, from whichever pile it's in
+		RemoveCard(c *Card) error
+		// Move : Move the card at i in one pile to j in another. Cards moving in or out of the
+		//	exhaust pile leave or rejoin circulation.
+		Move(from Pile, i int, to Pile, j int) error
+		// Exhaust : Move a card from the Hand to the top of the Exhaust pile
+		Exhaust(i int) error
+		// Search : The indices in the Draw pile of every card matching match, from the top down,
+		//	as for effects that fetch a card from the deck. Move them out with Move.
+		Search(match func(*Card) bool) []int
+		// Scry : Look at the top howMany cards of the Draw pile, less if unavailable. Follow up
+		//	with ReorderTop.
+		Scry(howMany int) Cardlist
+		// ReorderTop : Rearrange the top howMany cards of the Draw pile. keep lists indices into
+		//	those cards, in the order they should end up on top. Any not kept are discarded.
+		ReorderTop(howMany int, keep []int) error
+	}
+
+	// SliceDeck : A Deck keeping each pile as a Cardlist
+	SliceDeck struct {
+		observers
+		draw    Cardlist
+		discard Cardlist
+		hand    Cardlist
+		exhaust Cardlist
+		count   int
+	}
+
+	// Pile : One of a Deck's piles
+	Pile int
 
 	// IndexOutOfBoundsError : Attempted to access an element outside the collection
 	IndexOutOfBoundsError struct{}
 )
 
-// NewDeck : Create a new Deck from a list of Cards
-func NewDeck(c Cardlist) *Deck {
+// Piles, with the top of each at index 0
+const (
+	PileDraw Pile = iota
+	PileHand
+	PileDiscard
+	// PileExhaust : Out of circulation for the rest of the battle
+	PileExhaust
+)
+
+var pileNames = map[Pile]string{
+	NoPile:      "none",
+	PileDraw:    "draw",
+	PileHand:    "hand",
+	PileDiscard: "discard",
+	PileExhaust: "exhaust",
+}
+
+func (p Pile) String() string {
+	if name, ok := pileNames[p]; ok {
+		return name
+	}
+	return fmt.Sprintf("Pile(%d)", int(p))
+}
+
+// NewDeck : Create a new Deck from a list of Cards, all in the shuffled Draw pile
+func NewDeck(c Cardlist) *SliceDeck {
 	// Deep copy cards
 	cards := make(Cardlist, len(c))
 	for i := 0; i < len(c); i++ {
@@ -42,57 +107,79 @@ func NewDeck(c Cardlist) *Deck {
 		cards[i] = &card
 	}
 	cards.Shuffle()
-	return &Deck{
-		DrawPile:    cards,
-		DiscardPile: Cardlist{},
-		Hand:        Cardlist{},
-		ExhaustPile: Cardlist{},
-		Count:       len(cards),
+	return &SliceDeck{
+		draw:    cards,
+		discard: Cardlist{},
+		hand:    Cardlist{},
+		exhaust: Cardlist{},
+		count:   len(cards),
 	}
 }
 
-// NewDeckFromIDs : Create a new Decklist from their lookup IDs mapped to their quantity
-func NewDeckFromIDs(ids map[CardID]int) (Deck, error) {
-	panic("Not implemented")
+// NewDeckFromIDs : Create a new Decklist from their lookup IDs mapped to their quantity. Each copy
+// is its own instance.
+func NewDeckFromIDs(table CardTable, ids map[CardID]int) (Deck, error) {
+	cards := Cardlist{}
+	for id, n := range ids {
+		c, err := table.GetCard(id)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < n; i++ {
+			cards = append(cards, c.NewInstance())
+		}
+	}
+	return NewDeck(cards), nil
 }
 
-// ResetDraw : Shuffle the Draw and Discard piles together
-func (d *Deck) ResetDraw() {
-	d.DrawPile = append(d.DrawPile, d.DiscardPile...)
-	d.DrawPile.Shuffle()
-	d.DiscardPile = d.DiscardPile[:0] // TODO: Revisit if a 0-slice is a good choice here
+func (d *SliceDeck) ResetDraw() {
+	if len(d.discard) == 0 {
+		return
+	}
+	d.draw = append(d.draw, d.discard...)
+	d.draw.Shuffle()
+	d.discard = d.discard[:0] // TODO: Revisit if a 0-slice is a good choice here
+	d.emit(DeckReshuffled, nil, PileDiscard, PileDraw)
 }
 
-// DrawCards : DrawCards cards from the deck. Get as many as possible from the DrawCards. If it runs out shuffle
-// and try again.
-func (d *Deck) DrawCards(howMany int) error {
+func (d *SliceDeck) DrawCards(howMany int) error {
 	// TODO: This function looks like shit lol
-	if howMany > len(d.DrawPile)+len(d.DiscardPile) {
-		howMany = len(d.DrawPile) + len(d.DiscardPile)
+	if howMany > len(d.draw)+len(d.discard) {
+		howMany = len(d.draw) + len(d.discard)
 	}
 	if howMany == 0 {
 		return nil // TODO: Do we want an error here? Enough cards just don't exist
 	}
-	// Do we need as many or more than Draw can provide?
-	if howMany >= len(d.DrawPile) {
-		d.Hand = append(d.Hand, d.DrawPile...)
-		howMany -= len(d.DrawPile)
-		d.DrawPile = d.DrawPile[:0]
+	// Do we need more than Draw can provide?
+	if howMany > len(d.draw) {
+		d.hand = append(d.hand, d.draw...)
+		d.drawn(d.draw)
+		howMany -= len(d.draw)
+		d.draw = d.draw[:0]
 		d.ResetDraw()
 		return d.DrawCards(howMany)
 	}
-	d.Hand = append(d.Hand, d.DrawPile[:howMany]...)
-	d.DrawPile = d.DrawPile[howMany:]
+	d.hand = append(d.hand, d.draw[:howMany]...)
+	d.drawn(d.draw[:howMany])
+	d.draw = d.draw[howMany:]
 	return nil
 }
 
-// Discard : Move a card from the Hand to the Discard
-func (d *Deck) Discard(i int) error {
-	if len(d.Hand) <= i {
+// drawn : Tell observers cards were drawn
+func (d *SliceDeck) drawn(cards Cardlist) {
+	for _, c := range cards {
+		d.moved(c, PileDraw, PileHand)
+	}
+}
+
+func (d *SliceDeck) Discard(i int) error {
+	if len(d.hand) <= i {
 		return &IndexOutOfBoundsError{}
 	}
-	d.DiscardPile.Insert(d.Hand[i], 0)
-	d.Hand = append(d.Hand[:i], d.Hand[i+1:]...)
+	card := d.hand[i]
+	d.discard.Insert(card, 0)
+	d.hand = append(d.hand[:i], d.hand[i+1:]...)
+	d.moved(card, PileHand, PileDiscard)
 	return nil
 }
 
@@ -125,28 +212,221 @@ func (c *Cardlist) Insert(card *Card, i int) error {
 	return nil
 }
 
-// AddCard : Add a card to the deck. If toDiscard is true, it's added to the discard, else randomly
-// in the draw.
-func (d *Deck) AddCard(c *Card, toDiscard bool) error {
+func (d *SliceDeck) AddCard(c *Card, toDiscard bool) error {
+	if c.InstanceID == 0 {
+		*c = *c.NewInstance()
+	}
+	to := PileDiscard
 	if toDiscard {
-		d.DiscardPile = append(d.DiscardPile, c)
+		d.discard = append(d.discard, c)
 	} else {
-		if err := d.DrawPile.Insert(c, rand.Intn(len(d.DrawPile))); err != nil {
+		to = PileDraw
+		if err := d.draw.Insert(c, shuffler.Intn(len(d.draw)+1)); err != nil {
 			return err
 		}
 	}
-	d.Count++
+	d.count++
+	d.emit(CardAdded, c, NoPile, to)
 	return nil
 }
 
-// RemoveCard : INCOMPLETE
-// func (d *Deck) RemoveCard() error {
+func (d *SliceDeck) Pile(p Pile) Cardlist {
+	pile, err := d.pile(p)
+	if err != nil {
+		return nil
+	}
+	return append(Cardlist{}, *pile...)
+}
+
+func (d *SliceDeck) Len(p Pile) int {
+	pile, err := d.pile(p)
+	if err != nil {
+		return 0
+	}
+	return len(*pile)
+}
+
+func (d *SliceDeck) Count() int {
+	return d.count
+}
+
+func (d *SliceDeck) Clone() Deck {
+	result := &SliceDeck{count: d.count}
+	// One block for every card copied
+	cards := make([]Card, 0, len(d.draw)+len(d.hand)+len(d.discard)+len(d.exhaust))
+	for _, p := range []Pile{PileDraw, PileHand, PileDiscard, PileExhaust} {
+		from, _ := d.pile(p)
+		to, _ := result.pile(p)
+		*to = make(Cardlist, len(*from))
+		for i, c := range *from {
+			cards = append(cards, *c)
+			(*to)[i] = &cards[len(cards)-1]
+		}
+	}
+	return result
+}
+
+// pile : The list holding a pile
+func (d *SliceDeck) pile(p Pile) (*Cardlist, error) {
+	switch p {
+	case PileDraw:
+		return &d.draw, nil
+	case PileHand:
+		return &d.hand, nil
+	case PileDiscard:
+		return &d.discard, nil
+	case PileExhaust:
+		return &d.exhaust, nil
+	}
+	return nil, fmt.Errorf("%v is not a pile", p)
+}
+
+// Remove : Take the card at i out of the list
+func (c *Cardlist) Remove(i int) (*Card, error) {
+	if i < 0 || i >= len(*c) {
+		return nil, &IndexOutOfBoundsError{}
+	}
+	card := (*c)[i]
+	*c = append((*c)[:i], (*c)[i+1:]...)
+	return card, nil
+}
 
-// }
+// Find : The index of a card in the list, or -1 if it isn't there
+func (c Cardlist) Find(card *Card) int {
+	for i, other := range c {
+		if other == card {
+			return i
+		}
+	}
+	return -1
+}
+
+func (d *SliceDeck) Move(from Pile, i int, to Pile, j int) error {
+	src, err := d.pile(from)
+	if err != nil {
+		return err
+	}
+	dst, err := d.pile(to)
+	if err != nil {
+		return err
+	}
+	if i < 0 || i >= len(*src) {
+		return &IndexOutOfBoundsError{}
+	}
+	// Check the destination before anything moves. Moving within a pile, it's one card shorter.
+	max := len(*dst)
+	if from == to {
+		max--
+	}
+	if j < 0 || j > max {
+		return &IndexOutOfBoundsError{}
+	}
+	card, _ := src.Remove(i)
+	// Already checked
+	_ = dst.Insert(card, j)
+	if from == PileExhaust && to != PileExhaust {
+		d.count++
+	} else if from != PileExhaust && to == PileExhaust {
+		d.count--
+	}
+	d.moved(card, from, to)
+	return nil
+}
+
+func (d *SliceDeck) Exhaust(i int) error {
+	return d.Move(PileHand, i, PileExhaust, 0)
+}
+
+func (d *SliceDeck) RemoveCard(c *Card) error {
+	for _, p := range []Pile{PileDraw, PileHand, PileDiscard, PileExhaust} {
+		pile, _ := d.pile(p)
+		if i := pile.Find(c); i >= 0 {
+			// Found, so in range
+			_, _ = pile.Remove(i)
+			if p != PileExhaust {
+				d.count--
+			}
+			d.emit(CardRemoved, c, p, NoPile)
+			return nil
+		}
+	}
+	return fmt.Errorf("card %s (instance %d) is not in the deck", c.ID, c.InstanceID)
+}
+
+func (d *SliceDeck) Search(match func(*Card) bool) []int {
+	result := []int{}
+	for i, c := range d.draw {
+		if match(c) {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+func (d *SliceDeck) Scry(howMany int) Cardlist {
+	return append(Cardlist{}, d.draw.Peak(howMany)...)
+}
+
+func (d *SliceDeck) ReorderTop(howMany int, keep []int) error {
+	if howMany > len(d.draw) {
+		howMany = len(d.draw)
+	}
+	top := d.draw[:howMany]
+	kept := make([]bool, howMany)
+	reordered := make(Cardlist, 0, howMany)
+	for _, i := range keep {
+		if i < 0 || i >= howMany {
+			return &IndexOutOfBoundsError{}
+		}
+		if kept[i] {
+			return fmt.Errorf("card %d is kept twice", i)
+		}
+		kept[i] = true
+		reordered = append(reordered, top[i])
+	}
+	discarded := Cardlist{}
+	for i, c := range top {
+		if !kept[i] {
+			discarded = append(discarded, c)
+		}
+	}
+	d.draw = append(reordered, d.draw[howMany:]...)
+	d.discard = append(discarded, d.discard...)
+	for _, c := range discarded {
+		d.moved(c, PileDraw, PileDiscard)
+	}
+	return nil
+}
+
+func (d *SliceDeck) DiscardHand(retain ...int) error {
+	retained := make([]bool, len(d.hand))
+	for _, i := range retain {
+		if i < 0 || i >= len(d.hand) {
+			return &IndexOutOfBoundsError{}
+		}
+		retained[i] = true
+	}
+	hand := Cardlist{}
+	for i, c := range d.hand {
+		switch {
+		case retained[i] || c.Has(FlagRetain):
+			hand = append(hand, c)
+		case c.Has(FlagEthereal):
+			d.exhaust = append(Cardlist{c}, d.exhaust...)
+			d.count--
+			d.moved(c, PileHand, PileExhaust)
+		default:
+			d.discard = append(Cardlist{c}, d.discard...)
+			d.moved(c, PileHand, PileDiscard)
+		}
+	}
+	d.hand = hand
+	return nil
+}
 
 // Shuffle : Shuffle these cards
 func (c *Cardlist) Shuffle() {
-	rand.Shuffle(len(*c), func(i, j int) { (*c)[i], (*c)[j] = (*c)[j], (*c)[i] })
+	shuffler.Shuffle(len(*c), func(i, j int) { (*c)[i], (*c)[j] = (*c)[j], (*c)[i] })
 }
 
 func (c *Cardlist) String() string {
@@ -38,6 +38,21 @@ func TestShuffle(t *testing.T) {
 	checkShuffle(t, initial, c)
 }
 
+func TestSeed(t *testing.T) {
+	initial := makeCardlist(20)
+	shuffle := func() deck.Cardlist {
+		c := append(deck.Cardlist{}, initial...)
+		c.Shuffle()
+		return c
+	}
+	deck.Seed(7)
+	first := shuffle()
+	deck.Seed(7)
+	// Randomness elsewhere in the game doesn't change the replay
+	rand.Float64()
+	assert.Equal(t, first, shuffle())
+}
+
 func TestInsert(t *testing.T) {
 	t.Run("Out of bounds", func(t *testing.T) {
 		c := makeCardlist(10)
@@ -74,96 +89,412 @@ func TestPeak(t *testing.T) {
 	assert.Equal(t, c, c.Peak(6))
 }
 
+// newDeckFunc : Makes a Deck, as NewDeck
+type newDeckFunc func(deck.Cardlist) deck.Deck
+
+// forEachDeck : Run a test against every Deck implementation
+func forEachDeck(t *testing.T, test func(t *testing.T, newDeck newDeckFunc)) {
+	for _, impl := range []struct {
+		name    string
+		newDeck newDeckFunc
+	}{
+		{"slice", func(c deck.Cardlist) deck.Deck { return deck.NewDeck(c) }},
+		{"linked", func(c deck.Cardlist) deck.Deck { return deck.NewLinkedDeck(c) }},
+	} {
+		t.Run(impl.name, func(t *testing.T) { test(t, impl.newDeck) })
+	}
+}
+
 func TestNewDeck(t *testing.T) {
-	c := makeCardlist(10)
-	initial := c
-	d := deck.NewDeck(c)
-	checkShuffle(t, initial, d.DrawPile)
-	assert.Len(t, d.DrawPile, 10)
-	assert.Len(t, d.DiscardPile, 0)
-	assert.Len(t, d.Hand, 0)
-	assert.Len(t, d.ExhaustPile, 0)
-	assert.Equal(t, d.Count, 10)
+	forEachDeck(t, func(t *testing.T, newDeck newDeckFunc) {
+		c := makeCardlist(10)
+		initial := c
+		d := newDeck(c)
+		checkShuffle(t, initial, d.Pile(deck.PileDraw))
+		assert.Len(t, d.Pile(deck.PileDraw), 10)
+		assert.Len(t, d.Pile(deck.PileDiscard), 0)
+		assert.Len(t, d.Pile(deck.PileHand), 0)
+		assert.Len(t, d.Pile(deck.PileExhaust), 0)
+		assert.Equal(t, d.Count(), 10)
+	})
 }
 
 func TestDraw(t *testing.T) {
-	t.Run("Regular draw", func(t *testing.T) {
-		c := makeCardlist(10)
-		d := deck.NewDeck(c)
-		initial := d.DrawPile
-		assert.NoError(t, d.DrawCards(3))
-		assert.Len(t, d.Hand, 3)
-		assert.ElementsMatch(t, initial[:3], d.Hand)
-		assert.Len(t, d.DrawPile, 7)
-		assert.ElementsMatch(t, initial[3:], d.DrawPile)
-		assert.Equal(t, d.Count, 10)
-	})
-	t.Run("Overdraw", func(t *testing.T) {
-		c := makeCardlist(10)
-		d := deck.NewDeck(c)
-		initial := d.DrawPile
-		assert.NoError(t, d.DrawCards(15))
-		assert.Len(t, d.Hand, 10)
-		assert.ElementsMatch(t, initial, d.Hand)
-		assert.Len(t, d.DrawPile, 0)
-		assert.Equal(t, d.Count, 10)
+	forEachDeck(t, func(t *testing.T, newDeck newDeckFunc) {
+		t.Run("Regular draw", func(t *testing.T) {
+			c := makeCardlist(10)
+			d := newDeck(c)
+			initial := d.Pile(deck.PileDraw)
+			assert.NoError(t, d.DrawCards(3))
+			assert.Len(t, d.Pile(deck.PileHand), 3)
+			assert.ElementsMatch(t, initial[:3], d.Pile(deck.PileHand))
+			assert.Len(t, d.Pile(deck.PileDraw), 7)
+			assert.ElementsMatch(t, initial[3:], d.Pile(deck.PileDraw))
+			assert.Equal(t, d.Count(), 10)
+		})
+		t.Run("Overdraw", func(t *testing.T) {
+			c := makeCardlist(10)
+			d := newDeck(c)
+			initial := d.Pile(deck.PileDraw)
+			assert.NoError(t, d.DrawCards(15))
+			assert.Len(t, d.Pile(deck.PileHand), 10)
+			assert.ElementsMatch(t, initial, d.Pile(deck.PileHand))
+			assert.Len(t, d.Pile(deck.PileDraw), 0)
+			assert.Equal(t, d.Count(), 10)
+		})
 	})
 }
 
 func TestAddCard(t *testing.T) {
-	c := makeCardlist(10)
-	t.Run("Add to Draw", func(t *testing.T) {
-		d := deck.NewDeck(c)
-		target := makeTestCard()
-		d.AddCard(target, false)
-		assert.Len(t, d.DrawPile, 11)
-		assert.Len(t, d.DiscardPile, 0)
-		assert.Equal(t, d.Count, 11)
-		assert.Contains(t, d.DrawPile, target)
-	})
-	t.Run("Add to Discard", func(t *testing.T) {
-		d := deck.NewDeck(c)
-		target := makeTestCard()
-		d.AddCard(target, true)
-		assert.Len(t, d.DrawPile, 10)
-		assert.Len(t, d.DiscardPile, 1)
-		assert.Equal(t, d.Count, 11)
-		assert.Equal(t, target, d.DiscardPile[0])
+	forEachDeck(t, func(t *testing.T, newDeck newDeckFunc) {
+		c := makeCardlist(10)
+		t.Run("Add to Draw", func(t *testing.T) {
+			d := newDeck(c)
+			target := makeTestCard()
+			d.AddCard(target, false)
+			assert.Len(t, d.Pile(deck.PileDraw), 11)
+			assert.Len(t, d.Pile(deck.PileDiscard), 0)
+			assert.Equal(t, d.Count(), 11)
+			assert.Contains(t, d.Pile(deck.PileDraw), target)
+		})
+		t.Run("Add to Discard", func(t *testing.T) {
+			d := newDeck(c)
+			target := makeTestCard()
+			d.AddCard(target, true)
+			assert.Len(t, d.Pile(deck.PileDraw), 10)
+			assert.Len(t, d.Pile(deck.PileDiscard), 1)
+			assert.Equal(t, d.Count(), 11)
+			assert.Equal(t, target, d.Pile(deck.PileDiscard)[0])
+		})
 	})
 }
 
 func TestDiscard(t *testing.T) {
-	c := makeCardlist(10)
-	d := deck.NewDeck(c)
-	d.DrawCards(5)
-	err := deck.IndexOutOfBoundsError{}
-	assert.EqualError(t, d.Discard(7), err.Error())
-	assert.Len(t, d.Hand, 5)
-	target := d.Hand[2]
-	assert.NoError(t, d.Discard(2))
-	assert.Len(t, d.Hand, 4)
-	assert.Len(t, d.DiscardPile, 1)
-	assert.Equal(t, target, d.DiscardPile[0])
+	forEachDeck(t, func(t *testing.T, newDeck newDeckFunc) {
+		c := makeCardlist(10)
+		d := newDeck(c)
+		d.DrawCards(5)
+		err := deck.IndexOutOfBoundsError{}
+		assert.EqualError(t, d.Discard(7), err.Error())
+		assert.Len(t, d.Pile(deck.PileHand), 5)
+		target := d.Pile(deck.PileHand)[2]
+		assert.NoError(t, d.Discard(2))
+		assert.Len(t, d.Pile(deck.PileHand), 4)
+		assert.Len(t, d.Pile(deck.PileDiscard), 1)
+		assert.Equal(t, target, d.Pile(deck.PileDiscard)[0])
+	})
 }
 
 func TestReset(t *testing.T) {
-	t.Run("Empty Discard", func(t *testing.T) {
-		c := makeCardlist(10)
-		d := deck.NewDeck(c)
-		d.DrawCards(3)
-		d.ResetDraw()
-		assert.Len(t, d.DrawPile, 7)
-		assert.Len(t, d.Hand, 3)
-		assert.Len(t, d.DiscardPile, 0)
+	forEachDeck(t, func(t *testing.T, newDeck newDeckFunc) {
+		t.Run("Empty Discard", func(t *testing.T) {
+			c := makeCardlist(10)
+			d := newDeck(c)
+			d.DrawCards(3)
+			d.ResetDraw()
+			assert.Len(t, d.Pile(deck.PileDraw), 7)
+			assert.Len(t, d.Pile(deck.PileHand), 3)
+			assert.Len(t, d.Pile(deck.PileDiscard), 0)
+		})
+		t.Run("With discard", func(t *testing.T) {
+			c := makeCardlist(10)
+			d := newDeck(c)
+			d.DrawCards(3)
+			d.Discard(2)
+			d.ResetDraw()
+			assert.Len(t, d.Pile(deck.PileDraw), 8)
+			assert.Len(t, d.Pile(deck.PileHand), 2)
+			assert.Len(t, d.Pile(deck.PileDiscard), 0)
+		})
 	})
-	t.Run("With discard", func(t *testing.T) {
-		c := makeCardlist(10)
-		d := deck.NewDeck(c)
+}
+
+// checkCount : Count should always be the cards in circulation
+func checkCount(t *testing.T, d deck.Deck) {
+	assert.Equal(t, len(d.Pile(deck.PileDraw))+len(d.Pile(deck.PileHand))+len(d.Pile(deck.PileDiscard)), d.Count())
+}
+
+func TestAddCardToEmptyDraw(t *testing.T) {
+	forEachDeck(t, func(t *testing.T, newDeck newDeckFunc) {
+		d := newDeck(deck.Cardlist{})
+		target := makeTestCard()
+		assert.NoError(t, d.AddCard(target, false))
+		assert.Equal(t, deck.Cardlist{target}, d.Pile(deck.PileDraw))
+		checkCount(t, d)
+	})
+}
+
+func TestExhaust(t *testing.T) {
+	forEachDeck(t, func(t *testing.T, newDeck newDeckFunc) {
+		d := newDeck(makeCardlist(10))
+		d.DrawCards(5)
+		target := d.Pile(deck.PileHand)[1]
+		assert.NoError(t, d.Exhaust(1))
+		assert.Len(t, d.Pile(deck.PileHand), 4)
+		assert.Equal(t, deck.Cardlist{target}, d.Pile(deck.PileExhaust))
+		assert.Equal(t, 9, d.Count())
+		checkCount(t, d)
+		err := deck.IndexOutOfBoundsError{}
+		assert.EqualError(t, d.Exhaust(4), err.Error())
+		assert.EqualError(t, d.Exhaust(-1), err.Error())
+		checkCount(t, d)
+	})
+}
+
+func TestRemoveCard(t *testing.T) {
+	forEachDeck(t, func(t *testing.T, newDeck newDeckFunc) {
+		d := newDeck(makeCardlist(10))
+		d.DrawCards(4)
+		d.Discard(0)
+		d.Exhaust(0)
+		for _, p := range []deck.Cardlist{d.Pile(deck.PileDraw), d.Pile(deck.PileHand), d.Pile(deck.PileDiscard), d.Pile(deck.PileExhaust)} {
+			target := p[0]
+			assert.NoError(t, d.RemoveCard(target))
+			assert.Error(t, d.RemoveCard(target))
+			checkCount(t, d)
+		}
+		assert.Len(t, d.Pile(deck.PileDraw), 5)
+		assert.Len(t, d.Pile(deck.PileHand), 1)
+		assert.Len(t, d.Pile(deck.PileDiscard), 0)
+		assert.Len(t, d.Pile(deck.PileExhaust), 0)
+		assert.Equal(t, 6, d.Count())
+	})
+}
+
+func TestMove(t *testing.T) {
+	forEachDeck(t, func(t *testing.T, newDeck newDeckFunc) {
+		t.Run("Between piles", func(t *testing.T) {
+			d := newDeck(makeCardlist(10))
+			target := d.Pile(deck.PileDraw)[3]
+			assert.NoError(t, d.Move(deck.PileDraw, 3, deck.PileDiscard, 0))
+			assert.Equal(t, deck.Cardlist{target}, d.Pile(deck.PileDiscard))
+			assert.NoError(t, d.Move(deck.PileDiscard, 0, deck.PileExhaust, 0))
+			assert.Equal(t, 9, d.Count())
+			checkCount(t, d)
+			assert.NoError(t, d.Move(deck.PileExhaust, 0, deck.PileHand, 0))
+			assert.Equal(t, deck.Cardlist{target}, d.Pile(deck.PileHand))
+			assert.Equal(t, 10, d.Count())
+			checkCount(t, d)
+		})
+		t.Run("Within a pile", func(t *testing.T) {
+			d := newDeck(makeCardlist(10))
+			target := d.Pile(deck.PileDraw)[0]
+			assert.NoError(t, d.Move(deck.PileDraw, 0, deck.PileDraw, 9))
+			assert.Equal(t, target, d.Pile(deck.PileDraw)[9])
+			assert.Len(t, d.Pile(deck.PileDraw), 10)
+			err := deck.IndexOutOfBoundsError{}
+			assert.EqualError(t, d.Move(deck.PileDraw, 0, deck.PileDraw, 10), err.Error())
+			checkCount(t, d)
+		})
+		t.Run("Out of bounds", func(t *testing.T) {
+			d := newDeck(makeCardlist(10))
+			initial := append(deck.Cardlist{}, d.Pile(deck.PileDraw)...)
+			err := deck.IndexOutOfBoundsError{}
+			assert.EqualError(t, d.Move(deck.PileDraw, 10, deck.PileHand, 0), err.Error())
+			assert.EqualError(t, d.Move(deck.PileDraw, 0, deck.PileHand, 1), err.Error())
+			assert.Error(t, d.Move(deck.PileDraw, 0, deck.Pile(9), 0))
+			assert.Equal(t, initial, d.Pile(deck.PileDraw))
+			checkCount(t, d)
+		})
+	})
+}
+
+func TestSearch(t *testing.T) {
+	forEachDeck(t, func(t *testing.T, newDeck newDeckFunc) {
+		d := newDeck(makeCardlist(10))
+		target := d.Pile(deck.PileDraw)[6]
+		found := d.Search(func(c *deck.Card) bool { return c == target })
+		assert.Equal(t, []int{6}, found)
+		assert.NoError(t, d.Move(deck.PileDraw, found[0], deck.PileHand, 0))
+		assert.Equal(t, deck.Cardlist{target}, d.Pile(deck.PileHand))
+		assert.Empty(t, d.Search(func(c *deck.Card) bool { return c == target }))
+		assert.Len(t, d.Search(func(*deck.Card) bool { return true }), 9)
+	})
+}
+
+func TestScry(t *testing.T) {
+	forEachDeck(t, func(t *testing.T, newDeck newDeckFunc) {
+		t.Run("Reorder and discard", func(t *testing.T) {
+			d := newDeck(makeCardlist(10))
+			initial := append(deck.Cardlist{}, d.Pile(deck.PileDraw)...)
+			assert.Equal(t, initial[:3], d.Scry(3))
+			assert.NoError(t, d.ReorderTop(3, []int{2, 0}))
+			assert.Equal(t, append(deck.Cardlist{initial[2], initial[0]}, initial[3:]...), d.Pile(deck.PileDraw))
+			assert.Equal(t, deck.Cardlist{initial[1]}, d.Pile(deck.PileDiscard))
+			checkCount(t, d)
+		})
+		t.Run("More than the draw pile", func(t *testing.T) {
+			d := newDeck(makeCardlist(2))
+			initial := append(deck.Cardlist{}, d.Pile(deck.PileDraw)...)
+			assert.Len(t, d.Scry(5), 2)
+			assert.NoError(t, d.ReorderTop(5, []int{1, 0}))
+			assert.Equal(t, deck.Cardlist{initial[1], initial[0]}, d.Pile(deck.PileDraw))
+		})
+		t.Run("Bad order", func(t *testing.T) {
+			d := newDeck(makeCardlist(10))
+			initial := append(deck.Cardlist{}, d.Pile(deck.PileDraw)...)
+			err := deck.IndexOutOfBoundsError{}
+			assert.EqualError(t, d.ReorderTop(3, []int{3}), err.Error())
+			assert.Error(t, d.ReorderTop(3, []int{1, 1}))
+			assert.Equal(t, initial, d.Pile(deck.PileDraw))
+			assert.Empty(t, d.Pile(deck.PileDiscard))
+		})
+	})
+}
+
+func TestDiscardHand(t *testing.T) {
+	forEachDeck(t, func(t *testing.T, newDeck newDeckFunc) {
+		d := newDeck(makeCardlist(10))
+		d.DrawCards(5)
+		d.Pile(deck.PileHand)[1].Flags = deck.FlagRetain
+		d.Pile(deck.PileHand)[2].Flags = deck.FlagEthereal
+		d.Pile(deck.PileHand)[3].Flags = deck.FlagEthereal
+		hand := append(deck.Cardlist{}, d.Pile(deck.PileHand)...)
+		err := deck.IndexOutOfBoundsError{}
+		assert.EqualError(t, d.DiscardHand(5), err.Error())
+		assert.NoError(t, d.DiscardHand(3))
+		assert.Equal(t, deck.Cardlist{hand[1], hand[3]}, d.Pile(deck.PileHand))
+		assert.Equal(t, deck.Cardlist{hand[2]}, d.Pile(deck.PileExhaust))
+		assert.ElementsMatch(t, deck.Cardlist{hand[0], hand[4]}, d.Pile(deck.PileDiscard))
+		assert.Equal(t, 9, d.Count())
+		checkCount(t, d)
+	})
+}
+
+func TestClone(t *testing.T) {
+	forEachDeck(t, func(t *testing.T, newDeck newDeckFunc) {
+		d := newDeck(makeCardlist(10))
 		d.DrawCards(3)
-		d.Discard(2)
+		d.Exhaust(0)
+		clone := d.Clone()
+		for _, p := range []deck.Pile{deck.PileDraw, deck.PileHand, deck.PileDiscard, deck.PileExhaust} {
+			assert.Equal(t, d.Pile(p), clone.Pile(p))
+		}
+		assert.Equal(t, d.Count(), clone.Count())
+		// Changes to either don't reach the other
+		clone.Pile(deck.PileHand)[0].DamageModifier = 2
+		assert.Zero(t, d.Pile(deck.PileHand)[0].DamageModifier)
+		assert.NoError(t, clone.DrawCards(7))
+		assert.Len(t, d.Pile(deck.PileHand), 2)
+		assert.Len(t, d.Pile(deck.PileDraw), 7)
+		checkCount(t, d)
+		checkCount(t, clone)
+	})
+}
+
+func TestObserve(t *testing.T) {
+	forEachDeck(t, func(t *testing.T, newDeck newDeckFunc) {
+		d := newDeck(makeCardlist(5))
+		events := []deck.Event{}
+		d.Observe(deck.ObserverFunc(func(e deck.Event) { events = append(events, e) }))
+		kinds := func() []deck.EventKind {
+			result := []deck.EventKind{}
+			for _, e := range events {
+				result = append(result, e.Kind)
+			}
+			events = events[:0]
+			return result
+		}
+
+		top := d.Pile(deck.PileDraw)[:2]
+		assert.NoError(t, d.DrawCards(2))
+		assert.Equal(t, []deck.Event{
+			{Kind: deck.CardDrawn, Card: top[0], From: deck.PileDraw, To: deck.PileHand},
+			{Kind: deck.CardDrawn, Card: top[1], From: deck.PileDraw, To: deck.PileHand},
+		}, events)
+		events = events[:0]
+		assert.NoError(t, d.Discard(0))
+		assert.NoError(t, d.Exhaust(0))
+		assert.Equal(t, []deck.EventKind{deck.CardDiscarded, deck.CardExhausted}, kinds())
+
+		// Drawing past the Draw pile reshuffles in between, but not once both piles are empty
+		assert.NoError(t, d.DrawCards(4))
+		assert.Equal(t, []deck.EventKind{
+			deck.CardDrawn, deck.CardDrawn, deck.CardDrawn, deck.DeckReshuffled, deck.CardDrawn,
+		}, kinds())
+
+		added := makeTestCard()
+		assert.NoError(t, d.AddCard(added, true))
+		assert.Equal(t, []deck.Event{{Kind: deck.CardAdded, Card: added, From: deck.NoPile, To: deck.PileDiscard}}, events)
+		events = events[:0]
+		assert.NoError(t, d.Move(deck.PileDiscard, 0, deck.PileHand, 0))
+		assert.NoError(t, d.Move(deck.PileHand, 0, deck.PileHand, 1))
+		assert.NoError(t, d.RemoveCard(added))
+		assert.Equal(t, []deck.EventKind{deck.CardMoved, deck.CardMoved, deck.CardRemoved}, kinds())
+
+		d.Pile(deck.PileHand)[0].Flags = deck.FlagEthereal
+		assert.NoError(t, d.DiscardHand())
+		assert.Equal(t, []deck.EventKind{
+			deck.CardExhausted, deck.CardDiscarded, deck.CardDiscarded, deck.CardDiscarded,
+		}, kinds())
+
+		// There's nothing to reshuffle with an empty Discard
+		d.ResetDraw()
 		d.ResetDraw()
-		assert.Len(t, d.DrawPile, 8)
-		assert.Len(t, d.Hand, 2)
-		assert.Len(t, d.DiscardPile, 0)
+		assert.Equal(t, []deck.EventKind{deck.DeckReshuffled}, kinds())
+		// Drawing exactly the rest of the Draw pile doesn't reshuffle, even with cards to
+		assert.NoError(t, d.DrawCards(1))
+		assert.NoError(t, d.Discard(0))
+		assert.NoError(t, d.DrawCards(2))
+		assert.Equal(t, []deck.EventKind{
+			deck.CardDrawn, deck.CardDiscarded, deck.CardDrawn, deck.CardDrawn,
+		}, kinds())
+		assert.NoError(t, d.DiscardHand())
+		assert.Equal(t, []deck.EventKind{deck.CardDiscarded, deck.CardDiscarded}, kinds())
+
+		// Failed operations and clones tell no one
+		assert.Error(t, d.Discard(0))
+		assert.NoError(t, d.Clone().DrawCards(2))
+		assert.Empty(t, events)
+	})
+}
+
+// benchmarkDecks : Run a benchmark against every Deck implementation, on a deck of deckSize
+func benchmarkDecks(b *testing.B, bench func(b *testing.B, d deck.Deck)) {
+	const deckSize = 30
+	cards := makeCardlist(deckSize)
+	for _, impl := range []struct {
+		name string
+		d    deck.Deck
+	}{
+		{"slice", deck.NewDeck(cards)},
+		{"linked", deck.NewLinkedDeck(cards)},
+	} {
+		b.Run(impl.name, func(b *testing.B) {
+			b.ReportAllocs()
+			bench(b, impl.d)
+		})
+	}
+}
+
+func BenchmarkTurn(b *testing.B) {
+	benchmarkDecks(b, func(b *testing.B, d deck.Deck) {
+		for i := 0; i < b.N; i++ {
+			d.DrawCards(5)
+			d.Discard(0)
+			d.DiscardHand()
+		}
+	})
+}
+
+func BenchmarkClone(b *testing.B) {
+	benchmarkDecks(b, func(b *testing.B, d deck.Deck) {
+		d.DrawCards(5)
+		for i := 0; i < b.N; i++ {
+			d.Clone()
+		}
+	})
+}
+
+func BenchmarkResetDraw(b *testing.B) {
+	benchmarkDecks(b, func(b *testing.B, d deck.Deck) {
+		for i := 0; i < b.N; i++ {
+			// ResetDraw does nothing with an empty discard, so put the whole deck there first
+			b.StopTimer()
+			d.DrawCards(d.Len(deck.PileDraw))
+			d.DiscardHand()
+			b.StartTimer()
+			d.ResetDraw()
+		}
 	})
 }
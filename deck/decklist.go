@@ -0,0 +1,185 @@
+package deck
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DefaultRules : Deck building limits for a standard battle
+var DefaultRules = DeckRules{MinSize: 10, MaxSize: 40, MaxCopies: 3}
+
+// starterCards : What a new player owns, every copy of it in their first deck. Keeps to
+// DefaultRules.
+var starterCards = map[CardID]int{DummyCardID: 3, "jab": 3, "strike": 3, "lunge": 1}
+
+type (
+	// Collection : Every card the player owns, by ID, with how many copies of each
+	Collection map[CardID]int
+
+	// DeckRules : Limits a deck list has to keep to. Zero means no limit.
+	DeckRules struct {
+		MinSize   int
+		MaxSize   int
+		MaxCopies int
+	}
+
+	// DeckList : The cards a deck is built from, by ID, with how many copies of each. Drawn from
+	//	a Collection, and made into a playable Deck with Build.
+	DeckList struct {
+		Name  string
+		Cards map[CardID]int
+	}
+)
+
+// Add : Gain n copies of a card
+func (c Collection) Add(id CardID, n int) {
+	c[id] += n
+}
+
+// Remove : Lose n copies of a card
+func (c Collection) Remove(id CardID, n int) error {
+	if c[id] < n {
+		return fmt.Errorf("can't remove %d of card %s, only %d owned", n, id, c[id])
+	}
+	if c[id] -= n; c[id] == 0 {
+		delete(c, id)
+	}
+	return nil
+}
+
+// Upgrade : Upgrade an owned copy of a card, as defined in table. If every owned copy was in
+// list, the list gets the upgraded one in its place. list may be nil.
+func (c Collection) Upgrade(table CardTable, id CardID, list *DeckList) error {
+	card, err := table.GetCard(id)
+	if err != nil {
+		return err
+	}
+	if card.UpgradesTo == "" {
+		return fmt.Errorf("card %s can't be upgraded", id)
+	}
+	if err = c.Remove(id, 1); err != nil {
+		return err
+	}
+	c.Add(card.UpgradesTo, 1)
+	if list != nil && list.Cards[id] > c[id] {
+		// Known to be there
+		_ = list.Remove(id)
+		list.Cards[card.UpgradesTo]++
+	}
+	return nil
+}
+
+// IDs : Every card owned, in ID order
+func (c Collection) IDs() []CardID {
+	return sortedIDs(c)
+}
+
+// StarterCollection : The cards a new player owns
+func StarterCollection() Collection {
+	result := Collection{}
+	for id, n := range starterCards {
+		result[id] = n
+	}
+	return result
+}
+
+// StarterDeckList : A new player's deck, of every card in StarterCollection
+func StarterDeckList() DeckList {
+	result := NewDeckList("Starter")
+	for id, n := range starterCards {
+		result.Cards[id] = n
+	}
+	return result
+}
+
+// NewDeckList : An empty deck list
+func NewDeckList(name string) DeckList {
+	return DeckList{Name: name, Cards: map[CardID]int{}}
+}
+
+// Size : How many cards are in the list, counting copies
+func (l DeckList) Size() int {
+	result := 0
+	for _, n := range l.Cards {
+		result += n
+	}
+	return result
+}
+
+// IDs : Every card in the list, in ID order
+func (l DeckList) IDs() []CardID {
+	return sortedIDs(l.Cards)
+}
+
+// Add : Put a copy of a card in the list, if the collection has one spare and the rules allow it
+func (l *DeckList) Add(id CardID, owned Collection, rules DeckRules) error {
+	if l.Cards == nil {
+		l.Cards = map[CardID]int{}
+	}
+	if l.Cards[id] >= owned[id] {
+		return fmt.Errorf("every copy of card %s owned is already in the deck", id)
+	}
+	if rules.MaxCopies > 0 && l.Cards[id] >= rules.MaxCopies {
+		return fmt.Errorf("deck can't have more than %d copies of card %s", rules.MaxCopies, id)
+	}
+	if rules.MaxSize > 0 && l.Size() >= rules.MaxSize {
+		return fmt.Errorf("deck can't have more than %d cards", rules.MaxSize)
+	}
+	l.Cards[id]++
+	return nil
+}
+
+// Remove : Take a copy of a card out of the list
+func (l *DeckList) Remove(id CardID) error {
+	if l.Cards[id] == 0 {
+		return fmt.Errorf("card %s isn't in the deck", id)
+	}
+	if l.Cards[id]--; l.Cards[id] == 0 {
+		delete(l.Cards, id)
+	}
+	return nil
+}
+
+// Validate : Check the list is playable: within the rules, and built only from cards owned
+func (l DeckList) Validate(owned Collection, rules DeckRules) error {
+	size := l.Size()
+	if size < rules.MinSize {
+		return fmt.Errorf("deck has %d cards, needs at least %d", size, rules.MinSize)
+	}
+	if rules.MaxSize > 0 && size > rules.MaxSize {
+		return fmt.Errorf("deck has %d cards, can't have more than %d", size, rules.MaxSize)
+	}
+	for _, id := range l.IDs() {
+		n := l.Cards[id]
+		if rules.MaxCopies > 0 && n > rules.MaxCopies {
+			return fmt.Errorf("deck has %d copies of card %s, can't have more than %d", n, id, rules.MaxCopies)
+		}
+		if n > owned[id] {
+			return fmt.Errorf("deck has %d copies of card %s, only %d owned", n, id, owned[id])
+		}
+	}
+	return nil
+}
+
+// Clone : A copy of the list which can be edited without touching this one
+func (l DeckList) Clone() DeckList {
+	result := NewDeckList(l.Name)
+	for id, n := range l.Cards {
+		result.Cards[id] = n
+	}
+	return result
+}
+
+// Build : Make a shuffled deck from the list
+func (l DeckList) Build(table CardTable) (Deck, error) {
+	return NewDeckFromIDs(table, l.Cards)
+}
+
+func sortedIDs(cards map[CardID]int) []CardID {
+	result := make([]CardID, 0, len(cards))
+	for id := range cards {
+		result = append(result, id)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
+}
@@ -0,0 +1,86 @@
+package deck_test
+
+import (
+	"testing"
+
+	"github.com/jessdwitch/spiders/content"
+	"github.com/jessdwitch/spiders/deck"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollection(t *testing.T) {
+	c := deck.Collection{}
+	c.Add("b", 2)
+	c.Add("a", 1)
+	assert.Equal(t, []deck.CardID{"a", "b"}, c.IDs())
+	assert.Error(t, c.Remove("a", 2))
+	assert.NoError(t, c.Remove("a", 1))
+	assert.Equal(t, []deck.CardID{"b"}, c.IDs())
+}
+
+func TestDeckList(t *testing.T) {
+	owned := deck.Collection{"a": 4, "b": 1}
+	rules := deck.DeckRules{MinSize: 2, MaxSize: 4, MaxCopies: 3}
+	t.Run("Add", func(t *testing.T) {
+		l := deck.NewDeckList("test")
+		assert.NoError(t, l.Add("b", owned, rules))
+		assert.Error(t, l.Add("b", owned, rules), "only one owned")
+		assert.Error(t, l.Add("c", owned, rules), "none owned")
+		for i := 0; i < 3; i++ {
+			assert.NoError(t, l.Add("a", owned, rules))
+		}
+		assert.Error(t, l.Add("a", owned, rules), "copy limit")
+		assert.Equal(t, 4, l.Size())
+		assert.Equal(t, []deck.CardID{"a", "b"}, l.IDs())
+	})
+	t.Run("Max size", func(t *testing.T) {
+		l := deck.DeckList{Cards: map[deck.CardID]int{"a": 3, "b": 1}}
+		assert.Error(t, l.Add("a", deck.Collection{"a": 9, "b": 1}, deck.DeckRules{MaxSize: 4}))
+		assert.NoError(t, l.Add("a", deck.Collection{"a": 9, "b": 1}, deck.DeckRules{}))
+	})
+	t.Run("Remove", func(t *testing.T) {
+		l := deck.DeckList{Cards: map[deck.CardID]int{"a": 1}}
+		assert.NoError(t, l.Remove("a"))
+		assert.Error(t, l.Remove("a"))
+		assert.Empty(t, l.Cards)
+	})
+	t.Run("Validate", func(t *testing.T) {
+		assert.NoError(t, deck.DeckList{Cards: map[deck.CardID]int{"a": 2}}.Validate(owned, rules))
+		assert.Error(t, deck.DeckList{Cards: map[deck.CardID]int{"a": 1}}.Validate(owned, rules), "too small")
+		assert.Error(t, deck.DeckList{Cards: map[deck.CardID]int{"a": 4, "b": 1}}.Validate(owned, rules), "too big")
+		assert.Error(t, deck.DeckList{Cards: map[deck.CardID]int{"a": 4}}.Validate(owned, rules), "too many copies")
+		assert.Error(t, deck.DeckList{Cards: map[deck.CardID]int{"b": 2}}.Validate(owned, rules), "not owned")
+	})
+	t.Run("Clone", func(t *testing.T) {
+		l := deck.DeckList{Name: "test", Cards: map[deck.CardID]int{"a": 2}}
+		c := l.Clone()
+		assert.NoError(t, c.Remove("a"))
+		assert.Equal(t, 2, l.Cards["a"])
+		assert.Equal(t, "test", c.Name)
+	})
+	t.Run("Build", func(t *testing.T) {
+		table := deck.CardTable{deck.DummyCardID: deck.DummyCard}
+		d, err := deck.DeckList{Cards: map[deck.CardID]int{deck.DummyCardID: 3}}.Build(table)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, d.Count())
+		_, err = deck.DeckList{Cards: map[deck.CardID]int{"missing": 1}}.Build(table)
+		assert.Error(t, err)
+	})
+}
+
+func TestStarter(t *testing.T) {
+	owned := deck.StarterCollection()
+	list := deck.StarterDeckList()
+	assert.NoError(t, list.Validate(owned, deck.DefaultRules))
+
+	// Every starter card is in the game's card data
+	table, err := deck.LoadCardTable(content.Embedded(), "cards/data.csv")
+	assert.NoError(t, err)
+	_, err = list.Build(table)
+	assert.NoError(t, err)
+
+	// Each call is a fresh copy
+	owned.Add(deck.DummyCardID, 1)
+	assert.NotEqual(t, owned, deck.StarterCollection())
+}
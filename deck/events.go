@@ -0,0 +1,103 @@
+package deck
+
+import "fmt"
+
+// NoPile : Where an added card comes from, and a removed card goes
+const NoPile Pile = -1
+
+// Kinds of deck events
+const (
+	// CardMoved : A card changed piles in a way without a kind of its own, or moved within one
+	CardMoved EventKind = iota
+	// CardDrawn : A card went from the Draw pile to the Hand
+	CardDrawn
+	// CardDiscarded : A card went to the Discard
+	CardDiscarded
+	// CardExhausted : A card went to the Exhaust pile
+	CardExhausted
+	// CardAdded : A card joined the deck
+	CardAdded
+	// CardRemoved : A card left the deck for good
+	CardRemoved
+	// DeckReshuffled : The Discard was shuffled back into the Draw pile. No Card.
+	DeckReshuffled
+)
+
+var eventKindNames = map[EventKind]string{
+	CardMoved:      "moved",
+	CardDrawn:      "drawn",
+	CardDiscarded:  "discarded",
+	CardExhausted:  "exhausted",
+	CardAdded:      "added",
+	CardRemoved:    "removed",
+	DeckReshuffled: "reshuffled",
+}
+
+type (
+	// EventKind : What happened to a deck
+	EventKind int
+	// Event : A card changing piles, or the deck reshuffling. Observers hear about each card on
+	//	its own, in the order they move.
+	Event struct {
+		Kind EventKind
+		Card *Card
+		// From, To : The piles the card left and joined. NoPile for cards added or removed.
+		From Pile
+		To   Pile
+	}
+	// Observer : Hears about every change to a deck, as it happens. Observers are called in the
+	//	middle of deck operations, so shouldn't change the deck themselves; queue changes up for
+	//	later instead.
+	Observer interface {
+		DeckChanged(e Event)
+	}
+	// ObserverFunc : A function as an Observer
+	ObserverFunc func(e Event)
+	// observers : Embedded in Deck implementations to tell Observers about changes
+	observers struct {
+		list []Observer
+	}
+)
+
+func (k EventKind) String() string {
+	if name, ok := eventKindNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("EventKind(%d)", int(k))
+}
+
+// DeckChanged : Call f
+func (f ObserverFunc) DeckChanged(e Event) {
+	f(e)
+}
+
+// moveKind : The kind of event for a card moving between piles
+func moveKind(from, to Pile) EventKind {
+	switch {
+	case to == PileExhaust && from != PileExhaust:
+		return CardExhausted
+	case to == PileDiscard && from != PileDiscard:
+		return CardDiscarded
+	case from == PileDraw && to == PileHand:
+		return CardDrawn
+	}
+	return CardMoved
+}
+
+// Observe : Tell o about every change to the deck from here on. Clones don't inherit observers.
+func (o *observers) Observe(obs Observer) {
+	o.list = append(o.list, obs)
+}
+
+func (o *observers) emit(kind EventKind, c *Card, from, to Pile) {
+	for _, obs := range o.list {
+		obs.DeckChanged(Event{Kind: kind, Card: c, From: from, To: to})
+	}
+}
+
+// moved : Tell observers a card moved between piles
+func (o *observers) moved(c *Card, from, to Pile) {
+	if len(o.list) > 0 {
+		o.emit(moveKind(from, to), c, from, to)
+	}
+}
@@ -0,0 +1,402 @@
+package deck
+
+import (
+	"fmt"
+)
+
+const (
+	// nPiles : How many piles a deck has
+	nPiles = 4
+	// linkEnd : The next index at the bottom of a pile
+	linkEnd = -1
+)
+
+type (
+	// LinkedDeck : A Deck keeping every card in one list, with each pile threaded through it as a
+	//	linked list of indices. The value at each index is the index of the next card down the
+	//	pile, and we store the head and tail of each pile. Cards never move, so drawing and
+	//	moving cards between piles only relinks a few indices and allocates nothing, walking a
+	//	pile is O(pile) rather than O(deck), and cloning is a couple of copies. Shuffles pull a
+	//	pile's indices out, shuffle them, and link them back up.
+	LinkedDeck struct {
+		observers
+		cards []*Card
+		// next : for each card, the index of the card under it in its pile
+		next  []int
+		head  [nPiles]int
+		tail  [nPiles]int
+		size  [nPiles]int
+		count int
+		// scratch : indices pulled out for shuffling, kept to save allocating each time
+		scratch []int
+	}
+)
+
+// NewLinkedDeck : As NewDeck, for a LinkedDeck
+func NewLinkedDeck(c Cardlist) *LinkedDeck {
+	d := &LinkedDeck{
+		cards: make(Cardlist, len(c)),
+		next:  make([]int, len(c)),
+		count: len(c),
+	}
+	for p := range d.head {
+		d.head[p], d.tail[p] = linkEnd, linkEnd
+	}
+	// Deep copy cards, into one block
+	block := make([]Card, len(c))
+	for i := range c {
+		block[i] = *c[i]
+		d.cards[i] = &block[i]
+	}
+	order := make([]int, len(c))
+	for i := range order {
+		order[i] = i
+	}
+	shuffler.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+	d.relink(PileDraw, order)
+	return d
+}
+
+// valid : Is p one of the piles?
+func (d *LinkedDeck) valid(p Pile) bool {
+	return p >= 0 && p < nPiles
+}
+
+func (d *LinkedDeck) Pile(p Pile) Cardlist {
+	if !d.valid(p) {
+		return nil
+	}
+	result := make(Cardlist, 0, d.size[p])
+	for i := d.head[p]; i != linkEnd; i = d.next[i] {
+		result = append(result, d.cards[i])
+	}
+	return result
+}
+
+func (d *LinkedDeck) Len(p Pile) int {
+	if !d.valid(p) {
+		return 0
+	}
+	return d.size[p]
+}
+
+func (d *LinkedDeck) Count() int {
+	return d.count
+}
+
+func (d *LinkedDeck) Clone() Deck {
+	result := &LinkedDeck{
+		cards: make(Cardlist, len(d.cards)),
+		next:  append([]int{}, d.next...),
+		head:  d.head,
+		tail:  d.tail,
+		size:  d.size,
+		count: d.count,
+	}
+	block := make([]Card, len(d.cards))
+	for i, c := range d.cards {
+		block[i] = *c
+		result.cards[i] = &block[i]
+	}
+	return result
+}
+
+// relink : Make a pile of the cards at indices, top first
+func (d *LinkedDeck) relink(p Pile, indices []int) {
+	d.size[p] = len(indices)
+	if len(indices) == 0 {
+		d.head[p], d.tail[p] = linkEnd, linkEnd
+		return
+	}
+	for k := 0; k < len(indices)-1; k++ {
+		d.next[indices[k]] = indices[k+1]
+	}
+	d.head[p], d.tail[p] = indices[0], indices[len(indices)-1]
+	d.next[d.tail[p]] = linkEnd
+}
+
+// unlink : Take the card at position i out of a pile, returning its index. i must be in range.
+func (d *LinkedDeck) unlink(p Pile, i int) int {
+	d.size[p]--
+	if i == 0 {
+		card := d.head[p]
+		d.head[p] = d.next[card]
+		if d.head[p] == linkEnd {
+			d.tail[p] = linkEnd
+		}
+		d.next[card] = linkEnd
+		return card
+	}
+	prev := d.head[p]
+	for k := 1; k < i; k++ {
+		prev = d.next[prev]
+	}
+	card := d.next[prev]
+	d.next[prev] = d.next[card]
+	if card == d.tail[p] {
+		d.tail[p] = prev
+	}
+	d.next[card] = linkEnd
+	return card
+}
+
+// link : Put the card at index card into a pile at position j. j must be in range.
+func (d *LinkedDeck) link(p Pile, j, card int) {
+	d.size[p]++
+	switch {
+	case j == 0:
+		d.next[card] = d.head[p]
+		d.head[p] = card
+		if d.tail[p] == linkEnd {
+			d.tail[p] = card
+		}
+	case j == d.size[p]-1:
+		d.next[card] = linkEnd
+		d.next[d.tail[p]] = card
+		d.tail[p] = card
+	default:
+		prev := d.head[p]
+		for k := 1; k < j; k++ {
+			prev = d.next[prev]
+		}
+		d.next[card] = d.next[prev]
+		d.next[prev] = card
+	}
+}
+
+// splice : Move the top howMany cards of one pile to the bottom of another, in order. howMany
+// must be in range.
+func (d *LinkedDeck) splice(from, to Pile, howMany int) {
+	if howMany == 0 {
+		return
+	}
+	first, last := d.head[from], d.head[from]
+	for k := 1; k < howMany; k++ {
+		last = d.next[last]
+	}
+	d.head[from] = d.next[last]
+	if d.head[from] == linkEnd {
+		d.tail[from] = linkEnd
+	}
+	d.size[from] -= howMany
+	d.next[last] = linkEnd
+	if d.tail[to] == linkEnd {
+		d.head[to] = first
+	} else {
+		d.next[d.tail[to]] = first
+	}
+	d.tail[to] = last
+	d.size[to] += howMany
+	if len(d.list) > 0 {
+		for i := first; i != linkEnd; i = d.next[i] {
+			d.moved(d.cards[i], from, to)
+		}
+	}
+}
+
+func (d *LinkedDeck) ResetDraw() {
+	if d.size[PileDiscard] == 0 {
+		return
+	}
+	d.scratch = d.scratch[:0]
+	for _, p := range []Pile{PileDraw, PileDiscard} {
+		for i := d.head[p]; i != linkEnd; i = d.next[i] {
+			d.scratch = append(d.scratch, i)
+		}
+	}
+	s := d.scratch
+	shuffler.Shuffle(len(s), func(i, j int) { s[i], s[j] = s[j], s[i] })
+	d.relink(PileDraw, d.scratch)
+	d.relink(PileDiscard, nil)
+	d.emit(DeckReshuffled, nil, PileDiscard, PileDraw)
+}
+
+func (d *LinkedDeck) DrawCards(howMany int) error {
+	if howMany > d.size[PileDraw]+d.size[PileDiscard] {
+		howMany = d.size[PileDraw] + d.size[PileDiscard]
+	}
+	for howMany > 0 {
+		// Do we need more than Draw can provide?
+		if howMany > d.size[PileDraw] {
+			howMany -= d.size[PileDraw]
+			d.splice(PileDraw, PileHand, d.size[PileDraw])
+			d.ResetDraw()
+			continue
+		}
+		d.splice(PileDraw, PileHand, howMany)
+		howMany = 0
+	}
+	return nil
+}
+
+func (d *LinkedDeck) Discard(i int) error {
+	return d.Move(PileHand, i, PileDiscard, 0)
+}
+
+func (d *LinkedDeck) DiscardHand(retain ...int) error {
+	for _, i := range retain {
+		if i < 0 || i >= d.size[PileHand] {
+			return &IndexOutOfBoundsError{}
+		}
+	}
+	card := d.head[PileHand]
+	d.relink(PileHand, nil)
+	for k := 0; card != linkEnd; k++ {
+		next := d.next[card]
+		retained := d.cards[card].Has(FlagRetain)
+		for _, i := range retain {
+			retained = retained || i == k
+		}
+		switch {
+		case retained:
+			d.link(PileHand, d.size[PileHand], card)
+		case d.cards[card].Has(FlagEthereal):
+			d.link(PileExhaust, 0, card)
+			d.count--
+			d.moved(d.cards[card], PileHand, PileExhaust)
+		default:
+			d.link(PileDiscard, 0, card)
+			d.moved(d.cards[card], PileHand, PileDiscard)
+		}
+		card = next
+	}
+	return nil
+}
+
+func (d *LinkedDeck) AddCard(c *Card, toDiscard bool) error {
+	if c.InstanceID == 0 {
+		*c = *c.NewInstance()
+	}
+	d.cards = append(d.cards, c)
+	d.next = append(d.next, linkEnd)
+	card := len(d.cards) - 1
+	to := PileDiscard
+	if toDiscard {
+		d.link(PileDiscard, d.size[PileDiscard], card)
+	} else {
+		to = PileDraw
+		d.link(PileDraw, shuffler.Intn(d.size[PileDraw]+1), card)
+	}
+	d.count++
+	d.emit(CardAdded, c, NoPile, to)
+	return nil
+}
+
+func (d *LinkedDeck) RemoveCard(c *Card) error {
+	for _, p := range []Pile{PileDraw, PileHand, PileDiscard, PileExhaust} {
+		k := 0
+		for i := d.head[p]; i != linkEnd; i = d.next[i] {
+			if d.cards[i] == c {
+				// The card stays in the list, but isn't in any pile
+				d.unlink(p, k)
+				if p != PileExhaust {
+					d.count--
+				}
+				d.emit(CardRemoved, c, p, NoPile)
+				return nil
+			}
+			k++
+		}
+	}
+	return fmt.Errorf("card %s (instance %d) is not in the deck", c.ID, c.InstanceID)
+}
+
+func (d *LinkedDeck) Move(from Pile, i int, to Pile, j int) error {
+	for _, p := range []Pile{from, to} {
+		if !d.valid(p) {
+			return fmt.Errorf("%v is not a pile", p)
+		}
+	}
+	if i < 0 || i >= d.size[from] {
+		return &IndexOutOfBoundsError{}
+	}
+	// Check the destination before anything moves. Moving within a pile, it's one card shorter.
+	max := d.size[to]
+	if from == to {
+		max--
+	}
+	if j < 0 || j > max {
+		return &IndexOutOfBoundsError{}
+	}
+	card := d.unlink(from, i)
+	d.link(to, j, card)
+	if from == PileExhaust && to != PileExhaust {
+		d.count++
+	} else if from != PileExhaust && to == PileExhaust {
+		d.count--
+	}
+	d.moved(d.cards[card], from, to)
+	return nil
+}
+
+func (d *LinkedDeck) Exhaust(i int) error {
+	return d.Move(PileHand, i, PileExhaust, 0)
+}
+
+func (d *LinkedDeck) Search(match func(*Card) bool) []int {
+	result := []int{}
+	k := 0
+	for i := d.head[PileDraw]; i != linkEnd; i = d.next[i] {
+		if match(d.cards[i]) {
+			result = append(result, k)
+		}
+		k++
+	}
+	return result
+}
+
+func (d *LinkedDeck) Scry(howMany int) Cardlist {
+	if howMany > d.size[PileDraw] {
+		howMany = d.size[PileDraw]
+	}
+	result := make(Cardlist, 0, howMany)
+	for i := d.head[PileDraw]; len(result) < howMany; i = d.next[i] {
+		result = append(result, d.cards[i])
+	}
+	return result
+}
+
+func (d *LinkedDeck) ReorderTop(howMany int, keep []int) error {
+	if howMany > d.size[PileDraw] {
+		howMany = d.size[PileDraw]
+	}
+	for k, i := range keep {
+		if i < 0 || i >= howMany {
+			return &IndexOutOfBoundsError{}
+		}
+		for _, other := range keep[:k] {
+			if other == i {
+				return fmt.Errorf("card %d is kept twice", i)
+			}
+		}
+	}
+	top := d.scratch[:0]
+	for k := 0; k < howMany; k++ {
+		top = append(top, d.unlink(PileDraw, 0))
+	}
+	d.scratch = top
+	// Going from the bottom up, so the first ends up on top
+	for k := howMany - 1; k >= 0; k-- {
+		kept := false
+		for _, i := range keep {
+			kept = kept || i == k
+		}
+		if !kept {
+			d.link(PileDiscard, 0, top[k])
+		}
+	}
+	// Told in order from the top, as SliceDeck does
+	for k := 0; k < howMany; k++ {
+		kept := false
+		for _, i := range keep {
+			kept = kept || i == k
+		}
+		if !kept {
+			d.moved(d.cards[top[k]], PileDraw, PileDiscard)
+		}
+	}
+	for k := len(keep) - 1; k >= 0; k-- {
+		d.link(PileDraw, 0, top[keep[k]])
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+package deck
+
+import (
+	"math/rand"
+	"time"
+)
+
+// shuffler : The randomness behind every shuffle and random insert. It's kept apart from the rest
+// of the game's, so after reseeding, shuffles replay the same however much camera shake or
+// particles have used randomness since.
+var shuffler = rand.New(rand.NewSource(time.Now().UnixNano()))
+
+// Seed : Reseed shuffles, as for replaying a battle
+func Seed(seed int64) {
+	shuffler.Seed(seed)
+}
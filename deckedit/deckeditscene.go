@@ -0,0 +1,214 @@
+package deckedit
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/jessdwitch/spiders/deck"
+	"github.com/jessdwitch/spiders/engine"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font/basicfont"
+)
+
+const (
+	menuTop       = 60
+	menuLineSpace = 18
+	// visibleRows : Cards shown in each column before it scrolls
+	visibleRows = 16
+)
+
+// columnLeft : Where each column starts
+var columnLeft = [2]int{80, 340}
+
+var (
+	labelColor  color.Color = color.White
+	dimColor    color.Color = color.Gray{Y: 0x80}
+	cursorColor color.Color = color.RGBA{0xf0, 0xb1, 0xb1, 0xff}
+	errorColor  color.Color = color.RGBA{0xff, 0x40, 0x40, 0xff}
+)
+
+const (
+	collectionColumn = iota
+	deckColumn
+)
+
+type (
+	// DeckEditScene : Browse the collection, and move cards in and out of the deck list. Cancel
+	//	keeps the changes and saves the game, if the deck is valid; menu throws them away.
+	DeckEditScene struct {
+		back  engine.Scene
+		cards deck.CardTable
+		owned deck.Collection
+		rules deck.DeckRules
+		// list : the deck being edited, kept apart from the game state until it's saved
+		list   deck.DeckList
+		column int
+		cursor [2]int
+		scroll [2]int
+		// lastErr : Why the last edit or save didn't happen
+		lastErr error
+		// saveFailed : The last try at leaving couldn't save, so the next leaves without saving
+		saveFailed bool
+	}
+)
+
+// NewDeckEditScene : Edit the player's deck list under rules, returning to back when done
+func NewDeckEditScene(state *engine.GameState, back engine.Scene, rules deck.DeckRules) *DeckEditScene {
+	return &DeckEditScene{
+		back:  back,
+		cards: state.Cards,
+		owned: state.Collection,
+		rules: rules,
+		list:  state.DeckList.Clone(),
+	}
+}
+
+// rows : The card IDs in a column
+func (s *DeckEditScene) rows(column int) []deck.CardID {
+	if column == collectionColumn {
+		return s.owned.IDs()
+	}
+	return s.list.IDs()
+}
+
+// selected : The card under the cursor, if the column has any
+func (s *DeckEditScene) selected() (deck.CardID, bool) {
+	rows := s.rows(s.column)
+	if len(rows) == 0 {
+		return "", false
+	}
+	return rows[s.cursor[s.column]], true
+}
+
+// moveCursor : Step the cursor in the current column, keeping it in view
+func (s *DeckEditScene) moveCursor(dir int) {
+	n := len(s.rows(s.column))
+	if n == 0 {
+		return
+	}
+	c := (s.cursor[s.column] + dir + n) % n
+	s.cursor[s.column] = c
+	if c < s.scroll[s.column] {
+		s.scroll[s.column] = c
+	} else if c >= s.scroll[s.column]+visibleRows {
+		s.scroll[s.column] = c - visibleRows + 1
+	}
+}
+
+// clampCursors : Keep the cursors on a row after cards come and go
+func (s *DeckEditScene) clampCursors() {
+	for column := range s.cursor {
+		if n := len(s.rows(column)); s.cursor[column] >= n {
+			s.cursor[column] = 0
+			if n > 0 {
+				s.cursor[column] = n - 1
+			}
+		}
+		if s.scroll[column] > s.cursor[column] {
+			s.scroll[column] = s.cursor[column]
+		}
+	}
+}
+
+func (s *DeckEditScene) Update(state *engine.GameState) error {
+	switch {
+	case state.Input.IsJustPressed(engine.ButtonUp):
+		s.moveCursor(-1)
+		return state.PlaySFX(engine.SFXMove)
+	case state.Input.IsJustPressed(engine.ButtonDown):
+		s.moveCursor(1)
+		return state.PlaySFX(engine.SFXMove)
+	case state.Input.IsJustPressed(engine.ButtonLeft):
+		s.column = collectionColumn
+		return state.PlaySFX(engine.SFXMove)
+	case state.Input.IsJustPressed(engine.ButtonRight):
+		s.column = deckColumn
+		return state.PlaySFX(engine.SFXMove)
+	case state.Input.IsJustPressed(engine.ButtonConfirm):
+		id, ok := s.selected()
+		if !ok {
+			return nil
+		}
+		if s.column == collectionColumn {
+			s.lastErr = s.list.Add(id, s.owned, s.rules)
+		} else {
+			s.lastErr = s.list.Remove(id)
+		}
+		s.clampCursors()
+		if s.lastErr != nil {
+			return state.PlaySFX(engine.SFXCancel)
+		}
+		return state.PlaySFX(engine.SFXConfirm)
+	case state.Input.IsJustPressed(engine.ButtonCancel):
+		if s.lastErr = s.list.Validate(s.owned, s.rules); s.lastErr != nil {
+			return state.PlaySFX(engine.SFXCancel)
+		}
+		if err := state.PlaySFX(engine.SFXConfirm); err != nil {
+			return err
+		}
+		state.DeckList = s.list
+		if err := state.Save(); err != nil && !s.saveFailed {
+			s.lastErr = fmt.Errorf("deck not saved (back again to leave anyway): %w", err)
+			s.saveFailed = true
+			return nil
+		}
+		s.saveFailed = false
+		state.SceneManager.GoTo(s.back)
+	case state.Input.IsJustPressed(engine.ButtonMenu):
+		if err := state.PlaySFX(engine.SFXCancel); err != nil {
+			return err
+		}
+		state.SceneManager.GoTo(s.back)
+	}
+	return nil
+}
+
+// label : A card's name and count, by ID if it's missing from the card table
+func (s *DeckEditScene) label(id deck.CardID, n int) string {
+	name := string(id)
+	if c, err := s.cards.GetCard(id); err == nil {
+		name = c.Name
+	}
+	return fmt.Sprintf("%s x%d", name, n)
+}
+
+func (s *DeckEditScene) Draw(r *ebiten.Image) {
+	face := basicfont.Face7x13
+	headings := [2]string{
+		"Collection",
+		fmt.Sprintf("%s (%d cards)", s.list.Name, s.list.Size()),
+	}
+	for column, left := range columnLeft {
+		clr := dimColor
+		if column == s.column {
+			clr = labelColor
+		}
+		text.Draw(r, headings[column], face, left, menuTop-menuLineSpace, clr)
+		rows := s.rows(column)
+		for i := s.scroll[column]; i < len(rows) && i < s.scroll[column]+visibleRows; i++ {
+			id := rows[i]
+			n := s.owned[id] - s.list.Cards[id]
+			if column == deckColumn {
+				n = s.list.Cards[id]
+			}
+			y := menuTop + (i-s.scroll[column]+1)*menuLineSpace
+			rowColor := clr
+			if column == s.column && i == s.cursor[column] {
+				rowColor = cursorColor
+				text.Draw(r, ">", face, left-2*face.Advance, y, cursorColor)
+			}
+			text.Draw(r, s.label(id, n), face, left, y, rowColor)
+		}
+	}
+	y := menuTop + (visibleRows+2)*menuLineSpace
+	if id, ok := s.selected(); ok {
+		if c, err := s.cards.GetCard(id); err == nil {
+			text.Draw(r, c.Description, face, columnLeft[0], y, labelColor)
+		}
+	}
+	if s.lastErr != nil {
+		text.Draw(r, s.lastErr.Error(), face, columnLeft[0], y+menuLineSpace, errorColor)
+	}
+}
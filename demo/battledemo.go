@@ -1,11 +1,10 @@
 package demo
 
 import (
-	"encoding/csv"
 	"image/color"
-	"os"
 
 	"github.com/jessdwitch/spiders/battle"
+	"github.com/jessdwitch/spiders/content"
 	"github.com/jessdwitch/spiders/deck"
 	"github.com/jessdwitch/spiders/engine"
 	"github.com/jessdwitch/spiders/engine/render"
@@ -15,45 +14,44 @@ import (
 )
 
 func BattleDemo() *engine.Game {
-	const (
-		screenWidth  = 640
-		screenHeight = 480
-	)
-
 	state := engine.NewGameState(&title.TitleScene{})
-	background := ebiten.NewImage(screenWidth, screenHeight)
+	background := ebiten.NewImage(state.Config.ScreenWidth, state.Config.ScreenHeight)
 	background.Fill(color.RGBA{240, 177, 177, 1})
-	scene, err := battle.NewBattleScene(
-		state,
-		background,
-		[]int{0, 0},
-		true,
-		map[deck.CardID]int{0: 5},
-	)
-	if err != nil {
+	var err error
+	state.Content = content.FS()
+	if state.Cards, err = deck.LoadCardTable(state.Content, "cards/data.csv"); err != nil {
 		panic(err)
 	}
-	state.SceneManager.GoTo(scene)
-
-	sheetF, err := os.Open("./content/sprite/sheets.csv")
+	enemies, err := battle.LoadEnemyTable(state.Content, "battle/pawns.csv")
 	if err != nil {
 		panic(err)
 	}
-	defer sheetF.Close()
-	sheetMani := csv.NewReader(sheetF)
-	spriteF, err := os.Open("./content/sprite/sprites.csv")
+	sprites, err := render.NewSpriteFactoryFromManifests(
+		state.Content, "sprite/sheets.csv", "sprite/sprites.csv", state.Assets)
 	if err != nil {
 		panic(err)
 	}
-	defer spriteF.Close()
-	spriteMani := csv.NewReader(spriteF)
-
-	sprites, err := render.NewSpriteFactoryFromManifests(sheetMani, spriteMani)
+	// The red slime is a palette swap, which needs shaders
+	if state.Shaders, err = render.NewShaderLibrary(); err != nil {
+		panic(err)
+	}
+	sprites.SetShaders(state.Shaders)
+	scene, err := battle.NewBattleScene(
+		state,
+		enemies,
+		sprites,
+		background,
+		[]int{0, 0},
+		true,
+		state.DeckList,
+		nil,
+	)
 	if err != nil {
 		panic(err)
 	}
+	state.SceneManager.GoTo(scene)
 
-	g := &engine.Game{nil, state, sprites}
+	g := &engine.Game{GameState: state, SpriteGetter: sprites}
 
 	return g
 }
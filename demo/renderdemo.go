@@ -1,91 +1,82 @@
 package demo
 
 import (
-	"encoding/csv"
 	"image/color"
-	"os"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/jessdwitch/spiders/content"
 	"github.com/jessdwitch/spiders/engine"
 	"github.com/jessdwitch/spiders/engine/render"
 )
 
 type renderDemoScene struct {
+	factory render.SpriteGetter
 	sprites []render.Sprite
 }
 
+// RenderDemoSprites : The sprites shown by the render demo
+var RenderDemoSprites = []render.SpriteID{"slime_blue", "slime_red", "slime_green", "slime_white"}
+
 func RenderDemo() *engine.Game {
-	const (
-		screenWidth  = 640
-		screenHeight = 480
-	)
-	background := ebiten.NewImage(screenWidth, screenHeight)
+	state := engine.NewGameState(nil)
+	background := ebiten.NewImage(state.Config.ScreenWidth, state.Config.ScreenHeight)
 	background.Fill(color.Black)
 
-	sheetF, err := os.Open("./content/sprite/sheets.csv")
+	sprites, err := render.NewSpriteFactoryFromManifests(
+		content.FS(), "sprite/sheets.csv", "sprite/sprites.csv", state.Assets)
 	if err != nil {
 		panic(err)
 	}
-	defer sheetF.Close()
-	sheetMani := csv.NewReader(sheetF)
-	spriteF, err := os.Open("./content/sprite/sprites.csv")
-	if err != nil {
+	// Red and white slimes are palette swaps, which need shaders
+	if state.Shaders, err = render.NewShaderLibrary(); err != nil {
 		panic(err)
 	}
-	defer spriteF.Close()
-	spriteMani := csv.NewReader(spriteF)
-
-	sprites, err := render.NewSpriteFactoryFromManifests(sheetMani, spriteMani)
+	sprites.SetShaders(state.Shaders)
+	scene, err := NewRenderDemoScene(sprites)
 	if err != nil {
 		panic(err)
 	}
-	scene, err := newRenderDemoScene(sprites)
-	if err != nil {
-		panic(err)
-	}
-	state := engine.NewGameState(scene)
+	state.SceneManager.GoTo(scene)
 
-	g := &engine.Game{nil, state, sprites}
+	g := &engine.Game{GameState: state, SpriteGetter: sprites}
 
 	return g
 }
 
-func newRenderDemoScene(factory *render.SpriteFactory) (*renderDemoScene, error) {
+// NewRenderDemoScene : A handful of animated slimes
+func NewRenderDemoScene(factory render.SpriteGetter) (engine.Scene, error) {
 	var err error
 	var s render.Sprite
 	sprites := make([]render.Sprite, 4)
-	s, err = factory.GetSprite("slime_blue")
+	s, err = factory.GetSprite(RenderDemoSprites[0])
 	if err != nil {
 		return nil, err
 	}
-	s.Scale(4, 4)
 	s.Translate(200, 200)
 	s.Animate("idle")
 	sprites[0] = s
 
-	sprites[1], err = factory.GetSprite("slime_red")
+	sprites[1], err = factory.GetSprite(RenderDemoSprites[1])
 	if err != nil {
 		return nil, err
 	}
-	sprites[1].Scale(3, 3)
 	sprites[1].Translate(300, 300)
 	sprites[1].Animate("idle")
 	sprites[1].SetDelay(10)
-	sprites[2], err = factory.GetSprite("slime_green")
+	sprites[2], err = factory.GetSprite(RenderDemoSprites[2])
 	if err != nil {
 		return nil, err
 	}
-	sprites[2].Scale(2, 2)
 	sprites[2].Translate(400, 200)
 	sprites[2].Animate("idle")
 	sprites[2].SetDelay(3)
-	sprites[3], err = factory.GetSprite("slime_white")
+	sprites[3], err = factory.GetSprite(RenderDemoSprites[3])
 	if err != nil {
 		return nil, err
 	}
 	sprites[3].Translate(500, 300)
 	sprites[3].Animate("idle")
-	return &renderDemoScene{sprites}, nil
+	return &renderDemoScene{factory, sprites}, nil
 }
 
 func (r *renderDemoScene) Draw(i *ebiten.Image) {
@@ -94,6 +85,20 @@ func (r *renderDemoScene) Draw(i *ebiten.Image) {
 	}
 }
 
+// Leave : Release the slimes, so their sheets can be evicted
+func (r *renderDemoScene) Leave(_ *engine.GameState) error {
+	releaser, ok := r.factory.(render.SpriteReleaser)
+	if !ok {
+		return nil
+	}
+	for _, id := range RenderDemoSprites {
+		if err := releaser.Release(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (r *renderDemoScene) Update(_ *engine.GameState) error {
 	var err error
 	for _, s := range r.sprites {
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"github.com/jessdwitch/spiders/battle"
+	"github.com/jessdwitch/spiders/engine"
+	"github.com/jessdwitch/spiders/engine/hotreload"
+	"github.com/jessdwitch/spiders/engine/render"
+)
+
+// reloadInterval : Ticks between checks for edited content
+const reloadInterval = 30
+
+// watchContent : Reload sprites, cards, and enemies as their files are edited. Sprites made from
+// here on are rebuilt in place.
+func watchContent(
+	g *engine.Game, sheets string, sprites *render.SpriteFactory, enemies battle.EnemyTable,
+) error {
+	fsys := g.GameState.Content
+	w, err := hotreload.NewWatcher(fsys, reloadInterval)
+	if err != nil {
+		return err
+	}
+	sprites.TrackSprites()
+	reloadSprites := func() error {
+		return sprites.Reload(fsys, sheets, spriteManifest)
+	}
+	watches := []struct {
+		pattern string
+		reload  hotreload.Reloader
+	}{
+		{sheets, reloadSprites},
+		{spriteManifest, reloadSprites},
+		{"img/sprite/*.png", reloadSprites},
+		{"img/atlas/*.png", reloadSprites},
+		{"img/aseprite/*", reloadSprites},
+		{cardManifest, func() error { return g.GameState.Cards.Reload(fsys, cardManifest) }},
+		{pawnManifest, func() error { return enemies.Reload(fsys, pawnManifest) }},
+	}
+	for _, wt := range watches {
+		if err = w.Watch(wt.pattern, wt.reload); err != nil {
+			return err
+		}
+	}
+	g.Overlays = append(g.Overlays, w)
+	return nil
+}
+
+// addConsole : Show the debug overlay and console. goto can go to the given scenes.
+func addConsole(g *engine.Game, scenes map[string]func(*engine.GameState) (engine.Scene, error)) {
+	console := engine.NewConsole()
+	for name, build := range scenes {
+		console.AddScene(name, build)
+	}
+	g.Overlays = append(g.Overlays, engine.NewDebugOverlay(g, console))
+}
@@ -0,0 +1,193 @@
+// Shared, reference-counted cache for decoded assets
+
+package assets
+
+import (
+	"sync"
+)
+
+type (
+	// ID : Identifies a cached asset. Prefix with the asset kind (as in "sheet/slimes_blue") so
+	//	different kinds can't collide.
+	ID string
+	// Loader : Decode an asset from its source
+	Loader func() (interface{}, error)
+	// Request : An asset to preload, and how to load it
+	Request struct {
+		ID   ID
+		Load Loader
+	}
+	// Progress : Called as preloading moves along, with how many of total are finished
+	Progress func(done, total int)
+	// Manager : Caches decoded images, sheets, fonts, audio, or anything else by ID, so each is
+	//	only decoded once no matter how many users it has. Safe for concurrent use.
+	Manager struct {
+		mu      sync.Mutex
+		entries map[ID]*entry
+		// stale : References still held to invalidated values. Released before the current
+		//	entry's, as they were taken first.
+		stale map[ID]int
+	}
+	entry struct {
+		value interface{}
+		err   error
+		// refs : Number of Acquires without a matching Release
+		refs int
+		// ready : Closed when loading finishes
+		ready chan struct{}
+	}
+)
+
+// NewManager : Get an empty asset cache
+func NewManager() *Manager {
+	return &Manager{entries: map[ID]*entry{}, stale: map[ID]int{}}
+}
+
+// Acquire : Get an asset, loading it if it isn't cached, and hold a reference to it. If another
+// goroutine is already loading the asset, wait for it rather than loading twice.
+func (m *Manager) Acquire(id ID, load Loader) (interface{}, error) {
+	e, loading := m.entry(id, true)
+	if loading {
+		m.load(e, load)
+	}
+	<-e.ready
+	if e.err != nil {
+		m.mu.Lock()
+		// Don't cache failures, so fixing the file and asking again works
+		if m.entries[id] == e {
+			e.refs--
+			delete(m.entries, id)
+		} else {
+			m.unrefStale(id)
+		}
+		m.mu.Unlock()
+		return nil, e.err
+	}
+	return e.value, nil
+}
+
+// Release : Drop a reference taken by Acquire. Unreferenced assets stay cached until Evict. If
+// the asset was invalidated, references to the old value are dropped first.
+func (m *Manager) Release(id ID) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.unrefStale(id) {
+		return
+	}
+	if e, ok := m.entries[id]; ok && e.refs > 0 {
+		e.refs--
+	}
+}
+
+// unrefStale : Drop a reference to an invalidated value of id, if any are held. Call with mu
+// locked.
+func (m *Manager) unrefStale(id ID) bool {
+	n, ok := m.stale[id]
+	if !ok {
+		return false
+	}
+	if n <= 1 {
+		delete(m.stale, id)
+	} else {
+		m.stale[id] = n - 1
+	}
+	return true
+}
+
+// Preload : Load assets in the background without holding references to them. progress may be
+// nil; otherwise it's called from the loading goroutine after each asset. The returned channel
+// gets the first error, if any, and is closed when loading is done.
+func (m *Manager) Preload(reqs []Request, progress Progress) <-chan error {
+	result := make(chan error, 1)
+	go func() {
+		defer close(result)
+		var first error
+		for i, r := range reqs {
+			e, loading := m.entry(r.ID, false)
+			if loading {
+				m.load(e, r.Load)
+			}
+			<-e.ready
+			if e.err != nil && first == nil {
+				first = e.err
+			}
+			if progress != nil {
+				progress(i+1, len(reqs))
+			}
+		}
+		if first != nil {
+			result <- first
+		}
+	}()
+	return result
+}
+
+// Evict : Drop every loaded asset nobody holds a reference to. Returns how many were dropped.
+func (m *Manager) Evict() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for id, e := range m.entries {
+		select {
+		case <-e.ready:
+		default:
+			// Still loading
+			continue
+		}
+		if e.refs == 0 {
+			delete(m.entries, id)
+			n++
+		}
+	}
+	return n
+}
+
+// Invalidate : Drop an asset whatever its references, so the next Acquire loads it fresh. Holders
+// of the old value keep it. Used when the source changes on disk.
+func (m *Manager) Invalidate(id ID) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e, ok := m.entries[id]; ok && e.refs > 0 {
+		m.stale[id] += e.refs
+	}
+	delete(m.entries, id)
+}
+
+// Refs : How many references are held to an asset. Zero if it isn't cached.
+func (m *Manager) Refs(id ID) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e, ok := m.entries[id]; ok {
+		return e.refs
+	}
+	return 0
+}
+
+// Cached : Is the asset loaded or loading?
+func (m *Manager) Cached(id ID) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.entries[id]
+	return ok
+}
+
+// entry : Get or create the entry for id. loading is true if the caller is responsible for
+// loading it.
+func (m *Manager) entry(id ID, ref bool) (e *entry, loading bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[id]
+	if !ok {
+		e = &entry{ready: make(chan struct{})}
+		m.entries[id] = e
+	}
+	if ref {
+		e.refs++
+	}
+	return e, !ok
+}
+
+func (m *Manager) load(e *entry, load Loader) {
+	defer close(e.ready)
+	e.value, e.err = load()
+}
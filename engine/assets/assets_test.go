@@ -0,0 +1,107 @@
+package assets_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jessdwitch/spiders/engine/assets"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func countingLoader(count *int32, value interface{}) assets.Loader {
+	return func() (interface{}, error) {
+		atomic.AddInt32(count, 1)
+		return value, nil
+	}
+}
+
+func TestAcquire(t *testing.T) {
+	t.Run("Loads once", func(t *testing.T) {
+		m := assets.NewManager()
+		var loads int32
+		wg := sync.WaitGroup{}
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				v, err := m.Acquire("sheet/slime", countingLoader(&loads, "slime"))
+				assert.NoError(t, err)
+				assert.Equal(t, "slime", v)
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, int32(1), loads)
+		assert.Equal(t, 5, m.Refs("sheet/slime"))
+	})
+	t.Run("Failures aren't cached", func(t *testing.T) {
+		m := assets.NewManager()
+		fail := errors.New("bad png")
+		_, err := m.Acquire("sheet/slime", func() (interface{}, error) { return nil, fail })
+		assert.Equal(t, fail, err)
+		assert.False(t, m.Cached("sheet/slime"))
+		v, err := m.Acquire("sheet/slime", func() (interface{}, error) { return "fixed", nil })
+		assert.NoError(t, err)
+		assert.Equal(t, "fixed", v)
+	})
+}
+
+func TestEvict(t *testing.T) {
+	m := assets.NewManager()
+	var loads int32
+	_, _ = m.Acquire("a", countingLoader(&loads, 1))
+	_, _ = m.Acquire("b", countingLoader(&loads, 2))
+	_, _ = m.Acquire("b", countingLoader(&loads, 2))
+	m.Release("a")
+	m.Release("b")
+	assert.Equal(t, 1, m.Evict())
+	assert.False(t, m.Cached("a"))
+	assert.True(t, m.Cached("b"))
+	m.Release("b")
+	assert.Equal(t, 1, m.Evict())
+	assert.Equal(t, int32(2), loads)
+}
+
+func TestPreload(t *testing.T) {
+	m := assets.NewManager()
+	var loads int32
+	reqs := []assets.Request{
+		{ID: "a", Load: countingLoader(&loads, 1)},
+		{ID: "b", Load: countingLoader(&loads, 2)},
+		{ID: "a", Load: countingLoader(&loads, 1)},
+	}
+	progress := []int{}
+	for err := range m.Preload(reqs, func(done, total int) {
+		assert.Equal(t, 3, total)
+		progress = append(progress, done)
+	}) {
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, []int{1, 2, 3}, progress)
+	assert.Equal(t, int32(2), loads)
+	assert.Equal(t, 0, m.Refs("a"))
+	v, err := m.Acquire("a", countingLoader(&loads, 1))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, v)
+	assert.Equal(t, int32(2), loads, "preloaded assets shouldn't load again")
+}
+
+func TestInvalidate(t *testing.T) {
+	m := assets.NewManager()
+	var loads int32
+	_, _ = m.Acquire("a", countingLoader(&loads, "old"))
+	m.Invalidate("a")
+	assert.False(t, m.Cached("a"))
+	v, err := m.Acquire("a", countingLoader(&loads, "new"))
+	assert.NoError(t, err)
+	assert.Equal(t, "new", v)
+	// The old holder letting go shouldn't drop the new holder's reference
+	m.Release("a")
+	assert.Equal(t, 1, m.Refs("a"))
+	assert.Equal(t, 0, m.Evict())
+	m.Release("a")
+	assert.Equal(t, 0, m.Refs("a"))
+	assert.Equal(t, 1, m.Evict())
+}
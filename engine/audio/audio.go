@@ -0,0 +1,304 @@
+// Music and sound effect playback
+
+package audio
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// nSFXVoices : How many copies of one sound effect can play over each other
+const nSFXVoices = 4
+
+// ErrClipNotFound : The requested ClipID isn't in the manifest
+var ErrClipNotFound = errors.New("clip not found")
+
+type (
+	// ClipID : An identifier for a registered music track or sound effect
+	ClipID string
+	// Bus : A volume channel clips are mixed into
+	Bus int
+	// ClipMeta : Where to find a clip, and how to play it
+	ClipMeta struct {
+		ID   ClipID
+		Bus  Bus
+		Path string
+		// Volume : The clip's own volume, before bus and master volumes are applied
+		Volume float64
+	}
+	// Manifest : Every registered clip
+	Manifest map[ClipID]ClipMeta
+	// Player : Handle for a single playing clip
+	Player interface {
+		Play()
+		Pause()
+		Rewind() error
+		IsPlaying() bool
+		SetVolume(float64)
+		Close() error
+	}
+	// Backend : Makes Players from clips
+	Backend interface {
+		// NewPlayer : Get a Player for a clip. Looping players start over when they reach the end.
+		NewPlayer(meta ClipMeta, loop bool) (Player, error)
+	}
+	// Mixer : Plays background music and sound effects through volume buses
+	Mixer struct {
+		backend  Backend
+		manifest Manifest
+		master   float64
+		buses    map[Bus]float64
+		music    *track
+		// fadingOut : The previous music track, on its way out during a crossfade
+		fadingOut *track
+		sfx       map[ClipID][]Player
+	}
+	// track : A music player with crossfade state
+	track struct {
+		meta   ClipMeta
+		player Player
+		// fade : How far faded in this track is, from 0 to 1
+		fade float64
+		// step : Change in fade per tick
+		step float64
+	}
+)
+
+const (
+	BusMusic Bus = iota
+	BusSFX
+)
+
+var busNames = map[string]Bus{
+	"music": BusMusic,
+	"sfx":   BusSFX,
+}
+
+// NewManifest : Read a clip manifest
+func NewManifest(manifest *csv.Reader) (Manifest, error) {
+	result := Manifest(make(map[ClipID]ClipMeta))
+	// strip header
+	_, err := manifest.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("audio manifest is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+	for {
+		record, err := manifest.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		err = result.processManifestCsvRecord(record)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (m Manifest) processManifestCsvRecord(record []string) error {
+	// record: id, bus, path, volume
+	if len(record) != 4 {
+		return fmt.Errorf("audio manifest record %v has %d columns, want 4", record, len(record))
+	}
+	bus, ok := busNames[record[1]]
+	if !ok {
+		return fmt.Errorf("clip %s has unknown bus %s", record[0], record[1])
+	}
+	volume, err := strconv.ParseFloat(record[3], 64)
+	if err != nil {
+		return err
+	}
+	m[ClipID(record[0])] = ClipMeta{
+		ID:     ClipID(record[0]),
+		Bus:    bus,
+		Path:   record[2],
+		Volume: volume,
+	}
+	return nil
+}
+
+// NewMixer : Get a Mixer with every bus at full volume
+func NewMixer(backend Backend, manifest Manifest) *Mixer {
+	return &Mixer{
+		backend:  backend,
+		manifest: manifest,
+		master:   1,
+		buses:    map[Bus]float64{BusMusic: 1, BusSFX: 1},
+		sfx:      map[ClipID][]Player{},
+	}
+}
+
+// SetVolumes : Set the master and per-bus volumes, each from 0 to 1
+func (m *Mixer) SetVolumes(master, music, sfx float64) {
+	m.master = master
+	m.buses[BusMusic] = music
+	m.buses[BusSFX] = sfx
+	for _, t := range []*track{m.music, m.fadingOut} {
+		if t != nil {
+			t.player.SetVolume(m.volume(t.meta) * t.fade)
+		}
+	}
+	for id, voices := range m.sfx {
+		for _, p := range voices {
+			p.SetVolume(m.volume(m.manifest[id]))
+		}
+	}
+}
+
+func (m *Mixer) volume(meta ClipMeta) float64 {
+	return m.master * m.buses[meta.Bus] * meta.Volume
+}
+
+func (m *Mixer) lookup(id ClipID) (ClipMeta, error) {
+	meta, ok := m.manifest[id]
+	if !ok {
+		return ClipMeta{}, fmt.Errorf("%w: %s", ErrClipNotFound, id)
+	}
+	return meta, nil
+}
+
+// Music : The ID of the current background music, if any
+func (m *Mixer) Music() ClipID {
+	if m.music == nil {
+		return ""
+	}
+	return m.music.meta.ID
+}
+
+// PlayMusic : Loop a music track, crossfading from the current one over fadeTicks. Asking for the
+// track that's already playing does nothing. An empty ClipID fades to silence.
+func (m *Mixer) PlayMusic(id ClipID, fadeTicks int) error {
+	if id == m.Music() {
+		return nil
+	}
+	var next *track
+	if id != "" {
+		meta, err := m.lookup(id)
+		if err != nil {
+			return err
+		}
+		p, err := m.backend.NewPlayer(meta, true)
+		if err != nil {
+			return err
+		}
+		next = &track{meta: meta, player: p}
+	}
+	if m.fadingOut != nil {
+		if err := m.fadingOut.player.Close(); err != nil {
+			return err
+		}
+		m.fadingOut = nil
+	}
+	m.fadingOut = m.music
+	m.music = next
+
+	step := 1.0
+	if fadeTicks > 0 {
+		step = 1 / float64(fadeTicks)
+	}
+	if m.fadingOut != nil {
+		m.fadingOut.step = -step
+	}
+	if m.music != nil {
+		m.music.step = step
+		m.music.player.SetVolume(0)
+		m.music.player.Play()
+	}
+	return m.Update()
+}
+
+// PlaySFX : Play a sound effect once. If every voice for the clip is busy, the oldest restarts.
+func (m *Mixer) PlaySFX(id ClipID) error {
+	meta, err := m.lookup(id)
+	if err != nil {
+		return err
+	}
+	voices := m.sfx[id]
+	for _, p := range voices {
+		if !p.IsPlaying() {
+			return m.restart(p, meta)
+		}
+	}
+	if len(voices) >= nSFXVoices {
+		// Rotate so the voice we steal is the most recent
+		p := voices[0]
+		m.sfx[id] = append(voices[1:], p)
+		return m.restart(p, meta)
+	}
+	p, err := m.backend.NewPlayer(meta, false)
+	if err != nil {
+		return err
+	}
+	m.sfx[id] = append(voices, p)
+	p.SetVolume(m.volume(meta))
+	p.Play()
+	return nil
+}
+
+func (m *Mixer) restart(p Player, meta ClipMeta) error {
+	if err := p.Rewind(); err != nil {
+		return err
+	}
+	p.SetVolume(m.volume(meta))
+	p.Play()
+	return nil
+}
+
+// Update : Hook for the engine's tick function. Steps crossfades.
+func (m *Mixer) Update() error {
+	if m.music != nil {
+		m.music.advance()
+		m.music.player.SetVolume(m.volume(m.music.meta) * m.music.fade)
+	}
+	if m.fadingOut != nil {
+		m.fadingOut.advance()
+		if m.fadingOut.fade <= 0 {
+			err := m.fadingOut.player.Close()
+			m.fadingOut = nil
+			return err
+		}
+		m.fadingOut.player.SetVolume(m.volume(m.fadingOut.meta) * m.fadingOut.fade)
+	}
+	return nil
+}
+
+func (t *track) advance() {
+	t.fade += t.step
+	if t.fade >= 1 {
+		t.fade = 1
+		t.step = 0
+	}
+	if t.fade < 0 {
+		t.fade = 0
+	}
+}
+
+// Close : Stop and release every player
+func (m *Mixer) Close() error {
+	var err error
+	for _, t := range []*track{m.music, m.fadingOut} {
+		if t != nil {
+			if cerr := t.player.Close(); cerr != nil {
+				err = cerr
+			}
+		}
+	}
+	for _, voices := range m.sfx {
+		for _, p := range voices {
+			if cerr := p.Close(); cerr != nil {
+				err = cerr
+			}
+		}
+	}
+	m.music, m.fadingOut, m.sfx = nil, nil, map[ClipID][]Player{}
+	return err
+}
@@ -0,0 +1,85 @@
+package audio_test
+
+import (
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jessdwitch/spiders/engine/audio"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const sampleManifest = `id,bus,path,volume
+theme,music,content/audio/theme.ogg,1
+battle,music,content/audio/battle.ogg,0.5
+hit,sfx,content/audio/hit.wav,1`
+
+func newTestMixer(t *testing.T) (*audio.Mixer, *audio.NullBackend) {
+	m, err := audio.NewManifest(csv.NewReader(strings.NewReader(sampleManifest)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend := &audio.NullBackend{}
+	return audio.NewMixer(backend, m), backend
+}
+
+func TestNewManifest(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		m, err := audio.NewManifest(csv.NewReader(strings.NewReader(sampleManifest)))
+		assert.NoError(t, err)
+		assert.Len(t, m, 3)
+		assert.Equal(t, audio.BusSFX, m["hit"].Bus)
+		assert.Equal(t, 0.5, m["battle"].Volume)
+	})
+	t.Run("Unknown bus", func(t *testing.T) {
+		_, err := audio.NewManifest(csv.NewReader(strings.NewReader("id,bus,path,volume\nx,voice,x.wav,1")))
+		assert.Error(t, err)
+	})
+}
+
+func TestCrossfade(t *testing.T) {
+	m, backend := newTestMixer(t)
+	assert.NoError(t, m.PlayMusic("theme", 0))
+	assert.Equal(t, audio.ClipID("theme"), m.Music())
+	theme := backend.Players[0]
+	assert.True(t, theme.Playing)
+	assert.True(t, theme.Loop)
+	assert.Equal(t, 1.0, theme.Volume)
+
+	assert.NoError(t, m.PlayMusic("theme", 10))
+	assert.Len(t, backend.Players, 1, "replaying the current track shouldn't restart it")
+
+	assert.NoError(t, m.PlayMusic("battle", 4))
+	battle := backend.Players[1]
+	assert.InDelta(t, 0.75, theme.Volume, 1e-9)
+	assert.InDelta(t, 0.125, battle.Volume, 1e-9)
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, m.Update())
+	}
+	assert.True(t, theme.Closed)
+	assert.InDelta(t, 0.5, battle.Volume, 1e-9)
+}
+
+func TestPlaySFX(t *testing.T) {
+	m, backend := newTestMixer(t)
+	assert.True(t, errors.Is(m.PlaySFX("nope"), audio.ErrClipNotFound))
+	for i := 0; i < 6; i++ {
+		assert.NoError(t, m.PlaySFX("hit"))
+	}
+	assert.Len(t, backend.Players, 4, "voices should be pooled")
+	backend.Players[0].Pause()
+	assert.NoError(t, m.PlaySFX("hit"))
+	assert.Len(t, backend.Players, 4)
+	assert.True(t, backend.Players[0].Playing)
+}
+
+func TestSetVolumes(t *testing.T) {
+	m, backend := newTestMixer(t)
+	assert.NoError(t, m.PlayMusic("battle", 0))
+	assert.NoError(t, m.PlaySFX("hit"))
+	m.SetVolumes(0.5, 0.5, 0.2)
+	assert.InDelta(t, 0.125, backend.Players[0].Volume, 1e-9)
+	assert.InDelta(t, 0.1, backend.Players[1].Volume, 1e-9)
+}
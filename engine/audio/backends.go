@@ -0,0 +1,152 @@
+package audio
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/jessdwitch/spiders/engine/assets"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/hajimehoshi/ebiten/v2/audio/mp3"
+	"github.com/hajimehoshi/ebiten/v2/audio/vorbis"
+	"github.com/hajimehoshi/ebiten/v2/audio/wav"
+)
+
+type (
+	// EbitenBackend : Plays clips from files through ebiten's audio context
+	EbitenBackend struct {
+		context *audio.Context
+		// fsys : Where clip paths are found
+		fsys fs.FS
+		// cache : Holds decoded PCM for each clip, so replaying doesn't decode again
+		cache *assets.Manager
+	}
+	// ebitenPlayer : Releases its PCM when closed
+	ebitenPlayer struct {
+		*audio.Player
+		release func()
+	}
+	// NullBackend : Plays nothing, but keeps track of what would be playing. For tests and
+	//	headless runs.
+	NullBackend struct {
+		// Players : Every player made, in order
+		Players []*NullPlayer
+	}
+	// NullPlayer : A silent Player
+	NullPlayer struct {
+		Meta    ClipMeta
+		Loop    bool
+		Playing bool
+		Volume  float64
+		Closed  bool
+	}
+	pcmStream interface {
+		io.ReadSeeker
+		Length() int64
+	}
+)
+
+// NewEbitenBackend : Get a backend on ebiten's audio context, reading clips from fsys and caching
+// decoded clips in the given asset manager. There can only be one context per process, so this
+// reuses it if it's already been made.
+func NewEbitenBackend(fsys fs.FS, sampleRate int, cache *assets.Manager) *EbitenBackend {
+	ctx := audio.CurrentContext()
+	if ctx == nil {
+		ctx = audio.NewContext(sampleRate)
+	}
+	return &EbitenBackend{context: ctx, fsys: fsys, cache: cache}
+}
+
+func clipAssetID(id ClipID) assets.ID {
+	return assets.ID("audio/" + id)
+}
+
+// NewPlayer : Decode the clip (or pull it from cache) and get a player for it
+func (e *EbitenBackend) NewPlayer(meta ClipMeta, loop bool) (Player, error) {
+	id := clipAssetID(meta.ID)
+	pcm, err := e.cache.Acquire(id, func() (interface{}, error) {
+		return e.decode(meta)
+	})
+	if err != nil {
+		return nil, err
+	}
+	release := func() { e.cache.Release(id) }
+	if !loop {
+		return &ebitenPlayer{audio.NewPlayerFromBytes(e.context, pcm.([]byte)), release}, nil
+	}
+	src := bytes.NewReader(pcm.([]byte))
+	p, err := audio.NewPlayer(e.context, audio.NewInfiniteLoop(src, src.Size()))
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return &ebitenPlayer{p, release}, nil
+}
+
+// PreloadClips : Decode clips in the background. See assets.Manager.Preload
+func (e *EbitenBackend) PreloadClips(metas []ClipMeta, progress assets.Progress) <-chan error {
+	reqs := make([]assets.Request, len(metas))
+	for i, meta := range metas {
+		meta := meta
+		reqs[i] = assets.Request{
+			ID:   clipAssetID(meta.ID),
+			Load: func() (interface{}, error) { return e.decode(meta) },
+		}
+	}
+	return e.cache.Preload(reqs, progress)
+}
+
+func (e *EbitenBackend) decode(meta ClipMeta) ([]byte, error) {
+	raw, err := fs.ReadFile(e.fsys, meta.Path)
+	if err != nil {
+		return nil, err
+	}
+	var s pcmStream
+	src := bytes.NewReader(raw)
+	switch filepath.Ext(meta.Path) {
+	case ".wav":
+		s, err = wav.Decode(e.context, src)
+	case ".ogg":
+		s, err = vorbis.Decode(e.context, src)
+	case ".mp3":
+		s, err = mp3.Decode(e.context, src)
+	default:
+		return nil, fmt.Errorf("clip %s: unsupported audio format %s", meta.ID, meta.Path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("clip %s: %w", meta.ID, err)
+	}
+	return ioutil.ReadAll(s)
+}
+
+// Close : Stop playing and let go of the decoded clip
+func (p *ebitenPlayer) Close() error {
+	p.release()
+	return p.Player.Close()
+}
+
+// NewPlayer : Get a silent player
+func (n *NullBackend) NewPlayer(meta ClipMeta, loop bool) (Player, error) {
+	p := &NullPlayer{Meta: meta, Loop: loop, Volume: 1}
+	n.Players = append(n.Players, p)
+	return p, nil
+}
+
+func (n *NullPlayer) Play()  { n.Playing = true }
+func (n *NullPlayer) Pause() { n.Playing = false }
+
+func (n *NullPlayer) Rewind() error { return nil }
+
+func (n *NullPlayer) IsPlaying() bool { return n.Playing }
+
+func (n *NullPlayer) SetVolume(v float64) { n.Volume = v }
+
+func (n *NullPlayer) Close() error {
+	n.Playing = false
+	n.Closed = true
+	return nil
+}
@@ -1,8 +1,199 @@
+// User settings, persisted between sessions
+
 package engine
 
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/jessdwitch/spiders/engine/render"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const configFileName = "settings.json"
+
 type (
+	// Config : User adjustable settings. Zero values aren't sensible; start from DefaultConfig
 	Config struct {
 		ScreenHeight int
 		ScreenWidth  int
+		// WindowScale : Window size as a multiple of the screen size
+		WindowScale int
+		// ScaleMode : How the screen is fitted to the window when they don't match
+		ScaleMode  ScaleMode
+		Fullscreen bool
+		Vsync      bool
+		// Vignette, CRTFilter : Screen effects
+		Vignette  bool
+		CRTFilter bool
+		// MasterVolume, MusicVolume, SFXVolume : Volume buses, from 0 (mute) to 1
+		MasterVolume float64
+		MusicVolume  float64
+		SFXVolume    float64
+		// TextSpeed : Kept for dialogue, which nothing shows yet
+		TextSpeed TextSpeed
+		// Language : Which file in content/text to pull copy from
+		Language string
+		// KeyBindings : Key names bound to each AbstractButton name
+		KeyBindings map[string][]string
 	}
+	// TextSpeed : How quickly dialogue is revealed
+	TextSpeed int
+)
+
+const (
+	TextSlow TextSpeed = iota
+	TextNormal
+	TextFast
+	TextInstant
 )
+
+var textSpeedNames = map[TextSpeed]string{
+	TextSlow:    "Slow",
+	TextNormal:  "Normal",
+	TextFast:    "Fast",
+	TextInstant: "Instant",
+}
+
+func (t TextSpeed) String() string {
+	if name, ok := textSpeedNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("TextSpeed(%d)", int(t))
+}
+
+// DefaultConfig : Settings for a fresh install
+func DefaultConfig() Config {
+	return Config{
+		ScreenHeight: 480,
+		ScreenWidth:  640,
+		WindowScale:  1,
+		ScaleMode:    ScaleInteger,
+		Vsync:        true,
+		MasterVolume: 1,
+		MusicVolume:  0.8,
+		SFXVolume:    0.8,
+		TextSpeed:    TextNormal,
+		Language:     "eng",
+		KeyBindings:  defaultKeyBindings(),
+	}
+}
+
+// ConfigPath : The location of the user settings file
+func ConfigPath() (string, error) {
+	dir, err := userDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, configFileName), nil
+}
+
+// LoadConfig : Read the user settings file. If there isn't one yet, get the defaults
+func LoadConfig() (Config, error) {
+	c := DefaultConfig()
+	fp, err := ConfigPath()
+	if err != nil {
+		return c, err
+	}
+	f, err := os.Open(fp)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return c, err
+	}
+	defer f.Close()
+	// Decoding over the defaults keeps settings added since the file was written
+	if err = json.NewDecoder(f).Decode(&c); err != nil {
+		return DefaultConfig(), fmt.Errorf("settings file %s is malformed: %w", fp, err)
+	}
+	return c, c.Validate()
+}
+
+// Save : Write the settings to the user settings file
+func (c Config) Save() error {
+	fp, err := ConfigPath()
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(fp)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "\t")
+	return enc.Encode(c)
+}
+
+// Validate : Check the settings are usable
+func (c Config) Validate() error {
+	if c.ScreenWidth <= 0 || c.ScreenHeight <= 0 {
+		return fmt.Errorf("screen size %dx%d must be positive", c.ScreenWidth, c.ScreenHeight)
+	}
+	if c.WindowScale <= 0 {
+		return fmt.Errorf("window scale %d must be positive", c.WindowScale)
+	}
+	if _, ok := scaleModeNames[c.ScaleMode]; !ok {
+		return fmt.Errorf("unknown scale mode %d", c.ScaleMode)
+	}
+	if _, ok := textSpeedNames[c.TextSpeed]; !ok {
+		return fmt.Errorf("unknown text speed %d", c.TextSpeed)
+	}
+	for _, v := range []float64{c.MasterVolume, c.MusicVolume, c.SFXVolume} {
+		if math.IsNaN(v) || v < 0 || v > 1 {
+			return fmt.Errorf("volume %v must be between 0 and 1", v)
+		}
+	}
+	for button, keys := range c.KeyBindings {
+		if _, err := ParseAbstractButton(button); err != nil {
+			return err
+		}
+		for _, k := range keys {
+			if _, err := ParseKey(k); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ApplyConfig : Push the current settings out to the window, scenes, audio, input and text
+func (g *GameState) ApplyConfig() error {
+	c := g.Config
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	if g.Content != nil && (g.Text == nil || g.Text.Language != c.Language) {
+		text, err := LoadText(g.Content, c.Language)
+		if err != nil {
+			return err
+		}
+		g.Text = text
+	}
+	if g.SceneManager != nil {
+		g.SceneManager.SetSize(c.ScreenWidth, c.ScreenHeight)
+	}
+	ebiten.SetWindowSize(c.ScreenWidth*c.WindowScale, c.ScreenHeight*c.WindowScale)
+	ebiten.SetWindowResizable(true)
+	ebiten.SetFullscreen(c.Fullscreen)
+	ebiten.SetVsyncEnabled(c.Vsync)
+	if g.PostProcess != nil {
+		for id, on := range map[render.ShaderID]bool{
+			render.ShaderVignette: c.Vignette,
+			render.ShaderCRT:      c.CRTFilter,
+		} {
+			if e := g.PostProcess.Get(id); e != nil {
+				e.SetEnabled(on)
+			}
+		}
+	}
+	if g.Audio != nil {
+		g.Audio.SetVolumes(c.MasterVolume, c.MusicVolume, c.SFXVolume)
+	}
+	return g.Input.SetKeyBindings(c.KeyBindings)
+}
@@ -0,0 +1,155 @@
+// Developer console, for poking at the game while it runs
+
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jessdwitch/spiders/deck"
+)
+
+// consoleHistory : Lines of output the console keeps
+const consoleHistory = 8
+
+type (
+	// Command : A console command. args are the words typed after its name. The result is shown
+	//	in the console.
+	Command func(state *GameState, args []string) (string, error)
+	// Commander : A scene with console commands of its own, as a battle's "heal". Scene commands
+	//	are only available while it's the current scene, and take precedence over the console's.
+	Commander interface {
+		Commands() map[string]Command
+	}
+	// Console : Runs typed commands against the game state. Comes with help, seed, and goto;
+	//	register more with Register, or have scenes provide them as Commanders.
+	Console struct {
+		commands map[string]Command
+		// scenes : what goto can go to, by name
+		scenes map[string]func(state *GameState) (Scene, error)
+		// output : the last consoleHistory lines, oldest first
+		output []string
+		// history : lines run, oldest first, to recall with up
+		history []string
+	}
+)
+
+// NewConsole : A console with the built-in commands
+func NewConsole() *Console {
+	c := &Console{
+		commands: map[string]Command{},
+		scenes:   map[string]func(state *GameState) (Scene, error){},
+	}
+	c.Register("help", c.help)
+	c.Register("seed", seedCommand)
+	c.Register("goto", c.gotoCommand)
+	return c
+}
+
+// Register : Add a command, replacing any of the same name
+func (c *Console) Register(name string, cmd Command) {
+	c.commands[name] = cmd
+}
+
+// AddScene : Let goto go to a scene, built fresh each time
+func (c *Console) AddScene(name string, build func(state *GameState) (Scene, error)) {
+	c.scenes[name] = build
+}
+
+// Run : Run a line, as typed into the console. The line and its result are added to the output.
+func (c *Console) Run(state *GameState, line string) (string, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return "", nil
+	}
+	c.history = append(c.history, line)
+	c.print("> " + line)
+	result, err := c.run(state, fields[0], fields[1:])
+	if err != nil {
+		c.print("error: " + err.Error())
+		return "", err
+	}
+	if result != "" {
+		c.print(result)
+	}
+	return result, nil
+}
+
+func (c *Console) run(state *GameState, name string, args []string) (string, error) {
+	if commander, ok := state.SceneManager.Current().(Commander); ok {
+		if cmd, ok := commander.Commands()[name]; ok {
+			return cmd(state, args)
+		}
+	}
+	if cmd, ok := c.commands[name]; ok {
+		return cmd(state, args)
+	}
+	return "", fmt.Errorf("unknown command %q; try help", name)
+}
+
+// Output : Recent output, oldest first
+func (c *Console) Output() []string {
+	return c.output
+}
+
+func (c *Console) print(s string) {
+	c.output = append(c.output, strings.Split(s, "\n")...)
+	if over := len(c.output) - consoleHistory; over > 0 {
+		c.output = c.output[over:]
+	}
+}
+
+// help : List the commands available right now
+func (c *Console) help(state *GameState, _ []string) (string, error) {
+	names := []string{}
+	for name := range c.commands {
+		names = append(names, name)
+	}
+	if commander, ok := state.SceneManager.Current().(Commander); ok {
+		for name := range commander.Commands() {
+			if _, ok := c.commands[name]; !ok {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, " "), nil
+}
+
+// gotoCommand : goto <scene>
+func (c *Console) gotoCommand(state *GameState, args []string) (string, error) {
+	if len(args) != 1 {
+		names := []string{}
+		for name := range c.scenes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return "", fmt.Errorf("usage: goto <%s>", strings.Join(names, "|"))
+	}
+	build, ok := c.scenes[args[0]]
+	if !ok {
+		return "", fmt.Errorf("no scene %q", args[0])
+	}
+	scene, err := build(state)
+	if err != nil {
+		return "", err
+	}
+	state.SceneManager.GoTo(scene)
+	return "", nil
+}
+
+// seedCommand : seed <n>. Reseed deck shuffles, as for replaying a battle. Other randomness, as in
+// camera shake, doesn't affect them.
+func seedCommand(_ *GameState, args []string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("usage: seed <n>")
+	}
+	seed, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("seed %q should be a number", args[0])
+	}
+	deck.Seed(seed)
+	return fmt.Sprintf("seeded %d", seed), nil
+}
@@ -3,23 +3,45 @@
 package engine
 
 import (
+	"github.com/jessdwitch/spiders/deck"
+	"github.com/jessdwitch/spiders/engine/assets"
+	"github.com/jessdwitch/spiders/engine/audio"
 	"github.com/jessdwitch/spiders/engine/render"
 
+	"errors"
+	"fmt"
+	"image"
+	"io/fs"
 	"math/rand"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
+// Menu sounds, shared by every menu
+const (
+	SFXMove    audio.ClipID = "ui_move"
+	SFXConfirm audio.ClipID = "ui_confirm"
+	SFXCancel  audio.ClipID = "ui_cancel"
+)
+
+// ErrQuit : Returned from an Update hook to end the game loop normally
+var ErrQuit = errors.New("quit")
+
 func init() {
 	rand.Seed(time.Now().UnixNano())
 }
 
 type (
 	Game struct {
-		Events       *eventBus
 		GameState    *GameState
 		SpriteGetter render.SpriteGetter
+		// Overlays : Updated after the scene, and drawn over it. For development tools.
+		Overlays []render.Drawer
+		// canvas : the logical screen, which is scaled up to the window
+		canvas *ebiten.Image
+		// scene : the scene playing as of the last update, to publish when it changes
+		scene Scene
 	}
 
 	GameState struct {
@@ -27,34 +49,134 @@ type (
 		SceneManager *SceneManager
 		Input        *Input
 		PlayerParty  PlayerParty
+		// Audio : Music and sound effects. May be nil if the game is running without sound
+		Audio *audio.Mixer
+		// Assets : Decoded assets shared between scenes
+		Assets *assets.Manager
+		// Content : The filesystem content manifests and files are read from
+		Content fs.FS
+		// Text : Copy in the configured language. May be nil; Text.Get falls back.
+		Text *Text
+		// Cards : Static data for every card
+		Cards deck.CardTable
+		// Collection : Every card the player owns
+		Collection deck.Collection
+		// DeckList : The deck the player takes into battle, built from the collection
+		DeckList deck.DeckList
+		// Viewport : Where the logical screen sits in the window. Kept up to date by the game.
+		Viewport *Viewport
+		// Shaders : Compiled shaders for effects. May be nil if they couldn't be compiled.
+		Shaders render.ShaderLibrary
+		// PostProcess : Effects over the whole screen, toggled by Config. May be nil.
+		PostProcess *render.PostProcess
+		// Particles : Makes particle emitters, as for hits. May be nil.
+		Particles *render.ParticleFactory
+		// Events : Pub-sub between game systems, as in scenes telling tools what happened
+		Events *eventBus
 	}
 )
 
 // NewGame : Generate a new Game object.
 func NewGame(initScene Scene) (*Game, error) {
 	return &Game{
-		Events: &eventBus{},
 		// TODO: Initial scene
 		GameState: NewGameState(initScene),
 	}, nil
 }
 
 func NewGameState(initScene Scene) *GameState {
+	viewport := &Viewport{}
+	input := NewInput()
+	input.viewport = viewport
+	config := DefaultConfig()
+	scenes := NewSceneManager(initScene)
+	scenes.SetSize(config.ScreenWidth, config.ScreenHeight)
 	return &GameState{
-		Config:       Config{ScreenHeight: 480, ScreenWidth: 640},
-		SceneManager: NewSceneManager(initScene),
-		Input:        NewInput(),
+		Config:       config,
+		SceneManager: scenes,
+		Input:        input,
+		Assets:       assets.NewManager(),
+		Viewport:     viewport,
+		Collection:   deck.StarterCollection(),
+		DeckList:     deck.StarterDeckList(),
+		Events:       &eventBus{},
 	}
 }
 
 func (g *Game) Update() error {
-	return g.GameState.SceneManager.Update(g.GameState)
+	if g.GameState.Audio != nil {
+		if err := g.GameState.Audio.Update(); err != nil {
+			return err
+		}
+	}
+	if err := g.GameState.SceneManager.Update(g.GameState); err != nil {
+		return err
+	}
+	if current := g.GameState.SceneManager.Current(); current != g.scene {
+		g.scene = current
+		if err := g.GameState.Publish(TopicScene, fmt.Sprintf("%T", current)); err != nil {
+			return err
+		}
+	}
+	for _, o := range g.Overlays {
+		if err := o.Update(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PlaySFX : Play a sound effect, if there's audio and the clip is registered. Sounds are optional
+// content, so a missing clip isn't an error.
+func (g *GameState) PlaySFX(id audio.ClipID) error {
+	if g.Audio == nil {
+		return nil
+	}
+	if err := g.Audio.PlaySFX(id); err != nil && !errors.Is(err, audio.ErrClipNotFound) {
+		return err
+	}
+	return nil
+}
+
+// Publish : Publish an event on the bus, if there is one
+func (g *GameState) Publish(topic Topic, payload interface{}) error {
+	if g.Events == nil {
+		return nil
+	}
+	return g.Events.Publish(topic, payload)
 }
 
+// Draw : Draw the scene at the logical screen size, apply screen effects and overlays, then scale
+// it all to the window
 func (g *Game) Draw(screen *ebiten.Image) {
-	g.GameState.SceneManager.Draw(screen)
+	v := g.GameState.Viewport
+	if g.canvas == nil || g.canvas.Bounds().Size() != v.Logical {
+		if g.canvas != nil {
+			g.canvas.Dispose()
+		}
+		g.canvas = ebiten.NewImage(v.Logical.X, v.Logical.Y)
+	}
+	g.canvas.Clear()
+	g.GameState.SceneManager.Draw(g.canvas)
+	out := g.canvas
+	if g.GameState.PostProcess != nil {
+		out = g.GameState.PostProcess.Apply(g.canvas)
+	}
+	// Development tools stay clear of screen effects
+	for _, o := range g.Overlays {
+		o.Draw(out)
+	}
+	screen.DrawImage(out, &ebiten.DrawImageOptions{GeoM: v.GeoM(), Filter: v.Filter()})
 }
 
+// Layout : The window is drawn at its own size, so the logical screen can be letterboxed in it
+// however Config says
 func (g *Game) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
+	c := g.GameState.Config
+	*g.GameState.Viewport = Viewport{
+		Logical: image.Pt(c.ScreenWidth, c.ScreenHeight),
+		Outside: image.Pt(outsideWidth, outsideHeight),
+		Mode:    c.ScaleMode,
+	}
 	return outsideWidth, outsideHeight
 }
@@ -0,0 +1,15 @@
+package engine_test
+
+import (
+	"testing"
+
+	"github.com/jessdwitch/spiders/deck"
+	"github.com/jessdwitch/spiders/engine"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewGameStateDeck(t *testing.T) {
+	state := engine.NewGameState(nil)
+	assert.NoError(t, state.DeckList.Validate(state.Collection, deck.DefaultRules))
+}
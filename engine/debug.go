@@ -0,0 +1,156 @@
+// Debug overlay: what the game is doing, and a console to change it
+
+package engine
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font/basicfont"
+)
+
+const (
+	// debugInfoKey, debugConsoleKey : Toggle the info panel and the console
+	debugInfoKey    = ebiten.KeyF3
+	debugConsoleKey = ebiten.KeyGraveAccent
+	debugMargin     = 8
+	debugLine       = 16
+)
+
+var (
+	debugBack color.Color = color.RGBA{0, 0, 0x30, 0xd0}
+	debugText color.Color = color.White
+)
+
+type (
+	// Debugger : A scene that can describe its state for the debug overlay
+	Debugger interface {
+		DebugInfo() []string
+	}
+	// DebugOverlay : Shows frame rates, the current scene, and event traffic, along with anything
+	//	the scene adds as a Debugger. Also hosts the console, which takes the keyboard while it's
+	//	open. Add it to the game's Overlays.
+	DebugOverlay struct {
+		game        *Game
+		console     *Console
+		showInfo    bool
+		showConsole bool
+		// line : what's been typed into the console so far
+		line []rune
+		// recall : how far back up the console history we are, 0 for the line being typed
+		recall int
+		back   *ebiten.Image
+	}
+)
+
+// NewDebugOverlay : An overlay for g, hidden until toggled on
+func NewDebugOverlay(g *Game, console *Console) *DebugOverlay {
+	back := ebiten.NewImage(1, 1)
+	back.Fill(debugBack)
+	return &DebugOverlay{game: g, console: console, back: back}
+}
+
+// Update : Hook for the engine's tick function. Handles the toggles, and typing in the console.
+func (d *DebugOverlay) Update() error {
+	if inpututil.IsKeyJustPressed(debugInfoKey) {
+		d.showInfo = !d.showInfo
+	}
+	if inpututil.IsKeyJustPressed(debugConsoleKey) {
+		d.setConsole(!d.showConsole)
+		return nil
+	}
+	if !d.showConsole {
+		return nil
+	}
+	for _, r := range ebiten.InputChars() {
+		if r != '`' {
+			d.line = append(d.line, r)
+		}
+	}
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		d.setConsole(false)
+	case inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && len(d.line) > 0:
+		d.line = d.line[:len(d.line)-1]
+	case inpututil.IsKeyJustPressed(ebiten.KeyUp) && d.recall < len(d.console.history):
+		d.recall++
+		d.line = []rune(d.console.history[len(d.console.history)-d.recall])
+	case inpututil.IsKeyJustPressed(ebiten.KeyDown) && d.recall > 0:
+		d.recall--
+		d.line = d.line[:0]
+		if d.recall > 0 {
+			d.line = []rune(d.console.history[len(d.console.history)-d.recall])
+		}
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		// Errors are shown in the console; the game carries on
+		_, _ = d.console.Run(d.game.GameState, string(d.line))
+		d.line = d.line[:0]
+		d.recall = 0
+	}
+	return nil
+}
+
+func (d *DebugOverlay) setConsole(open bool) {
+	d.showConsole = open
+	d.game.GameState.Input.SetCaptured(open)
+}
+
+// Info : The lines the info panel shows
+func (d *DebugOverlay) Info() []string {
+	scene := d.game.GameState.SceneManager.Current()
+	lines := []string{
+		fmt.Sprintf("fps %.1f  tps %.1f", ebiten.CurrentFPS(), ebiten.CurrentTPS()),
+		fmt.Sprintf("scene %T", scene),
+	}
+	if debugger, ok := scene.(Debugger); ok {
+		lines = append(lines, debugger.DebugInfo()...)
+	}
+	traffic := map[Topic]int{}
+	if d.game.GameState.Events != nil {
+		traffic = d.game.GameState.Events.Traffic()
+	}
+	topics := make([]string, 0, len(traffic))
+	for t, n := range traffic {
+		topics = append(topics, fmt.Sprintf("%s %d", t, n))
+	}
+	sort.Strings(topics)
+	if len(topics) == 0 {
+		topics = append(topics, "none")
+	}
+	return append(lines, "events: "+strings.Join(topics, ", "))
+}
+
+// Draw : Show the info panel in the top right, and the console along the bottom
+func (d *DebugOverlay) Draw(screen *ebiten.Image) {
+	width, height := screen.Size()
+	if d.showInfo {
+		lines := d.Info()
+		longest := 0
+		for _, l := range lines {
+			if len(l) > longest {
+				longest = len(l)
+			}
+		}
+		panelW := longest*basicfont.Face7x13.Advance + 2*debugMargin
+		d.drawPanel(screen, lines, width-panelW, 0, panelW)
+	}
+	if d.showConsole {
+		lines := append(append([]string{}, d.console.Output()...), "> "+string(d.line)+"_")
+		d.drawPanel(screen, lines, 0, height-len(lines)*debugLine-2*debugMargin, width)
+	}
+}
+
+func (d *DebugOverlay) drawPanel(screen *ebiten.Image, lines []string, x, y, w int) {
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(float64(w), float64(len(lines)*debugLine+2*debugMargin))
+	op.GeoM.Translate(float64(x), float64(y))
+	screen.DrawImage(d.back, op)
+	for i, l := range lines {
+		text.Draw(screen, l, basicfont.Face7x13, x+debugMargin, y+debugMargin+(i+1)*debugLine-4, debugText)
+	}
+}
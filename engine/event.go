@@ -3,13 +3,16 @@ package engine
 import (
 	"bytes"
 	"encoding/gob"
+	"sync"
 )
 
-
 type (
 	// eventBus : Pub-sub for communicating between game systems
 	eventBus struct {
 		subscribers map[Topic][]Subscriber
+		// published : events published on each topic, for the debug overlay
+		published map[Topic]int
+		mu        sync.Mutex
 	}
 
 	// Topic : The subject of the event being pub/sub'd
@@ -27,13 +30,20 @@ type (
 	}
 )
 
+// Topics the engine publishes on
+const (
+	// TopicScene : A scene has started. The payload is its type name, as in "*battle.BattleScene".
+	TopicScene Topic = "scene"
+)
+
 // Subscribe : Add a Subscriber to a Topic
 func (e *eventBus) Subscribe(topic Topic, sub Subscriber) error {
-	if subs, ok := e.subscribers[topic]; ok {
-		subs = append(subs, sub)
-		return nil
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.subscribers == nil {
+		e.subscribers = map[Topic][]Subscriber{}
 	}
-	e.subscribers[topic] = []Subscriber{sub}
+	e.subscribers[topic] = append(e.subscribers[topic], sub)
 	return nil
 }
 
@@ -47,20 +57,38 @@ func (e *eventBus) Publish(topic Topic, payload interface{}) error {
 	}
 	// TODO: Is there a risk of collision by re-using this event? At this scale, does it matter?
 	event := Event{
-		Topic: topic,
+		Topic:   topic,
 		Payload: b.Bytes(),
 	}
-	for _, s := range e.subscribers[topic] {
+	e.mu.Lock()
+	if e.published == nil {
+		e.published = map[Topic]int{}
+	}
+	e.published[topic]++
+	subs := e.subscribers[topic]
+	e.mu.Unlock()
+	for _, s := range subs {
 		// TODO: How are publishing errors handled? Do we accept a handler on sub?
-		go func(e Event) {
+		go func(s Subscriber, e Event) {
 			s.Call(e)
-		}(event)
+		}(s, event)
 	}
 	return nil
 }
 
+// Traffic : How many events have been published on each topic
+func (e *eventBus) Traffic() map[Topic]int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	result := make(map[Topic]int, len(e.published))
+	for t, n := range e.published {
+		result[t] = n
+	}
+	return result
+}
+
 // GetPayload : Deserialize event payload. Ideally, Event is extended to streamline typing
 func (e *Event) GetPayload(output interface{}) error {
 	dec := gob.NewDecoder(bytes.NewBuffer(e.Payload))
-	return dec.Decode(e)
+	return dec.Decode(output)
 }
@@ -0,0 +1,174 @@
+// Watch content for changes during development, and reload what changed
+
+package hotreload
+
+import (
+	"fmt"
+	"image/color"
+	"io/fs"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font/basicfont"
+)
+
+const (
+	overlayMargin = 8
+	overlayLine   = 16
+)
+
+var (
+	overlayBack color.Color = color.RGBA{0x40, 0, 0, 0xd0}
+	overlayText color.Color = color.White
+)
+
+type (
+	// Watcher : Polls a filesystem for modified files, and calls the reloaders registered for
+	//	them. Reload errors are shown in an on-screen overlay rather than ending the game.
+	Watcher struct {
+		fsys fs.FS
+		// interval : Ticks between polls
+		interval int
+		count    int
+		mtimes   map[string]time.Time
+		watches  []watch
+		// errs : The last error from each failing reloader, cleared when it next succeeds
+		errs    map[int]error
+		scanErr error
+		errImg  *ebiten.Image
+	}
+	// Reloader : Reload whatever depends on the changed files
+	Reloader func() error
+	watch    struct {
+		// pattern : path.Match pattern for the files this cares about
+		pattern string
+		reload  Reloader
+	}
+)
+
+// NewWatcher : Watch fsys, polling every interval ticks
+func NewWatcher(fsys fs.FS, interval int) (*Watcher, error) {
+	w := &Watcher{
+		fsys:     fsys,
+		interval: interval,
+		errs:     map[int]error{},
+	}
+	var err error
+	w.mtimes, err = w.scan()
+	return w, err
+}
+
+// Watch : Call reload whenever a file matching pattern changes. Patterns are as in path.Match.
+func (w *Watcher) Watch(pattern string, reload Reloader) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return err
+	}
+	w.watches = append(w.watches, watch{pattern, reload})
+	return nil
+}
+
+func (w *Watcher) scan() (map[string]time.Time, error) {
+	result := map[string]time.Time{}
+	err := fs.WalkDir(w.fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		result[p] = info.ModTime()
+		return nil
+	})
+	return result, err
+}
+
+// Update : Hook for the engine's tick function. Polls for changes every interval.
+func (w *Watcher) Update() error {
+	w.count++
+	if w.count < w.interval {
+		return nil
+	}
+	w.count = 0
+	mtimes, err := w.scan()
+	// A file may vanish mid-scan while it's being saved; show it and try again next poll
+	if w.scanErr = err; err != nil {
+		return nil
+	}
+	changed := []string{}
+	for p, t := range mtimes {
+		if prev, ok := w.mtimes[p]; !ok || !prev.Equal(t) {
+			changed = append(changed, p)
+		}
+	}
+	for p := range w.mtimes {
+		if _, ok := mtimes[p]; !ok {
+			changed = append(changed, p)
+		}
+	}
+	w.mtimes = mtimes
+	for i, wt := range w.watches {
+		if !wt.matches(changed) {
+			continue
+		}
+		if err := wt.reload(); err != nil {
+			w.errs[i] = fmt.Errorf("reloading %s: %w", wt.pattern, err)
+		} else {
+			delete(w.errs, i)
+		}
+	}
+	return nil
+}
+
+func (wt watch) matches(changed []string) bool {
+	for _, p := range changed {
+		// Already validated in Watch
+		if ok, _ := path.Match(wt.pattern, p); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Errors : Reload errors that haven't been fixed yet
+func (w *Watcher) Errors() []error {
+	result := []error{}
+	if w.scanErr != nil {
+		result = append(result, w.scanErr)
+	}
+	for i := range w.watches {
+		if err, ok := w.errs[i]; ok {
+			result = append(result, err)
+		}
+	}
+	return result
+}
+
+// Draw : Show outstanding reload errors over the top of the screen
+func (w *Watcher) Draw(screen *ebiten.Image) {
+	errs := w.Errors()
+	if len(errs) == 0 {
+		return
+	}
+	if w.errImg == nil {
+		w.errImg = ebiten.NewImage(1, 1)
+		w.errImg.Fill(overlayBack)
+	}
+	width, _ := screen.Size()
+	lines := []string{"Content reload failed:"}
+	for _, err := range errs {
+		lines = append(lines, strings.Split(err.Error(), "\n")...)
+	}
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(float64(width), float64(len(lines)*overlayLine+2*overlayMargin))
+	screen.DrawImage(w.errImg, op)
+	for i, l := range lines {
+		text.Draw(screen, l, basicfont.Face7x13, overlayMargin, overlayMargin+(i+1)*overlayLine-4, overlayText)
+	}
+}
@@ -2,10 +2,210 @@
 
 package engine
 
-type Input struct {
+import (
+	"fmt"
 
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+type (
+	// Input : Maps physical keys and gamepad buttons onto abstract buttons, so scenes don't need
+	//	to care which device the player is using
+	Input struct {
+		keys           map[AbstractButton][]ebiten.Key
+		gamepadButtons map[AbstractButton][]ebiten.GamepadButton
+		// viewport : maps the cursor from the window to the logical screen, if set
+		viewport *Viewport
+		// captured : something else, like the console, has the keyboard, so scenes see nothing
+		captured bool
+	}
+	// AbstractButton : A device-independent input, like "confirm" or "move up"
+	AbstractButton int
+)
+
+const (
+	ButtonUp AbstractButton = iota
+	ButtonDown
+	ButtonLeft
+	ButtonRight
+	ButtonConfirm
+	ButtonCancel
+	ButtonMenu
+)
+
+// AbstractButtons : Every AbstractButton, in display order
+var AbstractButtons = []AbstractButton{
+	ButtonUp, ButtonDown, ButtonLeft, ButtonRight, ButtonConfirm, ButtonCancel, ButtonMenu,
+}
+
+var abstractButtonNames = map[AbstractButton]string{
+	ButtonUp:      "up",
+	ButtonDown:    "down",
+	ButtonLeft:    "left",
+	ButtonRight:   "right",
+	ButtonConfirm: "confirm",
+	ButtonCancel:  "cancel",
+	ButtonMenu:    "menu",
+}
+
+func defaultKeyBindings() map[string][]string {
+	return map[string][]string{
+		"up":      {"Up", "W"},
+		"down":    {"Down", "S"},
+		"left":    {"Left", "A"},
+		"right":   {"Right", "D"},
+		"confirm": {"Enter", "Space", "Z"},
+		"cancel":  {"Backspace", "X"},
+		"menu":    {"Escape"},
+	}
 }
 
+// NewInput : Get an Input with the default bindings
 func NewInput() *Input {
-	return &Input{}
-}
\ No newline at end of file
+	i := &Input{
+		gamepadButtons: map[AbstractButton][]ebiten.GamepadButton{
+			ButtonUp:      {ebiten.GamepadButton12},
+			ButtonDown:    {ebiten.GamepadButton13},
+			ButtonLeft:    {ebiten.GamepadButton14},
+			ButtonRight:   {ebiten.GamepadButton15},
+			ButtonConfirm: {ebiten.GamepadButton0},
+			ButtonCancel:  {ebiten.GamepadButton1},
+			ButtonMenu:    {ebiten.GamepadButton9},
+		},
+	}
+	// The defaults are known good
+	_ = i.SetKeyBindings(defaultKeyBindings())
+	return i
+}
+
+func (b AbstractButton) String() string {
+	return abstractButtonNames[b]
+}
+
+// ParseAbstractButton : Get an AbstractButton from its name
+func ParseAbstractButton(name string) (AbstractButton, error) {
+	for b, n := range abstractButtonNames {
+		if n == name {
+			return b, nil
+		}
+	}
+	return 0, fmt.Errorf("%s is not a button", name)
+}
+
+// ParseKey : Get an ebiten.Key from its name, as given by Key.String
+func ParseKey(name string) (ebiten.Key, error) {
+	for k := ebiten.Key(0); k <= ebiten.KeyMax; k++ {
+		if k.String() == name {
+			return k, nil
+		}
+	}
+	return 0, fmt.Errorf("%s is not a key", name)
+}
+
+// SetKeyBindings : Replace the keyboard bindings of each named button. Buttons not named keep
+// their current bindings.
+func (i *Input) SetKeyBindings(bindings map[string][]string) error {
+	keys := make(map[AbstractButton][]ebiten.Key, len(i.keys))
+	for b, k := range i.keys {
+		keys[b] = k
+	}
+	for name, keyNames := range bindings {
+		b, err := ParseAbstractButton(name)
+		if err != nil {
+			return err
+		}
+		bound := make([]ebiten.Key, len(keyNames))
+		for j, keyName := range keyNames {
+			if bound[j], err = ParseKey(keyName); err != nil {
+				return err
+			}
+		}
+		keys[b] = bound
+	}
+	i.keys = keys
+	return nil
+}
+
+// SetCaptured : Hide input from scenes while something else, like the console, takes it
+func (i *Input) SetCaptured(captured bool) {
+	i.captured = captured
+}
+
+// Captured : Is input hidden from scenes?
+func (i *Input) Captured() bool {
+	return i.captured
+}
+
+// JustPressedKey : Get a key pressed this tick, if any. Used for rebinding.
+func (i *Input) JustPressedKey() (ebiten.Key, bool) {
+	if i.captured {
+		return 0, false
+	}
+	for k := ebiten.Key(0); k <= ebiten.KeyMax; k++ {
+		if inpututil.IsKeyJustPressed(k) {
+			return k, true
+		}
+	}
+	return 0, false
+}
+
+// IsPressed : Is the abstract button currently held on any device?
+func (i *Input) IsPressed(b AbstractButton) bool {
+	if i.captured {
+		return false
+	}
+	for _, k := range i.keys[b] {
+		if ebiten.IsKeyPressed(k) {
+			return true
+		}
+	}
+	for _, id := range ebiten.GamepadIDs() {
+		for _, gb := range i.gamepadButtons[b] {
+			if ebiten.IsGamepadButtonPressed(id, gb) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsJustPressed : Was the abstract button pressed this tick on any device?
+func (i *Input) IsJustPressed(b AbstractButton) bool {
+	if i.captured {
+		return false
+	}
+	for _, k := range i.keys[b] {
+		if inpututil.IsKeyJustPressed(k) {
+			return true
+		}
+	}
+	for _, id := range ebiten.GamepadIDs() {
+		for _, gb := range i.gamepadButtons[b] {
+			if inpututil.IsGamepadButtonJustPressed(id, gb) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AnyJustPressed : Was any bound button pressed this tick?
+func (i *Input) AnyJustPressed() bool {
+	for b := range i.keys {
+		if i.IsJustPressed(b) {
+			return true
+		}
+	}
+	return false
+}
+
+// CursorPosition : Where the mouse cursor is on the logical screen. ok is false if it's outside
+// it, as in over the letterbox.
+func (i *Input) CursorPosition() (x, y int, ok bool) {
+	x, y = ebiten.CursorPosition()
+	if i.viewport == nil {
+		return x, y, true
+	}
+	return i.viewport.ToLogical(x, y)
+}
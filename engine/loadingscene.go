@@ -0,0 +1,109 @@
+// Show progress while assets load in the background
+
+package engine
+
+import (
+	"image/color"
+	"sync/atomic"
+
+	"github.com/jessdwitch/spiders/engine/assets"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	loadingBarWidth  = 320
+	loadingBarHeight = 12
+)
+
+var (
+	loadingBarBack color.Color = color.Gray{Y: 0x30}
+	loadingBarFore color.Color = color.RGBA{0xf0, 0xb1, 0xb1, 0xff}
+)
+
+type (
+	// Preloader : Start loading in the background. See assets.Manager.Preload
+	Preloader func(progress assets.Progress) <-chan error
+	// LoadingScene : A progress bar shown while preloaders run. Goes to the next scene when
+	//	they're all finished.
+	LoadingScene struct {
+		next func(state *GameState) (Scene, error)
+		// done, total : Progress summed across preloaders. Written from loading goroutines.
+		done, total int64
+		results     []<-chan error
+		finished    int
+		bar         *ebiten.Image
+	}
+)
+
+// NewLoadingScene : Run each preloader, then build and go to the next scene
+func NewLoadingScene(next func(state *GameState) (Scene, error), preloaders ...Preloader) *LoadingScene {
+	s := &LoadingScene{
+		next: next,
+		bar:  ebiten.NewImage(1, 1),
+	}
+	s.bar.Fill(color.White)
+	totals := make([]int64, len(preloaders))
+	dones := make([]int64, len(preloaders))
+	for i, p := range preloaders {
+		i := i
+		s.results = append(s.results, p(func(done, total int) {
+			// Each preloader reports its own counts; fold them into the scene's sums
+			atomic.AddInt64(&s.total, int64(total)-atomic.SwapInt64(&totals[i], int64(total)))
+			atomic.AddInt64(&s.done, int64(done)-atomic.SwapInt64(&dones[i], int64(done)))
+		}))
+	}
+	return s
+}
+
+// Progress : How far along loading is, from 0 to 1
+func (s *LoadingScene) Progress() float64 {
+	total := atomic.LoadInt64(&s.total)
+	if total == 0 {
+		return 0
+	}
+	return float64(atomic.LoadInt64(&s.done)) / float64(total)
+}
+
+func (s *LoadingScene) Update(state *GameState) error {
+	for s.finished < len(s.results) {
+		select {
+		case err, ok := <-s.results[s.finished]:
+			if err != nil {
+				return err
+			}
+			if !ok {
+				s.finished++
+			}
+		default:
+			return nil
+		}
+	}
+	if s.next == nil {
+		return nil
+	}
+	next, err := s.next(state)
+	if err != nil {
+		return err
+	}
+	s.next = nil
+	state.SceneManager.GoTo(next)
+	return nil
+}
+
+func (s *LoadingScene) Draw(screen *ebiten.Image) {
+	w, h := screen.Size()
+	x := float64(w-loadingBarWidth) / 2
+	y := float64(h-loadingBarHeight) / 2
+	s.drawBar(screen, x, y, loadingBarWidth, loadingBarBack)
+	s.drawBar(screen, x, y, loadingBarWidth*s.Progress(), loadingBarFore)
+}
+
+func (s *LoadingScene) drawBar(screen *ebiten.Image, x, y, width float64, clr color.Color) {
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(width, loadingBarHeight)
+	op.GeoM.Translate(x, y)
+	r, g, b, a := clr.RGBA()
+	op.ColorM.Scale(float64(r)/0xffff, float64(g)/0xffff, float64(b)/0xffff, float64(a)/0xffff)
+	screen.DrawImage(s.bar, op)
+}
@@ -2,15 +2,23 @@ package render
 
 import (
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"image"
 	"io"
-	"os"
+	"io/fs"
 	"strconv"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
+var (
+	// ErrSheetNotFound : The getter has no sheet by that ID
+	ErrSheetNotFound = errors.New("sheet not found")
+	// ErrSpriteNotFound : The getter has no sprite by that ID
+	ErrSpriteNotFound = errors.New("sprite not found")
+)
+
 type (
 	// AnimationGetter : Assemble an animation from it's metadata
 	AnimationGetter interface {
@@ -29,10 +37,28 @@ type (
 		SheetDim    image.Point
 		TileDim     image.Point
 		NTiles      image.Point
+		// Frames : Explicit frames, as in a packed atlas. If set, these are used in place of the
+		//	TileDim grid.
+		Frames []SheetFrame
+	}
+	// SheetFrame : Where one frame sits in a sheet, and how to draw it
+	SheetFrame struct {
+		Rect image.Rectangle
+		// Origin : The pivot, relative to Rect.Min. It's drawn at the sprite's position.
+		Origin image.Point
+		// Duration : How long to hold this frame, in the same units as AnimMeta.FrameDelay. Zero
+		//	means use the animation's delay.
+		Duration int
+	}
+	// sheetLister : A SpriteSheetGetter that can list its sheets, so they can be dropped from a
+	//	cache on reload
+	sheetLister interface {
+		sheetIDs() []SourceImageID
 	}
 	// SpriteSheetFiles : A provider of sprite sheets from file sources
 	SpriteSheetFiles map[SourceImageID]sheetFileMeta
 	sheetFileMeta    struct {
+		fsys     fs.FS
 		fp       string
 		sheetDim image.Point
 		tileDim  image.Point
@@ -40,8 +66,9 @@ type (
 	}
 )
 
-// NewSpriteSheetFiles : Get a new AnimationGetter from a sprite sheet manifest
-func NewSpriteSheetFiles(manifest *csv.Reader) (SpriteSheetFiles, error) {
+// NewSpriteSheetFiles : Get a new AnimationGetter from a sprite sheet manifest. Sheet paths are
+// relative to fsys.
+func NewSpriteSheetFiles(fsys fs.FS, manifest *csv.Reader) (SpriteSheetFiles, error) {
 	result := SpriteSheetFiles(make(map[SourceImageID]sheetFileMeta))
 	// strip header
 	_, err := manifest.Read()
@@ -59,7 +86,7 @@ func NewSpriteSheetFiles(manifest *csv.Reader) (SpriteSheetFiles, error) {
 		if err != nil {
 			return nil, err
 		}
-		err = result.processManifestCsvRecord(record)
+		err = result.processManifestCsvRecord(fsys, record)
 		if err != nil {
 			return nil, err
 		}
@@ -67,10 +94,14 @@ func NewSpriteSheetFiles(manifest *csv.Reader) (SpriteSheetFiles, error) {
 	return result, nil
 }
 
-func (s SpriteSheetFiles) processManifestCsvRecord(record []string) error {
+func (s SpriteSheetFiles) processManifestCsvRecord(fsys fs.FS, record []string) error {
 	// record: name, path, tileX, tileY, sheetX, sheetY
 	var err error
+	if len(record) != 6 {
+		return fmt.Errorf("sheet manifest record %v has %d columns, want 6", record, len(record))
+	}
 	meta := sheetFileMeta{
+		fsys:    fsys,
 		fp:      record[1],
 		tileDim: image.Point{},
 	}
@@ -82,10 +113,13 @@ func (s SpriteSheetFiles) processManifestCsvRecord(record []string) error {
 	if err != nil {
 		return err
 	}
+	if meta.tileDim.X <= 0 || meta.tileDim.Y <= 0 {
+		return fmt.Errorf("sheet %s has non-positive tile size %v", record[0], meta.tileDim)
+	}
 
 	meta.sheetDim.X, err = strconv.Atoi(record[4])
 	if err != nil || meta.sheetDim.X == 0 {
-		f, err := os.Open(meta.fp)
+		f, err := fsys.Open(meta.fp)
 		if err != nil {
 			return err
 		}
@@ -117,48 +151,109 @@ func (s SpriteSheetFiles) GetSpriteSheet(id SourceImageID) (*SpriteSheet, error)
 	if meta, ok := s[id]; ok {
 		return meta.GetSpriteSheet(id)
 	}
-	return nil, fmt.Errorf("sheet %s not found", id)
+	return nil, fmt.Errorf("sheet %s: %w", id, ErrSheetNotFound)
 }
 
-// ExtractAnimation : Extract a series of frames from this Sprite Sheet
+// ExtractAnimation : Extract a series of frames from this Sprite Sheet. Per-frame durations come
+// from meta if it has them, then from the sheet.
 func (s *SpriteSheet) ExtractAnimation(meta AnimMeta) (Animation, error) {
+	indices := meta.Sequence
+	if indices == nil {
+		indices = make([]int, meta.NFrames)
+		for i := range indices {
+			indices[i] = meta.Start + i
+		}
+	}
+	if meta.Durations != nil && len(meta.Durations) != len(indices) {
+		return Animation{}, fmt.Errorf("animation %s on sheet %s has %d durations for %d frames",
+			meta.Mode, s.SheetID, len(meta.Durations), len(indices))
+	}
+	for i := range meta.Markers {
+		if i < 0 || i >= len(indices) {
+			return Animation{}, fmt.Errorf("animation %s on sheet %s marks frame %d of %d",
+				meta.Mode, s.SheetID, i, len(indices))
+		}
+	}
 	t := NewTile(nil)
 	result := Animation{
 		Tile:          &t,
 		Frames:        []*ebiten.Image{},
 		FrameDelayMax: meta.FrameDelay,
+		Playback:      meta.Playback,
+		Markers:       meta.Markers,
 	}
-	for i := 0; i < meta.NFrames; i++ {
-		r, err := s.iToRect(meta.Start + i)
+	durations := make([]int, len(indices))
+	origins := make([]Point, len(indices))
+	uniform, centered := true, true
+	for i, index := range indices {
+		f, err := s.frame(index)
 		if err != nil {
 			return Animation{}, err
 		}
-		frame := s.SourceImage.SubImage(r).(*ebiten.Image)
+		frame := s.SourceImage.SubImage(f.Rect).(*ebiten.Image)
 		result.Frames = append(result.Frames, frame)
+		switch {
+		case meta.Durations != nil:
+			durations[i] = meta.Durations[i]
+		case f.Duration > 0:
+			durations[i] = f.Duration
+		default:
+			durations[i] = meta.FrameDelay
+		}
+		uniform = uniform && durations[i] == meta.FrameDelay
+		origins[i] = Point{float64(f.Origin.X), float64(f.Origin.Y)}
+		centered = centered && f.Origin == image.Point{}
+	}
+	// Leave these nil when they'd change nothing, so SetDelay keeps working on plain grid sprites
+	if !uniform {
+		result.Durations = durations
+	}
+	if !centered {
+		result.Origins = origins
 	}
 	return result, nil
 }
 
-func (s *SpriteSheet) iToRect(i int) (image.Rectangle, error) {
+// frame : The i-th frame, from the explicit frame list if there is one, or else the grid, in
+// row-major order
+func (s *SpriteSheet) frame(i int) (SheetFrame, error) {
 	if i < 0 {
-		return image.Rectangle{}, fmt.Errorf("requested index %d is negative for sheet %s", i, s.SheetID)
+		return SheetFrame{}, fmt.Errorf("requested index %d is negative for sheet %s", i, s.SheetID)
+	}
+	if s.Frames != nil {
+		if i >= len(s.Frames) {
+			return SheetFrame{},
+				fmt.Errorf("frame index %d must be less than %d for sheet %s", i, len(s.Frames), s.SheetID)
+		}
+		return s.Frames[i], nil
 	}
 	if i >= s.NTiles.X*s.NTiles.Y {
-		return image.Rectangle{},
+		return SheetFrame{},
 			fmt.Errorf("tile index %d must be less than %d for sheet %s", i, s.NTiles.X*s.NTiles.Y, s.SheetID)
 	}
 	x := (i % s.NTiles.X) * s.TileDim.X
-	y := (i / s.NTiles.Y) * s.TileDim.Y
-	return image.Rect(x, y, x+s.TileDim.X, y+s.TileDim.Y), nil
+	y := (i / s.NTiles.X) * s.TileDim.Y
+	return SheetFrame{Rect: image.Rect(x, y, x+s.TileDim.X, y+s.TileDim.Y)}, nil
+}
+
+func (s SpriteSheetFiles) sheetIDs() []SourceImageID {
+	result := make([]SourceImageID, 0, len(s))
+	for id := range s {
+		result = append(result, id)
+	}
+	return result
 }
 
 func (s *sheetFileMeta) GetSpriteSheet(id SourceImageID) (*SpriteSheet, error) {
-	f, err := os.Open(s.fp)
+	f, err := s.fsys.Open(s.fp)
 	if err != nil {
 		return nil, err
 	}
 	defer f.Close()
 	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("sheet %s: %w", id, err)
+	}
 	return &SpriteSheet{
 		SourceImage: ebiten.NewImageFromImage(img),
 		SheetID:     id,
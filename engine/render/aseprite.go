@@ -0,0 +1,173 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/jessdwitch/spiders/engine/render/spritedata"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// AsepriteDefaultMode : The mode given to every frame of an export with no tags
+const AsepriteDefaultMode AnimationMode = spritedata.AsepriteDefaultMode
+
+type (
+	// AsepriteFiles : Sprites read straight from Aseprite JSON exports. Each export is one sprite
+	//	and one sheet, both named after the JSON file. Each tag is an animation mode, played in the
+	//	tag's direction, as many times as the tag repeats. Tags starting with @ mark frames.
+	AsepriteFiles struct {
+		*AtlasFiles
+		metas SpriteMetaManager
+	}
+	// SpriteSheetGetters : Look for a sheet in each getter in turn
+	SpriteSheetGetters []SpriteSheetGetter
+	// SpriteMetaGetters : Look for a sprite in each getter in turn
+	SpriteMetaGetters []SpriteMetaGetter
+)
+
+// LoadAseprite : Read every Aseprite JSON export in fsys matching pattern (as in
+// "img/aseprite/*.json"). Images are found relative to their JSON file.
+func LoadAseprite(fsys fs.FS, pattern string) (*AsepriteFiles, error) {
+	files, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return nil, err
+	}
+	result := &AsepriteFiles{
+		AtlasFiles: &AtlasFiles{
+			fsys:   fsys,
+			sheets: map[SourceImageID]atlasSheetMeta{},
+			pages:  map[string]*ebiten.Image{},
+		},
+		metas: SpriteMetaManager{},
+	}
+	for _, f := range files {
+		if err := result.load(f); err != nil {
+			return nil, fmt.Errorf("%s: %w", f, err)
+		}
+	}
+	return result, nil
+}
+
+func (a *AsepriteFiles) load(file string) error {
+	b, err := fs.ReadFile(a.fsys, file)
+	if err != nil {
+		return err
+	}
+	export, err := spritedata.ParseAseprite(b)
+	if err != nil {
+		return err
+	}
+	name := strings.TrimSuffix(path.Base(file), path.Ext(file))
+	sheet := atlasSheetMeta{page: path.Join(path.Dir(file), export.Image)}
+	for _, f := range export.Frames {
+		sheet.frames = append(sheet.frames, SheetFrame{
+			Rect: f.Frame.Rectangle(),
+			// Trimmed frames are offset inside the untrimmed canvas, whose top-left is the pivot
+			Origin:   image.Pt(-f.SpriteSourceSize.X, -f.SpriteSourceSize.Y),
+			Duration: msToDelay(f.Duration),
+		})
+	}
+	first := export.Frames[0].SourceSize
+	meta := SpriteMeta{InitialDims: Point{float64(first.W), float64(first.H)}}
+	for _, anim := range export.Anims {
+		durations := make([]int, len(anim.Sequence))
+		var animMarkers map[int]string
+		for i, f := range anim.Sequence {
+			durations[i] = sheet.frames[f].Duration
+			if m, ok := export.Markers[f]; ok {
+				if animMarkers == nil {
+					animMarkers = map[int]string{}
+				}
+				animMarkers[i] = m
+			}
+		}
+		meta.Anims = append(meta.Anims, AnimMeta{
+			Mode:       AnimationMode(anim.Mode),
+			Source:     SourceImageID(name),
+			Start:      anim.Sequence[0],
+			NFrames:    len(anim.Sequence),
+			FrameDelay: durations[0],
+			Durations:  durations,
+			Sequence:   anim.Sequence,
+			Playback:   anim.Playback,
+			Markers:    animMarkers,
+		})
+	}
+	a.sheets[SourceImageID(name)] = sheet
+	a.metas[SpriteID(name)] = meta
+	return nil
+}
+
+// msToDelay : Convert an Aseprite frame duration to an Animation delay. A frame with delay d is
+// held for d+1 ticks.
+func msToDelay(ms int) int {
+	ticks := (ms*ebiten.DefaultTPS + 500) / 1000
+	if ticks < 1 {
+		return 0
+	}
+	return ticks - 1
+}
+
+// GetSpriteMeta : Get Sprite metadata from an ID
+func (a *AsepriteFiles) GetSpriteMeta(id SpriteID) (SpriteMeta, error) {
+	return a.metas.GetSpriteMeta(id)
+}
+
+// GetSpriteSheet : Get the sheet from the first getter that has it
+func (g SpriteSheetGetters) GetSpriteSheet(id SourceImageID) (*SpriteSheet, error) {
+	for _, getter := range g {
+		sheet, err := getter.GetSpriteSheet(id)
+		if !errors.Is(err, ErrSheetNotFound) {
+			return sheet, err
+		}
+	}
+	return nil, fmt.Errorf("sheet %s: %w", id, ErrSheetNotFound)
+}
+
+func (g SpriteSheetGetters) sheetIDs() []SourceImageID {
+	result := []SourceImageID{}
+	for _, getter := range g {
+		if lister, ok := getter.(sheetLister); ok {
+			result = append(result, lister.sheetIDs()...)
+		}
+	}
+	return result
+}
+
+// GetSpriteMeta : Get the sprite from the first getter that has it
+func (g SpriteMetaGetters) GetSpriteMeta(id SpriteID) (SpriteMeta, error) {
+	for _, getter := range g {
+		meta, err := getter.GetSpriteMeta(id)
+		if !errors.Is(err, ErrSpriteNotFound) {
+			return meta, err
+		}
+	}
+	return SpriteMeta{}, fmt.Errorf("id %s: %w", id, ErrSpriteNotFound)
+}
+
+// AddAseprite : Also make sprites from the Aseprite exports in fsys matching pattern. Sprites in
+// the manifests win over exports with the same name. Reload re-reads the exports too.
+func (s *SpriteFactory) AddAseprite(fsys fs.FS, pattern string) error {
+	ase, err := LoadAseprite(fsys, pattern)
+	if err != nil {
+		return err
+	}
+	sheets := s.sourceImageGetter
+	cached, isCached := sheets.(*CachedSheets)
+	if isCached {
+		sheets = cached.source
+	}
+	sheets = SpriteSheetGetters{sheets, ase}
+	if isCached {
+		sheets = NewCachedSheets(sheets, cached.cache)
+	}
+	s.sourceImageGetter = sheets
+	s.spriteMetaGetter = SpriteMetaGetters{s.spriteMetaGetter, ase}
+	s.asepritePatterns = append(s.asepritePatterns, pattern)
+	return nil
+}
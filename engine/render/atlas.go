@@ -0,0 +1,145 @@
+package render
+
+import (
+	"encoding/csv"
+	"fmt"
+	"image"
+	"io"
+	"io/fs"
+	"sync"
+
+	"github.com/jessdwitch/spiders/engine/render/spritedata"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+type (
+	// AtlasFiles : A provider of sprite sheets packed into shared atlas pages by cmd/atlaspack.
+	//	Each sheet is a list of frame rectangles on one page, and sheets on the same page share a
+	//	single decoded image, so drawing them doesn't switch textures.
+	AtlasFiles struct {
+		fsys   fs.FS
+		sheets map[SourceImageID]atlasSheetMeta
+		mu     sync.Mutex
+		// pages : decoded pages by path
+		pages map[string]*ebiten.Image
+	}
+	atlasSheetMeta struct {
+		page   string
+		frames []SheetFrame
+	}
+)
+
+// NewAtlasFiles : Get a new SpriteSheetGetter from an atlas manifest. Page paths are relative to
+// fsys.
+func NewAtlasFiles(fsys fs.FS, manifest *csv.Reader) (*AtlasFiles, error) {
+	result := &AtlasFiles{
+		fsys:   fsys,
+		sheets: map[SourceImageID]atlasSheetMeta{},
+		pages:  map[string]*ebiten.Image{},
+	}
+	// strip header
+	_, err := manifest.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("atlas manifest is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+	for {
+		record, err := manifest.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		err = result.processManifestCsvRecord(record)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for id, meta := range result.sheets {
+		for i, f := range meta.frames {
+			if f.Rect.Empty() {
+				return nil, fmt.Errorf("atlas sheet %s is missing frame %d", id, i)
+			}
+		}
+	}
+	return result, nil
+}
+
+func (a *AtlasFiles) processManifestCsvRecord(record []string) error {
+	rec, err := spritedata.ParseAtlasRecord(record)
+	if err != nil {
+		return err
+	}
+	id := SourceImageID(rec.Sheet)
+	meta, ok := a.sheets[id]
+	if !ok {
+		meta.page = rec.Page
+	} else if meta.page != rec.Page {
+		return fmt.Errorf("atlas sheet %s spans pages %s and %s", id, meta.page, rec.Page)
+	}
+	for len(meta.frames) <= rec.Frame {
+		meta.frames = append(meta.frames, SheetFrame{})
+	}
+	if !meta.frames[rec.Frame].Rect.Empty() {
+		return fmt.Errorf("atlas sheet %s has frame %d twice", id, rec.Frame)
+	}
+	meta.frames[rec.Frame] = SheetFrame{Rect: rec.Rect, Origin: rec.Origin, Duration: rec.Duration}
+	a.sheets[id] = meta
+	return nil
+}
+
+// GetSpriteSheet : Get a registered SpriteSheet. Its page is decoded the first time any sheet on it
+// is asked for.
+func (a *AtlasFiles) GetSpriteSheet(id SourceImageID) (*SpriteSheet, error) {
+	meta, ok := a.sheets[id]
+	if !ok {
+		return nil, fmt.Errorf("sheet %s: %w", id, ErrSheetNotFound)
+	}
+	page, err := a.page(meta.page)
+	if err != nil {
+		return nil, fmt.Errorf("sheet %s: %w", id, err)
+	}
+	for _, f := range meta.frames {
+		if !f.Rect.In(page.Bounds()) {
+			return nil, fmt.Errorf("sheet %s: frame %v is outside page %s", id, f.Rect, meta.page)
+		}
+	}
+	w, h := page.Size()
+	return &SpriteSheet{
+		SourceImage: page,
+		SheetID:     id,
+		SheetDim:    image.Pt(w, h),
+		Frames:      meta.frames,
+	}, nil
+}
+
+func (a *AtlasFiles) page(p string) (*ebiten.Image, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if img, ok := a.pages[p]; ok {
+		return img, nil
+	}
+	f, err := a.fsys.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, err
+	}
+	a.pages[p] = ebiten.NewImageFromImage(img)
+	return a.pages[p], nil
+}
+
+func (a *AtlasFiles) sheetIDs() []SourceImageID {
+	result := make([]SourceImageID, 0, len(a.sheets))
+	for id := range a.sheets {
+		result = append(result, id)
+	}
+	return result
+}
@@ -0,0 +1,204 @@
+package render
+
+import (
+	"image"
+	"math"
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+type (
+	// Camera : The view of the world the screen shows. Looks at a world point, which is drawn in
+	//	the middle of the viewport, zoomed about it. Pan it with Translate, so tweens can move it.
+	Camera struct {
+		// position : the world point in the middle of the view
+		position Point
+		zoom     float64
+		// viewport : screen size, in pixels
+		viewport Point
+		// bounds : the world area the view stays inside, if bounded
+		bounds  image.Rectangle
+		bounded bool
+		// target : what the camera follows, if anything
+		target *Transform
+		// follow : how much of the way to the target to move each tick, from 0 to 1
+		follow float64
+		// shake : how far the view may jump, in screen pixels, at the start of a shake
+		shake      float64
+		shakeTicks int
+		shakeTick  int
+		// shakeOffset : this tick's jump
+		shakeOffset Point
+	}
+	// ViewDrawer : Can be drawn through a camera. Drawers in world layers that aren't ViewDrawers
+	//	are drawn in screen space.
+	ViewDrawer interface {
+		// DrawView : Draw as Draw would, then apply view
+		DrawView(screen *ebiten.Image, view ebiten.GeoM)
+	}
+)
+
+// NewCamera : A camera for a viewport of the given size, looking at the middle of it, so world
+// and screen coordinates match until it moves
+func NewCamera(width, height int) *Camera {
+	return &Camera{
+		position: Point{float64(width) / 2, float64(height) / 2},
+		zoom:     1,
+		viewport: Point{float64(width), float64(height)},
+	}
+}
+
+// SetViewport : Resize the screen area the camera draws to
+func (c *Camera) SetViewport(width, height int) {
+	c.viewport = Point{float64(width), float64(height)}
+	c.clamp()
+}
+
+// LookAt : Center the view on a world point
+func (c *Camera) LookAt(x, y float64) {
+	c.position = Point{x, y}
+	c.clamp()
+}
+
+// GetPosition : The world point in the middle of the view
+func (c *Camera) GetPosition() Point {
+	return c.position
+}
+
+// Translate : Pan the view by a world offset
+func (c *Camera) Translate(x, y float64) {
+	c.LookAt(c.position.X+x, c.position.Y+y)
+}
+
+// Zoom : The current zoom. 2 draws the world at double size.
+func (c *Camera) Zoom() float64 {
+	return c.zoom
+}
+
+// SetZoom : Zoom about the middle of the view. Zooms of 0 or less are ignored.
+func (c *Camera) SetZoom(zoom float64) {
+	if zoom <= 0 {
+		return
+	}
+	c.zoom = zoom
+	c.clamp()
+}
+
+// SetBounds : Keep the view inside a world area. If the area is smaller than the view, the view
+// is centered on it.
+func (c *Camera) SetBounds(r image.Rectangle) {
+	c.bounds = r
+	c.bounded = true
+	c.clamp()
+}
+
+// ClearBounds : Let the view go anywhere
+func (c *Camera) ClearBounds() {
+	c.bounded = false
+}
+
+// Follow : Move towards a transform every tick, covering smoothing of the distance each time. A
+// smoothing of 1 snaps to it. A nil target stops following.
+func (c *Camera) Follow(target *Transform, smoothing float64) {
+	c.target = target
+	c.follow = math.Max(0, math.Min(1, smoothing))
+}
+
+// Shake : Jolt the view by up to magnitude screen pixels, settling over ticks. Stronger shakes
+// replace weaker ones.
+func (c *Camera) Shake(magnitude float64, ticks int) {
+	if c.shakeTick < c.shakeTicks && c.currentShake() > magnitude {
+		return
+	}
+	c.shake = magnitude
+	c.shakeTicks = ticks
+	c.shakeTick = 0
+}
+
+// Shaking : Is a shake still settling?
+func (c *Camera) Shaking() bool {
+	return c.shakeTick < c.shakeTicks
+}
+
+func (c *Camera) currentShake() float64 {
+	if c.shakeTicks == 0 {
+		return 0
+	}
+	return c.shake * float64(c.shakeTicks-c.shakeTick) / float64(c.shakeTicks)
+}
+
+// Update : Hook for the engine's tick function. Follows the target and moves the shake along.
+func (c *Camera) Update() error {
+	if c.target != nil {
+		to := c.target.WorldPosition()
+		c.LookAt(
+			c.position.X+(to.X-c.position.X)*c.follow,
+			c.position.Y+(to.Y-c.position.Y)*c.follow,
+		)
+	}
+	c.shakeOffset = Point{}
+	if c.Shaking() {
+		m := c.currentShake()
+		c.shakeOffset = Point{(rand.Float64()*2 - 1) * m, (rand.Float64()*2 - 1) * m}
+		c.shakeTick++
+	}
+	return nil
+}
+
+// GeoM : The world to screen transform
+func (c *Camera) GeoM() ebiten.GeoM {
+	g := ebiten.GeoM{}
+	g.Translate(-c.position.X, -c.position.Y)
+	g.Scale(c.zoom, c.zoom)
+	g.Translate(c.viewport.X/2+c.shakeOffset.X, c.viewport.Y/2+c.shakeOffset.Y)
+	return g
+}
+
+// WorldToScreen : Where a world point is drawn
+func (c *Camera) WorldToScreen(p Point) Point {
+	g := c.GeoM()
+	x, y := g.Apply(p.X, p.Y)
+	return Point{x, y}
+}
+
+// ScreenToWorld : The world point drawn at a screen point, as in under the cursor
+func (c *Camera) ScreenToWorld(p Point) Point {
+	g := c.GeoM()
+	g.Invert()
+	x, y := g.Apply(p.X, p.Y)
+	return Point{x, y}
+}
+
+// View : The world area the screen shows, ignoring shake
+func (c *Camera) View() image.Rectangle {
+	halfW, halfH := c.viewport.X/2/c.zoom, c.viewport.Y/2/c.zoom
+	return image.Rect(
+		int(math.Floor(c.position.X-halfW)), int(math.Floor(c.position.Y-halfH)),
+		int(math.Ceil(c.position.X+halfW)), int(math.Ceil(c.position.Y+halfH)),
+	)
+}
+
+// clamp : Pull the view back inside the bounds
+func (c *Camera) clamp() {
+	if !c.bounded {
+		return
+	}
+	halfW, halfH := c.viewport.X/2/c.zoom, c.viewport.Y/2/c.zoom
+	c.position.X = clampAxis(c.position.X, halfW, float64(c.bounds.Min.X), float64(c.bounds.Max.X))
+	c.position.Y = clampAxis(c.position.Y, halfH, float64(c.bounds.Min.Y), float64(c.bounds.Max.Y))
+}
+
+func clampAxis(v, half, min, max float64) float64 {
+	if max-min <= 2*half {
+		return (min + max) / 2
+	}
+	return math.Max(min+half, math.Min(max-half, v))
+}
+
+// DrawView : Draw the tile, then apply view, as in a camera's
+func (t *Tile) DrawView(screen *ebiten.Image, view ebiten.GeoM) {
+	g := t.geoM()
+	g.Concat(view)
+	t.draw(screen, g)
+}
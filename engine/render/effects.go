@@ -0,0 +1,226 @@
+package render
+
+import (
+	"embed"
+	"fmt"
+	"image/color"
+	"path"
+	"strings"
+
+	"github.com/jessdwitch/spiders/engine/render/spritedata"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Shaders the library compiles. Each is a Kage file in shaders/.
+const (
+	// ShaderPaletteSwap : Swap up to MaxPaletteColors colors for others. See SetPalette.
+	ShaderPaletteSwap ShaderID = "palette"
+	// ShaderFlash : Wash towards a color, white by default, as when hit
+	ShaderFlash ShaderID = "flash"
+	// ShaderGreyscale : Drain color, as from a knocked out pawn
+	ShaderGreyscale ShaderID = "greyscale"
+	// ShaderVignette : Darken towards the corners
+	ShaderVignette ShaderID = "vignette"
+	// ShaderCRT : Curved glass and scanlines, as an old TV
+	ShaderCRT ShaderID = "crt"
+)
+
+// MaxPaletteColors : How many colors one palette swap can change
+const MaxPaletteColors = spritedata.MaxPaletteColors
+
+//go:embed shaders/*.kage
+var shaderFiles embed.FS
+
+type (
+	// ShaderID : An identifier for a compiled shader
+	ShaderID string
+	// ShaderLibrary : Compiled shaders, to make Effects from
+	ShaderLibrary map[ShaderID]*ebiten.Shader
+	// Effect : A shader, and the uniforms to draw with it. Every shader takes Amount, from 0 (no
+	//	change) to 1. Effects can be drawn per sprite with Tile.SetEffect, or over the whole
+	//	screen in a PostProcess.
+	Effect struct {
+		id       ShaderID
+		shader   *ebiten.Shader
+		uniforms map[string]interface{}
+		disabled bool
+	}
+	// PostProcess : Full screen effects, applied one after another
+	PostProcess struct {
+		effects []*Effect
+		// buffers : effects draw back and forth between these
+		buffers [2]*ebiten.Image
+	}
+)
+
+// NewShaderLibrary : Compile every built-in shader
+func NewShaderLibrary() (ShaderLibrary, error) {
+	files, err := shaderFiles.ReadDir("shaders")
+	if err != nil {
+		return nil, err
+	}
+	result := ShaderLibrary{}
+	for _, f := range files {
+		src, err := shaderFiles.ReadFile(path.Join("shaders", f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		shader, err := ebiten.NewShader(src)
+		if err != nil {
+			return nil, fmt.Errorf("shader %s: %w", f.Name(), err)
+		}
+		result[ShaderID(strings.TrimSuffix(f.Name(), path.Ext(f.Name())))] = shader
+	}
+	return result, nil
+}
+
+// NewEffect : Make an effect at full Amount. Each sprite wanting its own Amount needs its own
+// effect.
+func (l ShaderLibrary) NewEffect(id ShaderID) (*Effect, error) {
+	shader, ok := l[id]
+	if !ok {
+		return nil, fmt.Errorf("shader %s not found", id)
+	}
+	e := &Effect{id: id, shader: shader, uniforms: map[string]interface{}{}}
+	e.SetAmount(1)
+	if id == ShaderFlash {
+		e.SetUniform("Color", []float32{1, 1, 1})
+	}
+	return e, nil
+}
+
+// ID : The effect's shader
+func (e *Effect) ID() ShaderID {
+	return e.id
+}
+
+// SetUniform : Set a shader variable. Values are float32s, or []float32s for vectors and arrays.
+func (e *Effect) SetUniform(name string, value interface{}) {
+	e.uniforms[name] = value
+}
+
+// Amount : How strong the effect is, from 0 to 1
+func (e *Effect) Amount() float64 {
+	if a, ok := e.uniforms["Amount"].(float32); ok {
+		return float64(a)
+	}
+	return 0
+}
+
+// SetAmount : Set how strong the effect is, from 0 to 1, as in fading a flash out with a tween
+func (e *Effect) SetAmount(a float64) {
+	e.uniforms["Amount"] = float32(a)
+}
+
+// Enabled : Is the effect drawn? Disabled effects draw as if they weren't there.
+func (e *Effect) Enabled() bool {
+	return !e.disabled
+}
+
+// SetEnabled : Turn the effect on or off
+func (e *Effect) SetEnabled(enabled bool) {
+	e.disabled = !enabled
+}
+
+// SetColor : Set the color a flash washes towards
+func (e *Effect) SetColor(c color.Color) {
+	s := colorScales(c)
+	e.SetUniform("Color", []float32{float32(s[0]), float32(s[1]), float32(s[2])})
+}
+
+// SetPalette : For a palette swap, draw each color in from as the color in to at the same index.
+// Alpha is kept from the image.
+func (e *Effect) SetPalette(from, to []color.Color) error {
+	if len(from) != len(to) {
+		return fmt.Errorf("palette swap has %d colors to swap and %d to swap for", len(from), len(to))
+	}
+	if len(from) > MaxPaletteColors {
+		return fmt.Errorf("palette swap has %d colors, max %d", len(from), MaxPaletteColors)
+	}
+	f, t := make([]float32, 4*MaxPaletteColors), make([]float32, 4*MaxPaletteColors)
+	for i := range from {
+		fs, ts := colorScales(from[i]), colorScales(to[i])
+		for j := 0; j < 3; j++ {
+			f[4*i+j], t[4*i+j] = float32(fs[j]), float32(ts[j])
+		}
+		// Alpha marks the entry used
+		f[4*i+3], t[4*i+3] = 1, 1
+	}
+	e.SetUniform("From", f)
+	e.SetUniform("To", t)
+	return nil
+}
+
+// colorScales : A color as straight, not premultiplied, scales from 0 to 1
+func colorScales(c color.Color) [4]float64 {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return [4]float64{float64(n.R) / 0xff, float64(n.G) / 0xff, float64(n.B) / 0xff, float64(n.A) / 0xff}
+}
+
+// DrawImage : Draw src onto dst through the effect, as DrawImage would with g and colorScale
+func (e *Effect) DrawImage(dst, src *ebiten.Image, g ebiten.GeoM, colorScale [4]float64) {
+	e.uniforms["ColorScale"] = []float32{
+		float32(colorScale[0]), float32(colorScale[1]), float32(colorScale[2]), float32(colorScale[3]),
+	}
+	op := &ebiten.DrawRectShaderOptions{GeoM: g, Uniforms: e.uniforms}
+	op.Images[0] = src
+	w, h := src.Size()
+	dst.DrawRectShader(w, h, e.shader, op)
+}
+
+// NewPostProcess : Apply effects in order over a whole screen
+func NewPostProcess(effects ...*Effect) *PostProcess {
+	return &PostProcess{effects: effects}
+}
+
+// Add : Apply another effect after the rest
+func (p *PostProcess) Add(e *Effect) {
+	p.effects = append(p.effects, e)
+}
+
+// Get : The first effect using a shader, if any, as in to toggle it from settings
+func (p *PostProcess) Get(id ShaderID) *Effect {
+	for _, e := range p.effects {
+		if e.id == id {
+			return e
+		}
+	}
+	return nil
+}
+
+// Apply : Run src through every enabled effect. Returns src if none are, or else one of the
+// chain's buffers, which is overwritten by the next Apply.
+func (p *PostProcess) Apply(src *ebiten.Image) *ebiten.Image {
+	size := src.Bounds().Size()
+	current := src
+	next := 0
+	for _, e := range p.effects {
+		if !e.Enabled() {
+			continue
+		}
+		buf := p.buffers[next]
+		if buf == nil || buf.Bounds().Size() != size {
+			if buf != nil {
+				buf.Dispose()
+			}
+			buf = ebiten.NewImage(size.X, size.Y)
+			p.buffers[next] = buf
+		}
+		buf.Clear()
+		e.DrawImage(buf, current, ebiten.GeoM{}, [4]float64{1, 1, 1, 1})
+		current = buf
+		next = 1 - next
+	}
+	return current
+}
+
+// SetEffect : Draw the tile through an effect, or normally if nil
+func (t *Tile) SetEffect(e *Effect) {
+	t.effect = e
+}
+
+// GetEffect : The effect the tile is drawn through, if any
+func (t *Tile) GetEffect() *Effect {
+	return t.effect
+}
@@ -0,0 +1,94 @@
+package render
+
+import (
+	"github.com/jessdwitch/spiders/engine/render/spritedata"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+type (
+	// Palette : Colors to swap, each From color for the To color at the same index
+	Palette = spritedata.Palette
+	// paletteVariant : A sheet recolored with a palette
+	paletteVariant struct {
+		sheet   SourceImageID
+		palette string
+	}
+)
+
+// ParsePalette : Parse a sprite manifest palette: semicolon-separated from>to pairs of hex colors,
+// as in "#3e8948>#e43b44;#63c74d>#f6757a". Empty is no palette.
+func ParsePalette(field string) (Palette, error) {
+	return spritedata.ParsePalette(field)
+}
+
+// NewPaletteEffect : An effect drawing with p's colors swapped, as for recoloring a sprite at
+// draw time, like a poisoned pawn turning green
+func (l ShaderLibrary) NewPaletteEffect(p Palette) (*Effect, error) {
+	e, err := l.NewEffect(ShaderPaletteSwap)
+	if err != nil {
+		return nil, err
+	}
+	return e, e.SetPalette(p.From, p.To)
+}
+
+// SetShaders : Give the factory shaders, which sprites with palettes need to be recolored.
+// Without them, those sprites draw in their sheet's own colors.
+func (s *SpriteFactory) SetShaders(shaders ShaderLibrary) {
+	s.shaders = shaders
+}
+
+// recolor : The sheet with p's colors swapped, recolored once at load and shared between every
+// sprite using the same sheet and palette. Without shaders, the sheet is used as it is. If source
+// is cached, shared is true and the copy holds a reference like the sheet's, given back with it.
+func (s *SpriteFactory) recolor(
+	source SpriteSheetGetter,
+	id SourceImageID,
+	sheet *SpriteSheet,
+	p Palette,
+) (variant *SpriteSheet, shared bool, err error) {
+	if s.shaders == nil {
+		return sheet, false, nil
+	}
+	key := paletteVariant{id, p.String()}
+	paint := func() (*SpriteSheet, error) {
+		return s.paint(sheet, p)
+	}
+	if cached, ok := source.(*CachedSheets); ok {
+		if s.sharedVariants == nil {
+			s.sharedVariants = map[paletteVariant]bool{}
+		}
+		s.sharedVariants[key] = true
+		variant, err = cached.getVariant(key, paint)
+		return variant, err == nil, err
+	}
+	// Uncached sheets are never let go of, so neither are their variants
+	if variant, ok := s.variants[key]; ok {
+		return variant, false, nil
+	}
+	if variant, err = paint(); err != nil {
+		return nil, false, err
+	}
+	if s.variants == nil {
+		s.variants = map[paletteVariant]*SpriteSheet{}
+	}
+	s.variants[key] = variant
+	return variant, false, nil
+}
+
+// paint : Draw a copy of the sheet with p's colors swapped
+func (s *SpriteFactory) paint(sheet *SpriteSheet, p Palette) (*SpriteSheet, error) {
+	effect, err := s.shaders.NewPaletteEffect(p)
+	if err != nil {
+		return nil, err
+	}
+	// Frame rects are in the source image's coordinates, so keep them
+	bounds := sheet.SourceImage.Bounds()
+	img := ebiten.NewImage(bounds.Max.X, bounds.Max.Y)
+	g := ebiten.GeoM{}
+	g.Translate(float64(bounds.Min.X), float64(bounds.Min.Y))
+	effect.DrawImage(img, sheet.SourceImage, g, [4]float64{1, 1, 1, 1})
+	variant := *sheet
+	variant.SourceImage = img
+	return &variant, nil
+}
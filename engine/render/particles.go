@@ -0,0 +1,419 @@
+package render
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/jessdwitch/spiders/engine/render/spritedata"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// ErrEmitterNotFound : No emitter is registered under the ID
+var ErrEmitterNotFound = errors.New("emitter not found")
+
+// emitterManifestColumns : name, sheet, start, nFrames, rate, max, life, vx, vy, gravity, color,
+// scale
+const emitterManifestColumns = 12
+
+type (
+	// EmitterID : An identifier for a registered emitter
+	EmitterID string
+	// Range : A span of values to pick from at random, inclusive
+	Range struct {
+		Min float64
+		Max float64
+	}
+	// EmitterMeta : How an emitter's particles look and move
+	EmitterMeta struct {
+		// Source, Start, NFrames : The sheet frames particles play through over their lives
+		Source  SourceImageID
+		Start   int
+		NFrames int
+		// Rate : Particles spawned per tick. May be fractional, as in 0.25 for one every 4 ticks.
+		Rate float64
+		// Max : How many particles can be alive at once. Spawns past it are dropped.
+		Max int
+		// Life : Ticks each particle lives
+		Life Range
+		// VelocityX, VelocityY : Starting velocity, in pixels per tick
+		VelocityX Range
+		VelocityY Range
+		// Gravity : Added to each particle's velocity every tick
+		Gravity Point
+		// StartColor, EndColor : Color scales at birth and death, blended between over life
+		StartColor [4]float64
+		EndColor   [4]float64
+		// StartScale, EndScale : Size at birth and death, blended between over life
+		StartScale float64
+		EndScale   float64
+	}
+	// EmitterMetaGetter : Provides emitter metadata
+	EmitterMetaGetter interface {
+		// GetEmitterMeta : Get emitter metadata from an ID
+		GetEmitterMeta(EmitterID) (EmitterMeta, error)
+	}
+	// EmitterMetaManager : Emitter metadata, as read from a manifest
+	EmitterMetaManager map[EmitterID]EmitterMeta
+	// ParticleFactory : Makes emitters from their metadata and sheets
+	ParticleFactory struct {
+		sheets SpriteSheetGetter
+		// sprites : the factory whose sheets are used instead, if any, so they're current after
+		//	a reload
+		sprites *SpriteFactory
+		metas   EmitterMetaGetter
+	}
+	// Emitter : Spawns, moves, and draws particles. Particles spawn at the emitter's world
+	//	position, then move on their own, so attaching the emitter to a sprite leaves a trail.
+	//	Particles are pooled, so a running emitter doesn't allocate.
+	Emitter struct {
+		*Transform
+		meta   EmitterMeta
+		frames []*ebiten.Image
+		// particles : the pool. The first live are alive.
+		particles []particle
+		live      int
+		// owed : fractional particles carried over between ticks
+		owed     float64
+		emitting bool
+		// op : reused between draws
+		op ebiten.DrawImageOptions
+	}
+	particle struct {
+		position Point
+		velocity Point
+		age      int
+		life     int
+	}
+)
+
+// NewEmitterMetaManager : Read an emitter manifest
+func NewEmitterMetaManager(manifest *csv.Reader) (EmitterMetaManager, error) {
+	result := EmitterMetaManager{}
+	// strip header
+	_, err := manifest.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("emitter manifest is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+	for {
+		record, err := manifest.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		err = result.processManifestCsvRecord(record)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (e EmitterMetaManager) processManifestCsvRecord(record []string) error {
+	// record: name, sheet, start, nFrames, rate, max, life, vx, vy, gravity, color, scale
+	if len(record) != emitterManifestColumns {
+		return fmt.Errorf("emitter manifest record %v has %d columns, want %d",
+			record, len(record), emitterManifestColumns)
+	}
+	meta, err := parseEmitterRecord(record)
+	if err != nil {
+		return fmt.Errorf("emitter %s: %w", record[0], err)
+	}
+	e[EmitterID(record[0])] = meta
+	return nil
+}
+
+func parseEmitterRecord(record []string) (EmitterMeta, error) {
+	meta := EmitterMeta{Source: SourceImageID(record[1])}
+	var err error
+	if meta.Start, err = strconv.Atoi(record[2]); err != nil {
+		return meta, err
+	}
+	if meta.NFrames, err = strconv.Atoi(record[3]); err != nil {
+		return meta, err
+	}
+	if meta.NFrames < 1 {
+		return meta, fmt.Errorf("needs at least one frame")
+	}
+	if meta.Rate, err = strconv.ParseFloat(record[4], 64); err != nil {
+		return meta, err
+	}
+	if meta.Max, err = strconv.Atoi(record[5]); err != nil {
+		return meta, err
+	}
+	if meta.Rate < 0 || meta.Max < 0 {
+		return meta, fmt.Errorf("rate %v and max %d can't be negative", meta.Rate, meta.Max)
+	}
+	if meta.Life, err = parseRange(record[6]); err != nil {
+		return meta, err
+	}
+	if meta.Life.Min < 1 {
+		return meta, fmt.Errorf("life %v must be at least a tick", meta.Life)
+	}
+	if meta.VelocityX, err = parseRange(record[7]); err != nil {
+		return meta, err
+	}
+	if meta.VelocityY, err = parseRange(record[8]); err != nil {
+		return meta, err
+	}
+	if meta.Gravity.X, meta.Gravity.Y, err = parsePair(record[9]); err != nil {
+		return meta, err
+	}
+	colors := strings.Split(record[10], ";")
+	if len(colors) > 2 {
+		return meta, fmt.Errorf("color %q should be start;end", record[10])
+	}
+	if meta.StartColor, err = ParseColorScale(colors[0]); err != nil {
+		return meta, err
+	}
+	meta.EndColor = meta.StartColor
+	if len(colors) == 2 {
+		if meta.EndColor, err = ParseColorScale(colors[1]); err != nil {
+			return meta, err
+		}
+	}
+	if meta.StartScale, meta.EndScale, err = parsePair(record[11]); err != nil {
+		return meta, err
+	}
+	return meta, nil
+}
+
+// parsePair : Parse two manifest values, as in x;y or start;end. One value is used for both.
+// Unlike a range, the second may be smaller, as in a particle shrinking.
+func parsePair(field string) (float64, float64, error) {
+	parts := strings.Split(field, ";")
+	if len(parts) > 2 {
+		return 0, 0, fmt.Errorf("%q should be a value or a;b", field)
+	}
+	values := make([]float64, len(parts))
+	for i, p := range parts {
+		v, err := strconv.ParseFloat(p, 64)
+		if err != nil {
+			return 0, 0, err
+		}
+		values[i] = v
+	}
+	return values[0], values[len(values)-1], nil
+}
+
+// parseRange : Parse a manifest range: either one value, or min;max
+func parseRange(field string) (Range, error) {
+	parts := strings.Split(field, ";")
+	if len(parts) > 2 {
+		return Range{}, fmt.Errorf("range %q should be a value or min;max", field)
+	}
+	values := make([]float64, len(parts))
+	for i, p := range parts {
+		v, err := strconv.ParseFloat(p, 64)
+		if err != nil {
+			return Range{}, err
+		}
+		values[i] = v
+	}
+	r := Range{values[0], values[len(values)-1]}
+	if r.Min > r.Max {
+		return Range{}, fmt.Errorf("range %q goes backwards", field)
+	}
+	return r, nil
+}
+
+// ParseColorScale : Parse a hex color, as in "#ff8800" or "#ff880080", into color scales from 0
+// to 1. Alpha is 1 if not given.
+func ParseColorScale(s string) ([4]float64, error) {
+	return spritedata.ParseColorScale(s)
+}
+
+// GetEmitterMeta : Get emitter metadata from an ID
+func (e EmitterMetaManager) GetEmitterMeta(id EmitterID) (EmitterMeta, error) {
+	if meta, ok := e[id]; ok {
+		return meta, nil
+	}
+	return EmitterMeta{}, fmt.Errorf("id %s: %w", id, ErrEmitterNotFound)
+}
+
+// NewParticleFactory : Make emitters from metadata, with frames from sheets
+func NewParticleFactory(sheets SpriteSheetGetter, metas EmitterMetaGetter) *ParticleFactory {
+	return &ParticleFactory{sheets: sheets, metas: metas}
+}
+
+// GetEmitter : Make a new, stopped emitter
+func (f *ParticleFactory) GetEmitter(id EmitterID) (*Emitter, error) {
+	meta, err := f.metas.GetEmitterMeta(id)
+	if err != nil {
+		return nil, err
+	}
+	sheet, err := f.sheetGetter().GetSpriteSheet(meta.Source)
+	if err != nil {
+		return nil, err
+	}
+	anim, err := sheet.ExtractAnimation(AnimMeta{
+		Mode:    AnimationMode(id),
+		Source:  meta.Source,
+		Start:   meta.Start,
+		NFrames: meta.NFrames,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return NewEmitter(meta, anim.Frames), nil
+}
+
+// Release : Let go of the sheet held by an emitter from GetEmitter, so it can be evicted once
+// nothing else uses it
+func (f *ParticleFactory) Release(id EmitterID) error {
+	cache, ok := f.sheetGetter().(SheetCache)
+	if !ok {
+		return nil
+	}
+	meta, err := f.metas.GetEmitterMeta(id)
+	if err != nil {
+		return err
+	}
+	cache.ReleaseSheet(meta.Source)
+	return nil
+}
+
+func (f *ParticleFactory) sheetGetter() SpriteSheetGetter {
+	if f.sprites != nil {
+		return f.sprites.sourceImageGetter
+	}
+	return f.sheets
+}
+
+// Particles : Make a ParticleFactory sharing this factory's sheets, and its sheet cache
+func (s *SpriteFactory) Particles(metas EmitterMetaGetter) *ParticleFactory {
+	return &ParticleFactory{sprites: s, metas: metas}
+}
+
+// NewEmitter : Make a stopped emitter drawing particles with frames, which they play through
+// over their lives
+func NewEmitter(meta EmitterMeta, frames []*ebiten.Image) *Emitter {
+	return &Emitter{
+		Transform: NewTransform(0, 0),
+		meta:      meta,
+		frames:    frames,
+		particles: make([]particle, meta.Max),
+	}
+}
+
+// Attach : Spawn particles wherever t is. Call SetPosition for an offset from it.
+func (e *Emitter) Attach(t Transformer) error {
+	return e.SetParent(t.GetTransform())
+}
+
+// Start : Spawn particles every tick, at the emitter's rate
+func (e *Emitter) Start() {
+	e.emitting = true
+}
+
+// Stop : Stop spawning. Live particles play out.
+func (e *Emitter) Stop() {
+	e.emitting = false
+	e.owed = 0
+}
+
+// Emitting : Is the emitter spawning particles?
+func (e *Emitter) Emitting() bool {
+	return e.emitting
+}
+
+// Burst : Spawn n particles at once, as in a hit
+func (e *Emitter) Burst(n int) {
+	at := e.WorldPosition()
+	for i := 0; i < n; i++ {
+		e.spawn(at)
+	}
+}
+
+// Live : How many particles are alive
+func (e *Emitter) Live() int {
+	return e.live
+}
+
+// Done : Stopped, with no particles left to draw
+func (e *Emitter) Done() bool {
+	return !e.emitting && e.live == 0
+}
+
+func (e *Emitter) spawn(at Point) {
+	if e.live >= len(e.particles) {
+		return
+	}
+	e.particles[e.live] = particle{
+		position: at,
+		velocity: Point{e.meta.VelocityX.random(), e.meta.VelocityY.random()},
+		life:     int(e.meta.Life.random()),
+	}
+	e.live++
+}
+
+func (r Range) random() float64 {
+	return r.Min + rand.Float64()*(r.Max-r.Min)
+}
+
+// Update : Hook for the engine's tick function. Ages and moves particles, then spawns new ones.
+func (e *Emitter) Update() error {
+	for i := 0; i < e.live; {
+		p := &e.particles[i]
+		p.age++
+		if p.age >= p.life {
+			// Swap the dead particle out of the live span
+			e.live--
+			e.particles[i] = e.particles[e.live]
+			continue
+		}
+		p.velocity.X += e.meta.Gravity.X
+		p.velocity.Y += e.meta.Gravity.Y
+		p.position.X += p.velocity.X
+		p.position.Y += p.velocity.Y
+		i++
+	}
+	if e.emitting {
+		e.owed += e.meta.Rate
+		n := int(e.owed)
+		e.owed -= float64(n)
+		e.Burst(n)
+	}
+	return nil
+}
+
+// Draw : Engine Draw hook
+func (e *Emitter) Draw(screen *ebiten.Image) {
+	e.DrawView(screen, ebiten.GeoM{})
+}
+
+// DrawView : Draw the particles, then apply view, as in a camera's
+func (e *Emitter) DrawView(screen *ebiten.Image, view ebiten.GeoM) {
+	if len(e.frames) == 0 {
+		return
+	}
+	for i := 0; i < e.live; i++ {
+		p := &e.particles[i]
+		progress := float64(p.age) / float64(p.life)
+		frame := e.frames[int(progress*float64(len(e.frames)))]
+		w, h := frame.Size()
+		scale := e.meta.StartScale + (e.meta.EndScale-e.meta.StartScale)*progress
+		e.op.GeoM.Reset()
+		e.op.GeoM.Translate(-float64(w)/2, -float64(h)/2)
+		e.op.GeoM.Scale(scale, scale)
+		e.op.GeoM.Translate(p.position.X, p.position.Y)
+		e.op.GeoM.Concat(view)
+		e.op.ColorM.Reset()
+		c := [4]float64{}
+		for j := range c {
+			c[j] = e.meta.StartColor[j] + (e.meta.EndColor[j]-e.meta.StartColor[j])*progress
+		}
+		e.op.ColorM.Scale(c[0], c[1], c[2], c[3])
+		screen.DrawImage(frame, &e.op)
+	}
+}
@@ -0,0 +1,161 @@
+package render
+
+import (
+	"fmt"
+
+	"github.com/jessdwitch/spiders/engine/render/spritedata"
+)
+
+type (
+	// PlaybackMode : What an animation does when it runs out of frames. See spritedata.
+	PlaybackMode = spritedata.PlaybackMode
+	// AnimationEventKind : What happened to an animation
+	AnimationEventKind int
+	// AnimationEvent : Something that happened to a sprite's animation on this tick
+	AnimationEvent struct {
+		Kind AnimationEventKind
+		Mode AnimationMode
+		// Marker : The frame's name, for AnimationMarker events
+		Marker string
+		// Frame : The frame the animation is on
+		Frame int
+	}
+)
+
+// Playback modes, as in spritedata
+const (
+	PlayLoop     = spritedata.PlayLoop
+	PlayOnce     = spritedata.PlayOnce
+	PlayPingPong = spritedata.PlayPingPong
+	PlayHoldLast = spritedata.PlayHoldLast
+)
+
+// Animation events
+const (
+	// AnimationFinished : A PlayOnce or PlayHoldLast animation got to the end of its last frame
+	AnimationFinished AnimationEventKind = iota
+	// AnimationLooped : A PlayLoop or PlayPingPong animation finished a cycle
+	AnimationLooped
+	// AnimationMarker : The animation reached a marked frame
+	AnimationMarker
+)
+
+// ParsePlaybackMode : As spritedata.ParsePlaybackMode
+func ParsePlaybackMode(name string) (PlaybackMode, error) {
+	return spritedata.ParsePlaybackMode(name)
+}
+
+// advance : Move along one tick. entered is true if this moved to a new frame, and ended if it
+// finished a cycle or the whole animation.
+func (a *Animation) advance() (entered, ended bool) {
+	if len(a.Frames) == 0 {
+		return false, false
+	}
+	defer func() { a.show(a.CurrentFrame) }()
+	if a.done {
+		return false, false
+	}
+	a.FrameDelay--
+	if a.FrameDelay >= 0 {
+		return false, false
+	}
+	last := len(a.Frames) - 1
+	switch a.Playback {
+	case PlayOnce, PlayHoldLast:
+		if a.CurrentFrame >= last {
+			a.done = true
+			return false, true
+		}
+		a.CurrentFrame++
+	case PlayPingPong:
+		if a.direction == 0 {
+			a.direction = 1
+		}
+		next := a.CurrentFrame + a.direction
+		if next < 0 || next > last {
+			a.direction = -a.direction
+			next = a.CurrentFrame + a.direction
+		}
+		if next < 0 || next > last {
+			// Only one frame
+			next = 0
+		}
+		a.CurrentFrame = next
+		ended = next == 0 && a.direction < 0
+	default:
+		a.CurrentFrame++
+		if a.CurrentFrame > last {
+			a.CurrentFrame = 0
+			ended = true
+		}
+	}
+	a.FrameDelay = a.delay(a.CurrentFrame)
+	return true, ended
+}
+
+// Update : Hook for the engine's tick function. Moves the animation along, plays the next one when
+// it ends, and tells listeners what happened.
+func (b *BasicSprite) Update() error {
+	if b.Animation == nil {
+		return nil
+	}
+	entered, ended := b.advance()
+	if entered {
+		b.enter()
+	}
+	if !ended {
+		return nil
+	}
+	kind := AnimationLooped
+	if !b.Playback.Loops() {
+		kind = AnimationFinished
+	}
+	b.emit(AnimationEvent{Kind: kind, Mode: b.mode, Frame: b.CurrentFrame})
+	switch {
+	case len(b.queue) > 0:
+		next := b.queue[0]
+		b.queue = b.queue[1:]
+		if _, err := b.play(next); err != nil {
+			return err
+		}
+	case b.Playback == PlayOnce && b.resume != "" && b.resume != b.mode:
+		if _, err := b.play(b.resume); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Queue : Play an animation once the current one ends, after any already queued. A looping
+// animation ends at the end of its cycle; a once or held one ends after its last frame's delay.
+func (b *BasicSprite) Queue(mode AnimationMode) error {
+	if _, ok := b.registeredAnimations[mode]; !ok {
+		return fmt.Errorf("animation %v could not be found for sprite %v", mode, b.id)
+	}
+	if b.Animation != nil && b.done {
+		// Already at the end, so there's nothing to wait for
+		_, err := b.play(mode)
+		return err
+	}
+	b.queue = append(b.queue, mode)
+	return nil
+}
+
+// OnAnimationEvent : Call f as animations finish, loop, or reach marked frames. Listeners are
+// called from Update, in the order they were added.
+func (b *BasicSprite) OnAnimationEvent(f func(AnimationEvent)) {
+	b.listeners = append(b.listeners, f)
+}
+
+// enter : Tell listeners if the current frame is marked
+func (b *BasicSprite) enter() {
+	if name, ok := b.Markers[b.CurrentFrame]; ok {
+		b.emit(AnimationEvent{Kind: AnimationMarker, Mode: b.mode, Marker: name, Frame: b.CurrentFrame})
+	}
+}
+
+func (b *BasicSprite) emit(e AnimationEvent) {
+	for _, f := range b.listeners {
+		f(e)
+	}
+}
@@ -0,0 +1,77 @@
+package render
+
+import (
+	"io/fs"
+)
+
+// TrackSprites : Remember every sprite made from now on, so Reload can rebuild them. Meant for
+// development; tracked sprites are never let go.
+func (s *SpriteFactory) TrackSprites() {
+	s.track = true
+}
+
+// Reload : Re-read the sheet and sprite manifests and any Aseprite exports, drop cached sheets,
+// and rebuild every tracked sprite in place, keeping its current animation mode and transform. If
+// anything fails to load, the factory, its cache and sprites are left as they were.
+func (s *SpriteFactory) Reload(fsys fs.FS, sheetManifest, spriteManifest string) error {
+	fresh, err := NewSpriteFactoryFromManifests(fsys, sheetManifest, spriteManifest, nil)
+	if err != nil {
+		return err
+	}
+	for _, pattern := range s.asepritePatterns {
+		if err = fresh.AddAseprite(fsys, pattern); err != nil {
+			return err
+		}
+	}
+	// Build everything from the fresh sheets before touching live sprites or the shared cache, so
+	// a bad sheet doesn't leave them half done. Recolored sheets are made again from the fresh ones.
+	sheets := fresh.sourceImageGetter
+	variants := s.variants
+	s.variants = nil
+	rebuilt := make([]map[AnimationMode]Animation, len(s.tracked))
+	for i, sprite := range s.tracked {
+		meta, err := fresh.spriteMetaGetter.GetSpriteMeta(sprite.id)
+		if err != nil {
+			s.variants = variants
+			return err
+		}
+		if rebuilt[i], err = s.getAnimations(sheets, meta.Anims, meta.Palette); err != nil {
+			s.variants = variants
+			return err
+		}
+	}
+	if s.cache != nil {
+		cached := NewCachedSheets(sheets, s.cache)
+		for _, id := range sheets.(sheetLister).sheetIDs() {
+			cached.InvalidateSheet(id)
+		}
+		if old, ok := s.sourceImageGetter.(*CachedSheets); ok {
+			if lister, ok := old.source.(sheetLister); ok {
+				for _, id := range lister.sheetIDs() {
+					cached.InvalidateSheet(id)
+				}
+			}
+		}
+		for key := range s.sharedVariants {
+			cached.invalidateVariant(key)
+		}
+		s.sharedVariants = nil
+		sheets = cached
+	}
+	s.sourceImageGetter = sheets
+	s.spriteMetaGetter = fresh.spriteMetaGetter
+	for i, sprite := range s.tracked {
+		sprite.rebuild(rebuilt[i])
+	}
+	return nil
+}
+
+// rebuild : Swap in new animations, carrying over the current mode and transform
+func (b *BasicSprite) rebuild(anims map[AnimationMode]Animation) {
+	b.registeredAnimations = anims
+	if _, ok := anims[b.mode]; !ok {
+		// The mode went away; keep showing the old frames rather than nothing
+		return
+	}
+	_, _ = b.play(b.mode)
+}
@@ -1,10 +1,15 @@
 package render
 
 import (
+	"bytes"
 	"encoding/csv"
 	"fmt"
+	"io/fs"
 	"math"
 
+	"github.com/jessdwitch/spiders/engine/assets"
+	"github.com/jessdwitch/spiders/engine/render/spritedata"
+
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
@@ -23,6 +28,19 @@ type (
 		FrameDelay int
 		// FrameDelayMax : how long should we stay on any given frame?
 		FrameDelayMax int
+		// Durations : how long to stay on each frame, if they differ. Overrides FrameDelayMax.
+		Durations []int
+		// Origins : each frame's pivot, if any are set. The pivot is drawn at the transform's
+		//	origin.
+		Origins []Point
+		// Playback : what happens at the end of the frames
+		Playback PlaybackMode
+		// Markers : named frames, as in the hit frame of an attack, by index in Frames
+		Markers map[int]string
+		// direction : which way a ping-pong is going; 1 forward, -1 back
+		direction int
+		// done : a PlayOnce or PlayHoldLast animation reached its end
+		done bool
 	}
 	// AnimationMode : An identifier for an animation registered to an Animatable.
 	AnimationMode string
@@ -33,12 +51,29 @@ type (
 		Start      int
 		NFrames    int
 		FrameDelay int
+		// Durations : Optional per-frame delays, overriding FrameDelay and the sheet's durations
+		Durations []int
+		// Sequence : Optional sheet frame indices, in play order. Overrides Start and NFrames.
+		Sequence []int
+		// Playback : What happens at the end of the frames
+		Playback PlaybackMode
+		// Markers : Named frames, by index into the animation's frames
+		Markers map[int]string
 	}
 	// BasicSprite : A collection of ready-to-render animations
 	BasicSprite struct {
 		*Animation
 		// registeredAnimations : animations available to this sprite
 		registeredAnimations map[AnimationMode]Animation
+		// id : the sprite this was made from
+		id SpriteID
+		// mode : the current animation
+		mode AnimationMode
+		// queue : animations to play as each one ends
+		queue []AnimationMode
+		// resume : the looping animation to go back to after a PlayOnce one, if nothing is queued
+		resume    AnimationMode
+		listeners []func(AnimationEvent)
 	}
 	// Point : A rank-2 vector
 	Point struct {
@@ -51,6 +86,19 @@ type (
 	SpriteFactory struct {
 		sourceImageGetter SpriteSheetGetter
 		spriteMetaGetter  SpriteMetaGetter
+		// cache : shared sheet cache, if built from manifests with one
+		cache *assets.Manager
+		// tracked : sprites to rebuild on Reload. Only kept when tracking is on.
+		tracked []*BasicSprite
+		track   bool
+		// asepritePatterns : exports added with AddAseprite, to re-read on Reload
+		asepritePatterns []string
+		// shaders : for recoloring sheets with palettes
+		shaders ShaderLibrary
+		// variants : sheets already recolored, when they aren't shared through cache
+		variants map[paletteVariant]*SpriteSheet
+		// sharedVariants : recolored sheets which have been put in cache, to drop on Reload
+		sharedVariants map[paletteVariant]bool
 	}
 	// Animator : Triggers a registered animation. Returns the number of frames in a loop
 	Animator interface {
@@ -58,6 +106,10 @@ type (
 		Animate(AnimationMode) (int, error)
 		// SetDelay : Adjust the frame delay. Returns the new number of ticks per cycle
 		SetDelay(int) (int, error)
+		// Queue : Play an animation once the current one ends, after any already queued
+		Queue(AnimationMode) error
+		// OnAnimationEvent : Call f as animations finish, loop, or reach marked frames
+		OnAnimationEvent(f func(AnimationEvent))
 	}
 	// Drawer : Able to hook into the game engine's animation loop
 	Drawer interface {
@@ -68,13 +120,38 @@ type (
 	Sprite interface {
 		Animator
 		Transformer
+		Colorer
+		Effector
 		Drawer
 	}
+	// Effector : Draws through a shader effect
+	Effector interface {
+		// SetEffect : Draw through an effect, or normally if nil
+		SetEffect(e *Effect)
+		// GetEffect : The effect drawn through, if any
+		GetEffect() *Effect
+	}
+	// Colorer : Tints and fades a drawable
+	Colorer interface {
+		// ColorScale : The current color scales. All 1 draws the image as is.
+		ColorScale() (r, g, b, a float64)
+		// SetColorScale : Multiply each channel by a factor when drawn
+		SetColorScale(r, g, b, a float64)
+		// Alpha : The current opacity, from 0 to 1
+		Alpha() float64
+		// SetAlpha : Set the opacity, from 0 to 1
+		SetAlpha(a float64)
+	}
 	// SpriteGetter : Give me a Sprite!
 	SpriteGetter interface {
 		// GetSprite : Get a drawable, animatable, transformable Sprite
 		GetSprite(spriteID SpriteID) (Sprite, error)
 	}
+	// SpriteReleaser : A SpriteGetter whose sprites hold on to shared assets until released
+	SpriteReleaser interface {
+		// Release : Let go of what one GetSprite for id took
+		Release(spriteID SpriteID) error
+	}
 	// SpriteMetaGetter : Provides metadata for Sprite retrieval
 	SpriteMetaGetter interface {
 		// GetSpriteMeta : Get Sprite metadata from an ID
@@ -82,23 +159,35 @@ type (
 	}
 	// Tile : Static drawable
 	Tile struct {
+		*Transform
 		// image : the thing to render
-		*ebiten.GeoM
-		image    *ebiten.Image
-		position Point
+		image *ebiten.Image
+		// origin : offset of the current frame's pivot within image
+		origin Point
+		// colorScale : r, g, b, a factors applied when drawn
+		colorScale [4]float64
+		// effect : shader drawn through, if any
+		effect *Effect
 	}
-	// Transformer : A handle for modifying scale, location, and rotation. Can be used as a wrapper for ebiten.GeoM
+	// Transformer : A handle for modifying scale, location, rotation, and skew. See Transform.
 	Transformer interface {
-		// Scale : Rescale the transform to the given dimensions
+		// Scale : Multiply the scale by factors, about the origin
 		Scale(x, y float64)
-		// Translate : Move the transform to a new position
+		// Translate : Move the transform by an offset
 		Translate(x, y float64)
-		// Skew : Like rotation, but not!
-		// Skew() error // TODO:
-		// Rotate : Rotate the transform about the center
-		// Rotate() error // TODO: Units? Degrees or radians?
-
-		// GetPosition : Get the current top-left pixel of the transform
+		// SetPosition : Move the transform to a position, relative to its parent
+		SetPosition(x, y float64)
+		// Skew : Shear along each axis by angles in radians, about the origin
+		Skew(x, y float64)
+		// Rotate : Rotate about the origin by theta radians, clockwise on screen
+		Rotate(theta float64)
+		// SetOrigin : Set the pivot, in untransformed pixels from the top-left
+		SetOrigin(x, y float64)
+		// SetParent : Follow a parent transform, or nil to stop following
+		SetParent(parent *Transform) error
+		// GetTransform : The underlying transform, as in to parent something to this
+		GetTransform() *Transform
+		// GetPosition : Get where the origin is, relative to the parent
 		GetPosition() Point
 	}
 )
@@ -114,17 +203,49 @@ func NewSpriteFactory(a SpriteSheetGetter, s SpriteMetaGetter) (*SpriteFactory,
 	}, nil
 }
 
-// NewSpriteFactoryFromManifests : Create a new Sprite generator with simple args
-func NewSpriteFactoryFromManifests(sheetManifest, spriteManifest *csv.Reader) (*SpriteFactory, error) {
-	sheetManager, err := NewSpriteSheetFiles(sheetManifest)
+// NewSpriteFactoryFromManifests : Create a new Sprite generator from the sheet and sprite
+// manifests at the given paths in fsys. The sheet manifest may be a grid sheet manifest or an
+// atlas manifest from cmd/atlaspack. If cache is non-nil, decoded sheets are shared through it.
+func NewSpriteFactoryFromManifests(
+	fsys fs.FS,
+	sheetManifest, spriteManifest string,
+	cache *assets.Manager,
+) (*SpriteFactory, error) {
+	sheetManager, err := loadSheetManifest(fsys, sheetManifest)
+	if err != nil {
+		return nil, err
+	}
+	spriteF, err := fsys.Open(spriteManifest)
 	if err != nil {
 		return nil, err
 	}
-	spriteMetaManager, err := NewSpriteMetaManager(spriteManifest)
+	defer spriteF.Close()
+	spriteMetaManager, err := NewSpriteMetaManager(csv.NewReader(spriteF))
 	if err != nil {
 		return nil, err
 	}
-	return NewSpriteFactory(sheetManager, spriteMetaManager)
+	if cache == nil {
+		return NewSpriteFactory(sheetManager, spriteMetaManager)
+	}
+	factory, err := NewSpriteFactory(NewCachedSheets(sheetManager, cache), spriteMetaManager)
+	if err != nil {
+		return nil, err
+	}
+	factory.cache = cache
+	return factory, nil
+}
+
+// loadSheetManifest : Read a grid or atlas sheet manifest, telling them apart by their header
+func loadSheetManifest(fsys fs.FS, manifest string) (SpriteSheetGetter, error) {
+	b, err := fs.ReadFile(fsys, manifest)
+	if err != nil {
+		return nil, err
+	}
+	header, err := csv.NewReader(bytes.NewReader(b)).Read()
+	if err == nil && len(header) >= spritedata.AtlasManifestColumns {
+		return NewAtlasFiles(fsys, csv.NewReader(bytes.NewReader(b)))
+	}
+	return NewSpriteSheetFiles(fsys, csv.NewReader(bytes.NewReader(b)))
 }
 
 // NewTile : Make a new fixed image renderable. Optionally, takes exactly 2 position args (x,y)
@@ -133,50 +254,82 @@ func NewTile(i *ebiten.Image, position ...float64) Tile {
 	if len(position) == 2 {
 		p = Point{position[0], position[1]}
 	}
-	return Tile{GeoM: &ebiten.GeoM{}, image: i, position: p}
+	return Tile{Transform: NewTransform(p.X, p.Y), image: i, colorScale: [4]float64{1, 1, 1, 1}}
 }
 
 // Update : Hook for the engine's tick function
 func (a *Animation) Update() error {
-	if len(a.Frames) == 1 {
-		a.image = a.Frames[0]
-		return nil
+	a.advance()
+	return nil
+}
+
+// delay : How long to hold frame i
+func (a *Animation) delay(i int) int {
+	if a.Durations != nil {
+		return a.Durations[i]
 	}
-	a.FrameDelay--
-	if a.FrameDelay < 0 {
-		a.FrameDelay = a.FrameDelayMax
-		a.CurrentFrame++
-		if a.CurrentFrame >= len(a.Frames) {
-			a.CurrentFrame = 0
+	return a.FrameDelayMax
+}
+
+// cycleTicks : Ticks in one pass through the animation. A ping-pong pass goes there and back.
+func (a *Animation) cycleTicks() int {
+	total := 0
+	for i := range a.Frames {
+		total += a.delay(i)
+		if a.Playback == PlayPingPong && i > 0 && i < len(a.Frames)-1 {
+			total += a.delay(i)
 		}
 	}
-	a.image = a.Frames[a.CurrentFrame]
-	return nil
+	return total
+}
+
+func (a *Animation) show(i int) {
+	a.image = a.Frames[i]
+	a.origin = Point{}
+	if a.Origins != nil {
+		a.origin = a.Origins[i]
+	}
 }
 
-// Animate : Switch to a new Animation
+// Animate : Switch to a new Animation, dropping any queued ones
 func (b *BasicSprite) Animate(mode AnimationMode) (int, error) {
+	b.queue = nil
+	return b.play(mode)
+}
+
+func (b *BasicSprite) play(mode AnimationMode) (int, error) {
 	anim, ok := b.registeredAnimations[mode]
 	if !ok {
 		return 0, fmt.Errorf("animation %v could not be found for sprite %v", mode, b)
 	}
 	anim.CurrentFrame = 0
-	anim.FrameDelay = anim.FrameDelayMax
+	anim.FrameDelay = anim.delay(0)
+	anim.direction = 1
+	anim.done = false
+	// Keep the sprite's tile, so its transform and color carry over
 	if b.Animation != nil && b.Tile != nil {
-		anim.GeoM = b.GeoM
+		anim.Tile = b.Tile
 	}
 	b.Animation = &anim
-	return len(anim.Frames) * anim.FrameDelay, nil
+	b.mode = mode
+	if anim.Playback.Loops() {
+		b.resume = mode
+	}
+	b.enter()
+	return anim.cycleTicks(), nil
 }
 
-// SetDelay : Adjust the frame delay, and resets the animation cycle. Returns the new number of ticks per cycle.
+// SetDelay : Adjust the frame delay, and resets the animation cycle. Per-frame durations are
+// replaced by the new, uniform delay. Returns the new number of ticks per cycle.
 func (b *BasicSprite) SetDelay(newDelay int) (int, error) {
 	if newDelay < 0 {
 		return -1, fmt.Errorf("frame delay must be non-negative")
 	}
 	b.FrameDelayMax = newDelay
+	b.Durations = nil
 	b.CurrentFrame = 0
-	return len(b.Frames) * b.FrameDelayMax, nil
+	b.done = false
+	return b.cycleTicks(), nil
 }
 
 // Dist : The distance to the other Point.
@@ -184,32 +337,83 @@ func (p *Point) Dist(p2 Point) float64 {
 	return math.Sqrt(math.Pow(p.Y-p2.Y, 2) + math.Pow(p.X-p2.X, 2))
 }
 
-// AddVec : And a vector of magnitude mag, pointing towards dir
+// AddVec : Add a vector of magnitude mag, pointing towards dir
 func (p *Point) AddVec(mag float64, dir Point) Point {
-	scalar := mag / math.Sqrt(dir.X*dir.X+dir.Y*dir.Y)
-	return Point{(dir.X - p.X) * scalar, (dir.Y - p.Y) * scalar}
+	dist := p.Dist(dir)
+	if dist == 0 {
+		return *p
+	}
+	scalar := mag / dist
+	return Point{p.X + (dir.X-p.X)*scalar, p.Y + (dir.Y-p.Y)*scalar}
+}
+
+// Angle : The angle from p to dir in radians, clockwise on screen from the x axis
+func (p *Point) Angle(dir Point) float64 {
+	return math.Atan2(dir.Y-p.Y, dir.X-p.X)
 }
 
 // Lerp : Linear interpolation. Returns an Update hook that moves p to the given endpoint over the
-// given number of ticks
-func (p *Point) Lerp(Point, int) func() error {
-	panic("not implemented") // TODO: Implement
+// given number of ticks. For eased or combined motion, see the tween package.
+func (p *Point) Lerp(end Point, ticks int) func() error {
+	start := *p
+	tick := 0
 	return func() error {
+		if tick >= ticks {
+			*p = end
+			return nil
+		}
+		tick++
+		f := float64(tick) / float64(ticks)
+		p.X = start.X + (end.X-start.X)*f
+		p.Y = start.Y + (end.Y-start.Y)*f
 		return nil
 	}
 }
 
 // Draw : Engine Draw hook
 func (t *Tile) Draw(screen *ebiten.Image) {
-	op := &ebiten.DrawImageOptions{GeoM: *t.GeoM}
+	t.draw(screen, t.geoM())
+}
+
+func (t *Tile) draw(screen *ebiten.Image, g ebiten.GeoM) {
+	if t.effect != nil && t.effect.Enabled() {
+		t.effect.DrawImage(screen, t.image, g, t.colorScale)
+		return
+	}
+	op := &ebiten.DrawImageOptions{GeoM: g}
+	op.ColorM.Scale(t.colorScale[0], t.colorScale[1], t.colorScale[2], t.colorScale[3])
 	screen.DrawImage(t.image, op)
 }
 
+// geoM : Where the current image is drawn, pivoting about the frame's origin
+func (t *Tile) geoM() ebiten.GeoM {
+	g := ebiten.GeoM{}
+	g.Translate(-t.origin.X, -t.origin.Y)
+	g.Concat(t.Transform.GeoM())
+	return g
+}
+
 // Update : Engine Update hook
 func (t *Tile) Update() error { return nil }
 
-func (t *Tile) GetPosition() Point {
-	return t.position
+// ColorScale : The current color scales. All 1 draws the image as is.
+func (t *Tile) ColorScale() (r, g, b, a float64) {
+	return t.colorScale[0], t.colorScale[1], t.colorScale[2], t.colorScale[3]
+}
+
+// SetColorScale : Multiply each channel by a factor when drawn
+func (t *Tile) SetColorScale(r, g, b, a float64) {
+	t.colorScale = [4]float64{r, g, b, a}
+}
+
+// Alpha : The current opacity, from 0 to 1
+func (t *Tile) Alpha() float64 {
+	return t.colorScale[3]
+}
+
+// SetAlpha : Set the opacity, from 0 to 1
+func (t *Tile) SetAlpha(a float64) {
+	t.colorScale[3] = a
 }
 
 // GetSprite : Get a Sprite using the provided SpriteMetaGetter and AnimationGetter
@@ -218,7 +422,7 @@ func (s *SpriteFactory) GetSprite(id SpriteID) (Sprite, error) {
 	if err != nil {
 		return nil, err
 	}
-	anims, err := s.GetAnimations(s.sourceImageGetter, meta.Anims)
+	anims, err := s.getAnimations(s.sourceImageGetter, meta.Anims, meta.Palette)
 	if err != nil {
 		return nil, err
 	}
@@ -228,34 +432,76 @@ func (s *SpriteFactory) GetSprite(id SpriteID) (Sprite, error) {
 			Tile: &t,
 		},
 		registeredAnimations: anims,
+		id:                   id,
+		mode:                 NoAnimation,
+	}
+	if s.track {
+		s.tracked = append(s.tracked, result)
 	}
 	return result, nil
 }
 
 // GetAnimations : Retrieve animations from sprite sheets
 func (s *SpriteFactory) GetAnimations(source SpriteSheetGetter, metas []AnimMeta) (map[AnimationMode]Animation, error) {
+	return s.getAnimations(source, metas, Palette{})
+}
+
+// getAnimations : As GetAnimations, recoloring the sheets with a palette
+func (s *SpriteFactory) getAnimations(
+	source SpriteSheetGetter,
+	metas []AnimMeta,
+	palette Palette,
+) (map[AnimationMode]Animation, error) {
 	batches := map[SourceImageID][]AnimMeta{}
 	for _, meta := range metas {
-		if batch, ok := batches[meta.Source]; ok {
-			batch = append(batch, meta)
-			continue
-		}
-		batches[meta.Source] = []AnimMeta{meta}
+		batches[meta.Source] = append(batches[meta.Source], meta)
 	}
 	result := map[AnimationMode]Animation{}
+	taken := []SourceImageID{}
+	recolored := []paletteVariant{}
+	fail := func(err error) (map[AnimationMode]Animation, error) {
+		releaseSheets(source, taken, recolored)
+		return nil, err
+	}
 	for sheetID, metas := range batches {
 		// RFE: Is it worthwhile to use sync map writing to parallelize this?
 		sheet, err := source.GetSpriteSheet(sheetID)
 		if err != nil {
-			return nil, err
+			return fail(err)
+		}
+		taken = append(taken, sheetID)
+		if !palette.IsZero() {
+			var shared bool
+			if sheet, shared, err = s.recolor(source, sheetID, sheet, palette); err != nil {
+				return fail(err)
+			}
+			if shared {
+				recolored = append(recolored, paletteVariant{sheetID, palette.String()})
+			}
 		}
 		for _, meta := range metas {
 			anim, err := sheet.ExtractAnimation(meta)
 			if err != nil {
-				return nil, err
+				return fail(err)
 			}
 			result[meta.Mode] = anim
 		}
 	}
 	return result, nil
 }
+
+// releaseSheets : Give back the references a failed getAnimations took, if source counts them
+func releaseSheets(source SpriteSheetGetter, ids []SourceImageID, variants []paletteVariant) {
+	cache, ok := source.(SheetCache)
+	if !ok {
+		return
+	}
+	for _, id := range ids {
+		cache.ReleaseSheet(id)
+	}
+	if cached, ok := cache.(*CachedSheets); ok {
+		for _, v := range variants {
+			cached.releaseVariant(v)
+		}
+	}
+}
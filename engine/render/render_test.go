@@ -3,11 +3,16 @@ package render_test
 import (
 	"bytes"
 	"encoding/csv"
+	"errors"
 	"image"
+	"image/color"
+	"math"
 	"strings"
 	"testing"
+	"testing/fstest"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/jessdwitch/spiders/engine/assets"
 	"github.com/jessdwitch/spiders/engine/render"
 	"github.com/jessdwitch/spiders/engine/render/testdata"
 
@@ -38,6 +43,174 @@ func TestExtractAnimation(t *testing.T) {
 	assert.Equal(t, render.Point{0, 0}, anim.GetPosition())
 }
 
+func TestFrameDurations(t *testing.T) {
+	tile := render.NewTile(nil)
+	anim := render.Animation{
+		Tile:      &tile,
+		Frames:    make([]*ebiten.Image, 3),
+		Durations: []int{0, 2, 1},
+	}
+	frames := []int{}
+	for i := 0; i < 8; i++ {
+		assert.NoError(t, anim.Update())
+		frames = append(frames, anim.CurrentFrame)
+	}
+	// Each frame is held one tick longer than its duration, as with FrameDelayMax
+	assert.Equal(t, []int{1, 1, 1, 2, 2, 0, 1, 1}, frames)
+}
+
+func TestExtractAnimationFrames(t *testing.T) {
+	img, _, err := image.Decode(bytes.NewReader(testdata.Test_sprite_png))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &render.SpriteSheet{
+		SourceImage: ebiten.NewImageFromImage(img),
+		SheetID:     sampleSheetID,
+		Frames: []render.SheetFrame{
+			{Rect: image.Rect(0, 0, 51, 54), Duration: 3},
+			{Rect: image.Rect(51, 0, 80, 20), Origin: image.Pt(10, 20)},
+		},
+	}
+	anim, err := s.ExtractAnimation(render.AnimMeta{NFrames: 2, FrameDelay: 5})
+	assert.NoError(t, err)
+	w, h := anim.Frames[1].Size()
+	assert.Equal(t, []int{29, 20}, []int{w, h})
+	assert.Equal(t, []int{3, 5}, anim.Durations)
+	assert.Equal(t, []render.Point{{0, 0}, {10, 20}}, anim.Origins)
+
+	anim, err = s.ExtractAnimation(render.AnimMeta{NFrames: 2, FrameDelay: 5, Durations: []int{1, 2}})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, anim.Durations)
+	_, err = s.ExtractAnimation(render.AnimMeta{NFrames: 2, Durations: []int{1}})
+	assert.Error(t, err)
+
+	// Grid frames are row-major, whatever the sheet's shape
+	grid := &render.SpriteSheet{
+		SourceImage: ebiten.NewImageFromImage(img),
+		TileDim:     image.Pt(51, 27),
+		NTiles:      image.Pt(4, 2),
+	}
+	anim, err = grid.ExtractAnimation(render.AnimMeta{Start: 5, NFrames: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(51, 27, 102, 54), anim.Frames[0].Bounds())
+	assert.Nil(t, anim.Durations)
+	assert.Nil(t, anim.Origins)
+}
+
+func TestSpriteManifestDelays(t *testing.T) {
+	metas, err := render.NewSpriteMetaManager(csv.NewReader(strings.NewReader(
+		"name,sheet,mode,start,nFrames,dimX,dimY,delay\n" +
+			"slime1,slimes1,idle,0,4,51,54,2\n" +
+			"slime1,slimes1,hop,0,3,51,54,1;4;1\n")))
+	assert.NoError(t, err)
+	meta, err := metas.GetSpriteMeta(sampleSpriteID)
+	assert.NoError(t, err)
+	assert.Nil(t, meta.Anims[0].Durations)
+	assert.Equal(t, 1, meta.Anims[1].FrameDelay)
+	assert.Equal(t, []int{1, 4, 1}, meta.Anims[1].Durations)
+
+	_, err = render.NewSpriteMetaManager(csv.NewReader(strings.NewReader(
+		"name,sheet,mode,start,nFrames,dimX,dimY,delay\nslime1,slimes1,idle,0,4,51,54,1;2\n")))
+	assert.Error(t, err)
+}
+
+func TestLoadAseprite(t *testing.T) {
+	fsys := fstest.MapFS{
+		"ase/slime.json":      {Data: []byte(sampleAsepriteHash)},
+		"ase/test_sprite.png": {Data: testdata.Test_sprite_png},
+	}
+	ase, err := render.LoadAseprite(fsys, "ase/*.json")
+	assert.NoError(t, err)
+	meta, err := ase.GetSpriteMeta("slime")
+	assert.NoError(t, err)
+	assert.Equal(t, render.Point{51, 54}, meta.InitialDims)
+	assert.Len(t, meta.Anims, 2)
+	hop := meta.Anims[1]
+	assert.Equal(t, render.AnimationMode("hop"), hop.Mode)
+	assert.Equal(t, []int{1, 2, 3}, hop.Sequence)
+	assert.Equal(t, render.PlayPingPong, hop.Playback)
+	// 100ms is 6 ticks, which is a delay of 5; 50ms is 3 ticks
+	assert.Equal(t, []int{5, 2, 5}, hop.Durations)
+	assert.Equal(t, map[int]string{2: "land"}, hop.Markers)
+
+	sheet, err := ase.GetSpriteSheet("slime")
+	assert.NoError(t, err)
+	anim, err := sheet.ExtractAnimation(hop)
+	assert.NoError(t, err)
+	assert.Len(t, anim.Frames, 3)
+	// Frame 2 is trimmed 3px from the left of its canvas
+	assert.Equal(t, render.Point{-3, 0}, anim.Origins[1])
+
+	factory, err := render.NewSpriteFactoryFromManifests(sampleFS, "sheets.csv", "sprites.csv", nil)
+	assert.NoError(t, err)
+	assert.NoError(t, factory.AddAseprite(fsys, "ase/*.json"))
+	_, err = factory.GetSprite("slime")
+	assert.NoError(t, err)
+	_, err = factory.GetSprite(sampleSpriteID)
+	assert.NoError(t, err)
+	_, err = factory.GetSprite("ghost")
+	assert.True(t, errors.Is(err, render.ErrSpriteNotFound))
+}
+
+func TestPingPong(t *testing.T) {
+	tile := render.NewTile(nil)
+	anim := render.Animation{
+		Tile:     &tile,
+		Frames:   make([]*ebiten.Image, 3),
+		Playback: render.PlayPingPong,
+	}
+	frames := []int{}
+	for i := 0; i < 6; i++ {
+		assert.NoError(t, anim.Update())
+		frames = append(frames, anim.CurrentFrame)
+	}
+	assert.Equal(t, []int{1, 2, 1, 0, 1, 2}, frames)
+}
+
+func TestPlayback(t *testing.T) {
+	fsys := fstest.MapFS{
+		"sheets.csv": {Data: []byte(sampleSheetManifest)},
+		"sprites.csv": {Data: []byte(`name,sheet,mode,start,nFrames,dimX,dimY,delay,playback,markers
+slime1,slimes1,idle,0,2,51,54,0,loop,
+slime1,slimes1,attack,0,3,51,54,0,once,hit@1
+slime1,slimes1,die,2,2,51,54,0,hold,`)},
+		"test_sprite.png": {Data: testdata.Test_sprite_png},
+	}
+	factory, err := render.NewSpriteFactoryFromManifests(fsys, "sheets.csv", "sprites.csv", nil)
+	assert.NoError(t, err)
+	sprite, err := factory.GetSprite(sampleSpriteID)
+	assert.NoError(t, err)
+	events := []render.AnimationEvent{}
+	sprite.OnAnimationEvent(func(e render.AnimationEvent) { events = append(events, e) })
+	_, err = sprite.Animate("idle")
+	assert.NoError(t, err)
+	ticks, err := sprite.Animate("attack")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, ticks)
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, sprite.Update())
+	}
+	// The attack hit on its second frame, finished, and went back to idle
+	assert.Equal(t, []render.AnimationEvent{
+		{Kind: render.AnimationMarker, Mode: "attack", Marker: "hit", Frame: 1},
+		{Kind: render.AnimationFinished, Mode: "attack", Frame: 2},
+	}, events)
+
+	events = events[:0]
+	assert.NoError(t, sprite.Queue("die"))
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, sprite.Update())
+	}
+	assert.Equal(t, []render.AnimationEvent{
+		{Kind: render.AnimationLooped, Mode: "idle", Frame: 0},
+		{Kind: render.AnimationFinished, Mode: "die", Frame: 1},
+	}, events)
+	// Held on the last frame of die
+	assert.Equal(t, 1, sprite.(*render.BasicSprite).CurrentFrame)
+	assert.Error(t, sprite.Queue("dance"))
+}
+
 // func TestSpriteSheetFiles(t *testing.T) {
 // 	s, err := render.NewSpriteSheetFiles(csv.NewReader(strings.NewReader(sampleSheetManifest)))
 // 	assert.NoError(t, err)
@@ -53,10 +226,7 @@ func TestExtractAnimation(t *testing.T) {
 // }
 
 func TestNewSpriteFactoryFromManifests(t *testing.T) {
-	factory, err := render.NewSpriteFactoryFromManifests(
-		csv.NewReader(strings.NewReader(sampleSheetManifest)),
-		csv.NewReader(strings.NewReader(sampleSpriteManifest)),
-	)
+	factory, err := render.NewSpriteFactoryFromManifests(sampleFS, "sheets.csv", "sprites.csv", nil)
 	assert.NoError(t, err)
 	sprite, err := factory.GetSprite("slime1")
 	assert.NoError(t, err)
@@ -64,6 +234,72 @@ func TestNewSpriteFactoryFromManifests(t *testing.T) {
 	// TODO: I spot-checked the sprite with `t.Fatal(sprite)`, but should add real tests
 }
 
+func TestAtlasFiles(t *testing.T) {
+	factory, err := render.NewSpriteFactoryFromManifests(sampleFS, "atlas.csv", "sprites.csv", nil)
+	assert.NoError(t, err)
+	sprite, err := factory.GetSprite("slime1")
+	assert.NoError(t, err)
+	assert.NotNil(t, sprite)
+
+	atlas, err := render.NewAtlasFiles(sampleFS, csv.NewReader(strings.NewReader(sampleAtlasManifest)))
+	assert.NoError(t, err)
+	sheet, err := atlas.GetSpriteSheet(sampleSheetID)
+	assert.NoError(t, err)
+	other, err := atlas.GetSpriteSheet("other")
+	assert.NoError(t, err)
+	// Sheets on the same page share its image
+	assert.Same(t, sheet.SourceImage, other.SourceImage)
+	anim, err := sheet.ExtractAnimation(sampleAnimMetas[0])
+	assert.NoError(t, err)
+	assert.Len(t, anim.Frames, 4)
+	w, h := anim.Frames[1].Size()
+	assert.Equal(t, []int{51, 54}, []int{w, h})
+	_, err = sheet.ExtractAnimation(render.AnimMeta{Source: sampleSheetID, Start: 3, NFrames: 2})
+	assert.Error(t, err)
+
+	_, err = render.NewAtlasFiles(sampleFS, csv.NewReader(strings.NewReader(
+		"sheet,page,frame,x,y,w,h\nslimes1,test_sprite.png,1,0,0,51,54")))
+	assert.Error(t, err, "frame 0 is missing")
+}
+
+func TestReload(t *testing.T) {
+	cache := assets.NewManager()
+	factory, err := render.NewSpriteFactoryFromManifests(sampleFS, "sheets.csv", "sprites.csv", cache)
+	assert.NoError(t, err)
+	factory.TrackSprites()
+	_, err = factory.GetSprite(sampleSpriteID)
+	assert.NoError(t, err)
+	assert.True(t, cache.Cached("sheet/"+sampleSheetID))
+
+	// More frames than the sheet has, so the tracked sprite can't be rebuilt
+	broken := fstest.MapFS{}
+	for name, f := range sampleFS {
+		broken[name] = f
+	}
+	broken["sprites.csv"] = &fstest.MapFile{Data: []byte(strings.Replace(sampleSpriteManifest, ",4,", ",9,", 1))}
+	assert.Error(t, factory.Reload(broken, "sheets.csv", "sprites.csv"))
+	assert.True(t, cache.Cached("sheet/"+sampleSheetID), "failed reload keeps the cache")
+
+	assert.NoError(t, factory.Reload(sampleFS, "sheets.csv", "sprites.csv"))
+	assert.False(t, cache.Cached("sheet/"+sampleSheetID), "reload drops stale sheets")
+}
+
+func TestGetSpriteReleasesOnError(t *testing.T) {
+	cache := assets.NewManager()
+	fsys := fstest.MapFS{}
+	for name, f := range sampleFS {
+		fsys[name] = f
+	}
+	// More frames than the sheet has, so the animation can't be cut from it
+	fsys["sprites.csv"] = &fstest.MapFile{Data: []byte(strings.Replace(sampleSpriteManifest, ",4,", ",9,", 1))}
+	factory, err := render.NewSpriteFactoryFromManifests(fsys, "sheets.csv", "sprites.csv", cache)
+	assert.NoError(t, err)
+	_, err = factory.GetSprite(sampleSpriteID)
+	assert.Error(t, err)
+	assert.Equal(t, 0, cache.Refs("sheet/"+sampleSheetID), "a failed sprite holds no sheets")
+	assert.Equal(t, 1, cache.Evict())
+}
+
 // func TestGetSprite(t *testing.T) {
 // 	var s render.SpriteGetter
 
@@ -74,14 +310,46 @@ func TestNewSpriteFactoryFromManifests(t *testing.T) {
 
 const (
 	sampleSheetManifest = `name,path,tileX,tileY,sheetX,sheetY
-slimes1,./testdata/test_sprite.png,51,54,204,54`
+slimes1,test_sprite.png,51,54,204,54`
 	sampleSpriteManifest = `name,sheet,mode,start,nFrames,dimX,dimY,delay
 slime1,slimes1,idle,0,4,51,54,2`
+	sampleAtlasManifest = `sheet,page,frame,x,y,w,h
+slimes1,test_sprite.png,0,0,0,51,54
+slimes1,test_sprite.png,1,51,0,51,54
+slimes1,test_sprite.png,2,102,0,51,54
+slimes1,test_sprite.png,3,153,0,51,54
+other,test_sprite.png,0,0,0,10,10`
+	sampleAsepriteHash = `{
+  "frames": {
+    "slime 0.aseprite": {"frame": {"x": 0, "y": 0, "w": 51, "h": 54}, "rotated": false,
+      "spriteSourceSize": {"x": 0, "y": 0, "w": 51, "h": 54}, "sourceSize": {"w": 51, "h": 54}, "duration": 100},
+    "slime 1.aseprite": {"frame": {"x": 51, "y": 0, "w": 51, "h": 54}, "rotated": false,
+      "spriteSourceSize": {"x": 0, "y": 0, "w": 51, "h": 54}, "sourceSize": {"w": 51, "h": 54}, "duration": 100},
+    "slime 2.aseprite": {"frame": {"x": 102, "y": 0, "w": 48, "h": 54}, "rotated": false,
+      "spriteSourceSize": {"x": 3, "y": 0, "w": 48, "h": 54}, "sourceSize": {"w": 51, "h": 54}, "duration": 50},
+    "slime 3.aseprite": {"frame": {"x": 153, "y": 0, "w": 51, "h": 54}, "rotated": false,
+      "spriteSourceSize": {"x": 0, "y": 0, "w": 51, "h": 54}, "sourceSize": {"w": 51, "h": 54}, "duration": 100}
+  },
+  "meta": {
+    "image": "test_sprite.png",
+    "frameTags": [
+      {"name": "idle", "from": 0, "to": 0, "direction": "forward"},
+      {"name": "hop", "from": 1, "to": 3, "direction": "pingpong"},
+      {"name": "@land", "from": 3, "to": 3, "direction": "forward"}
+    ]
+  }
+}`
 	sampleSpriteID = "slime1"
 	sampleSheetID  = "slimes1"
 )
 
 var (
+	sampleFS = fstest.MapFS{
+		"sheets.csv":      {Data: []byte(sampleSheetManifest)},
+		"atlas.csv":       {Data: []byte(sampleAtlasManifest)},
+		"sprites.csv":     {Data: []byte(sampleSpriteManifest)},
+		"test_sprite.png": {Data: testdata.Test_sprite_png},
+	}
 	sampleAnimMetas []render.AnimMeta = []render.AnimMeta{
 		{
 			Mode:       render.NoAnimation,
@@ -92,3 +360,293 @@ var (
 		},
 	}
 )
+
+func TestTransform(t *testing.T) {
+	tr := render.NewTransform(0, 0)
+	tr.SetOrigin(16, 16)
+	tr.Scale(2, 2)
+	tr.Translate(100, 50)
+	assert.Equal(t, render.Point{100, 50}, tr.GetPosition())
+	g := tr.GeoM()
+	// The origin is drawn at the position, and scaling happens about it
+	x, y := g.Apply(16, 16)
+	assert.InDelta(t, 100, x, 1e-9)
+	assert.InDelta(t, 50, y, 1e-9)
+	x, y = g.Apply(0, 0)
+	assert.InDelta(t, 68, x, 1e-9)
+	assert.InDelta(t, 18, y, 1e-9)
+
+	tr.Rotate(math.Pi / 2)
+	g = tr.GeoM()
+	x, y = g.Apply(32, 16)
+	assert.InDelta(t, 100, x, 1e-9)
+	assert.InDelta(t, 82, y, 1e-9)
+
+	// Children follow their parents
+	child := render.NewTransform(10, 0)
+	assert.NoError(t, child.SetParent(tr))
+	assert.InDelta(t, 132, child.WorldPosition().X, 1e-9)
+	assert.InDelta(t, 38, child.WorldPosition().Y, 1e-9)
+	tr.Translate(5, 0)
+	assert.InDelta(t, 137, child.WorldPosition().X, 1e-9)
+	assert.True(t, errors.Is(tr.SetParent(child), render.ErrTransformCycle))
+	assert.NoError(t, child.SetParent(nil))
+	assert.Equal(t, render.Point{10, 0}, child.WorldPosition())
+
+	// Sprites are transforms
+	tile := render.NewTile(nil, 3, 4)
+	assert.Equal(t, render.Point{3, 4}, tile.GetPosition())
+	tile.Translate(1, 1)
+	assert.Equal(t, render.Point{4, 5}, tile.GetPosition())
+}
+
+type boundedDrawer struct {
+	render.DrawFunc
+	bounds image.Rectangle
+}
+
+func (b boundedDrawer) Bounds() image.Rectangle { return b.bounds }
+
+func TestSceneGraph(t *testing.T) {
+	drawn := []string{}
+	drawer := func(name string) render.DrawFunc {
+		return func(*ebiten.Image) { drawn = append(drawn, name) }
+	}
+	g := &render.SceneGraph{}
+	g.Add(drawer("ui"), render.LayerUI, 0)
+	front := g.Add(drawer("front"), render.LayerPawns, 10)
+	g.Add(drawer("back"), render.LayerPawns, 0)
+	g.Add(drawer("background"), render.LayerBackground, 100)
+	g.Add(drawer("tied"), render.LayerPawns, 0)
+	hidden := g.Add(drawer("hidden"), render.LayerEffects, 0)
+	hidden.SetVisible(false)
+	g.Add(boundedDrawer{drawer("offscreen"), image.Rect(-20, -20, -10, -10)}, render.LayerEffects, 0)
+	g.Add(boundedDrawer{drawer("onscreen"), image.Rect(-5, -5, 5, 5)}, render.LayerEffects, 0)
+
+	screen := ebiten.NewImage(64, 64)
+	g.Draw(screen)
+	assert.Equal(t, []string{"background", "back", "tied", "front", "onscreen", "ui"}, drawn)
+
+	drawn = nil
+	front.SetZ(-1)
+	hidden.SetVisible(true)
+	g.Remove(front)
+	g.Draw(screen)
+	assert.Equal(t, []string{"background", "back", "tied", "hidden", "onscreen", "ui"}, drawn)
+	assert.Equal(t, 7, g.Len())
+
+	// Tiles cover their transformed image
+	tile := render.NewTile(ebiten.NewImage(10, 20), 30, 40)
+	tile.Scale(2, 1)
+	assert.Equal(t, image.Rect(30, 40, 50, 60), tile.Bounds())
+}
+
+func TestCamera(t *testing.T) {
+	c := render.NewCamera(200, 100)
+	// A fresh camera draws the world as is
+	assert.Equal(t, render.Point{30, 40}, c.WorldToScreen(render.Point{30, 40}))
+
+	c.SetZoom(2)
+	c.LookAt(50, 50)
+	assert.Equal(t, render.Point{100, 50}, c.WorldToScreen(render.Point{50, 50}))
+	assert.Equal(t, render.Point{120, 50}, c.WorldToScreen(render.Point{60, 50}))
+	assert.Equal(t, render.Point{60, 50}, c.ScreenToWorld(render.Point{120, 50}))
+	assert.Equal(t, image.Rect(0, 25, 100, 75), c.View())
+
+	// Bounds keep the view inside the world
+	c.SetBounds(image.Rect(0, 0, 400, 400))
+	c.LookAt(390, -10)
+	assert.Equal(t, render.Point{350, 25}, c.GetPosition())
+	c.Translate(-500, 0)
+	assert.Equal(t, render.Point{50, 25}, c.GetPosition())
+
+	// Following covers part of the distance each tick
+	c.ClearBounds()
+	c.LookAt(0, 0)
+	target := render.NewTransform(100, 0)
+	c.Follow(target, 0.5)
+	assert.NoError(t, c.Update())
+	assert.Equal(t, render.Point{50, 0}, c.GetPosition())
+	assert.NoError(t, c.Update())
+	assert.Equal(t, render.Point{75, 0}, c.GetPosition())
+
+	// Shakes stay within their magnitude and settle
+	c.Follow(nil, 0)
+	c.Shake(4, 3)
+	for c.Shaking() {
+		assert.NoError(t, c.Update())
+		p := c.WorldToScreen(c.GetPosition())
+		assert.InDelta(t, 100, p.X, 4)
+		assert.InDelta(t, 50, p.Y, 4)
+	}
+	assert.NoError(t, c.Update())
+	assert.Equal(t, render.Point{100, 50}, c.WorldToScreen(c.GetPosition()))
+}
+
+const sampleEmitters = `name,sheet,start,nFrames,rate,max,life,vx,vy,gravity,color,scale
+spark,fx,0,2,0.5,3,4,1,-2,0;1,#ffffff;#ff000000,1;0.5
+`
+
+func TestEmitterManifest(t *testing.T) {
+	metas, err := render.NewEmitterMetaManager(csv.NewReader(strings.NewReader(sampleEmitters)))
+	assert.NoError(t, err)
+	meta, err := metas.GetEmitterMeta("spark")
+	assert.NoError(t, err)
+	assert.Equal(t, render.EmitterMeta{
+		Source: "fx", Start: 0, NFrames: 2, Rate: 0.5, Max: 3,
+		Life:       render.Range{4, 4},
+		VelocityX:  render.Range{1, 1},
+		VelocityY:  render.Range{-2, -2},
+		Gravity:    render.Point{0, 1},
+		StartColor: [4]float64{1, 1, 1, 1},
+		EndColor:   [4]float64{1, 0, 0, 0},
+		StartScale: 1, EndScale: 0.5,
+	}, meta)
+	_, err = metas.GetEmitterMeta("smoke")
+	assert.True(t, errors.Is(err, render.ErrEmitterNotFound))
+
+	for _, bad := range []string{
+		"spark,fx,0,2,0.5,3,4,1,-2,0;1,#fff,1",
+		"spark,fx,0,2,0.5,3,0,1,-2,0;1,#ffffff,1",
+		"spark,fx,0,2,0.5,3,4,2;1,-2,0;1,#ffffff,1",
+		"spark,fx,0,2,0.5,3,4,1,-2,0;1,#ffffff",
+	} {
+		_, err := render.NewEmitterMetaManager(csv.NewReader(strings.NewReader("header\n" + bad)))
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestEmitter(t *testing.T) {
+	metas, err := render.NewEmitterMetaManager(csv.NewReader(strings.NewReader(sampleEmitters)))
+	assert.NoError(t, err)
+	meta, _ := metas.GetEmitterMeta("spark")
+	e := render.NewEmitter(meta, []*ebiten.Image{ebiten.NewImage(2, 2), ebiten.NewImage(2, 2)})
+	parent := render.NewTransform(100, 50)
+	e.SetPosition(0, 10)
+	assert.NoError(t, e.SetParent(parent))
+
+	// Half a particle a tick spawns one every other tick
+	e.Start()
+	counts := []int{}
+	for i := 0; i < 6; i++ {
+		assert.NoError(t, e.Update())
+		counts = append(counts, e.Live())
+	}
+	// Each lives 4 ticks
+	assert.Equal(t, []int{0, 1, 1, 2, 2, 2}, counts)
+
+	// The pool caps live particles
+	e.Burst(5)
+	assert.Equal(t, 3, e.Live())
+	e.Draw(ebiten.NewImage(200, 200))
+
+	// Updating doesn't allocate
+	assert.Equal(t, 0.0, testing.AllocsPerRun(10, func() { _ = e.Update() }))
+
+	e.Stop()
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, e.Update())
+	}
+	assert.True(t, e.Done())
+}
+
+func TestEffects(t *testing.T) {
+	shaders, err := render.NewShaderLibrary()
+	assert.NoError(t, err)
+	for _, id := range []render.ShaderID{
+		render.ShaderPaletteSwap, render.ShaderFlash, render.ShaderGreyscale,
+		render.ShaderVignette, render.ShaderCRT,
+	} {
+		e, err := shaders.NewEffect(id)
+		assert.NoError(t, err, id)
+		assert.Equal(t, 1.0, e.Amount(), id)
+	}
+	_, err = shaders.NewEffect("sepia")
+	assert.Error(t, err)
+
+	swap, _ := shaders.NewEffect(render.ShaderPaletteSwap)
+	assert.NoError(t, swap.SetPalette(
+		[]color.Color{color.White}, []color.Color{color.RGBA{0xff, 0, 0, 0xff}}))
+	assert.Error(t, swap.SetPalette([]color.Color{color.White}, nil))
+	assert.Error(t, swap.SetPalette(make([]color.Color, 9), make([]color.Color, 9)))
+
+	// Sprites can be drawn through effects
+	tile := render.NewTile(ebiten.NewImage(4, 4))
+	tile.SetEffect(swap)
+	assert.Equal(t, swap, tile.GetEffect())
+	tile.Draw(ebiten.NewImage(8, 8))
+
+	// A chain with nothing enabled passes the screen through
+	vignette, _ := shaders.NewEffect(render.ShaderVignette)
+	crt, _ := shaders.NewEffect(render.ShaderCRT)
+	post := render.NewPostProcess(vignette, crt)
+	assert.Equal(t, crt, post.Get(render.ShaderCRT))
+	assert.Nil(t, post.Get(render.ShaderFlash))
+	screen := ebiten.NewImage(16, 16)
+	vignette.SetEnabled(false)
+	crt.SetEnabled(false)
+	assert.Equal(t, screen, post.Apply(screen))
+	crt.SetEnabled(true)
+	out := post.Apply(screen)
+	assert.NotEqual(t, screen, out)
+	assert.Equal(t, screen.Bounds().Size(), out.Bounds().Size())
+}
+
+func TestSpriteManifestPalette(t *testing.T) {
+	metas, err := render.NewSpriteMetaManager(csv.NewReader(strings.NewReader(
+		"name,sheet,mode,start,nFrames,dimX,dimY,delay,playback,markers,palette\n" +
+			"red,slimes,idle,0,4,32,32,5,,,#3e8948>#e43b44;#63c74d>#F6757A\n" +
+			"red,slimes,die,0,4,32,32,5,hold,,\n")))
+	assert.NoError(t, err)
+	meta, err := metas.GetSpriteMeta("red")
+	assert.NoError(t, err)
+	assert.Len(t, meta.Anims, 2)
+	assert.Equal(t, "#3e8948>#e43b44;#63c74d>#f6757a", meta.Palette.String())
+	assert.Equal(t, color.NRGBA{0xe4, 0x3b, 0x44, 0xff}, meta.Palette.To[0])
+
+	for _, bad := range []string{
+		"#3e8948",
+		"#3e8948>#e43b4480",
+		"#3e8948>red",
+		strings.Repeat("#000000>#ffffff;", 8) + "#000000>#ffffff",
+	} {
+		_, err := render.ParsePalette(bad)
+		assert.Error(t, err, bad)
+	}
+	_, err = render.NewSpriteMetaManager(csv.NewReader(strings.NewReader(
+		"name,sheet,mode,start,nFrames,dimX,dimY,delay,playback,markers,palette\n" +
+			"red,slimes,idle,0,4,32,32,5,,,#3e8948>#e43b44\n" +
+			"red,slimes,die,0,4,32,32,5,hold,,#3e8948>#000000\n")))
+	assert.Error(t, err)
+
+	// Sheets are recolored as sprites load. Without shaders, sprites keep the sheet's colors.
+	fsys := fstest.MapFS{
+		"sheets.csv":      sampleFS["sheets.csv"],
+		"test_sprite.png": sampleFS["test_sprite.png"],
+		"sprites.csv": {Data: []byte(
+			"name,sheet,mode,start,nFrames,dimX,dimY,delay,playback,markers,palette\n" +
+				"red," + sampleSheetID + ",idle,0,4,51,54,5,,,#3e8948>#e43b44\n")},
+	}
+	factory, err := render.NewSpriteFactoryFromManifests(fsys, "sheets.csv", "sprites.csv", nil)
+	assert.NoError(t, err)
+	_, err = factory.GetSprite("red")
+	assert.NoError(t, err)
+	shaders, err := render.NewShaderLibrary()
+	assert.NoError(t, err)
+	factory.SetShaders(shaders)
+	sprite, err := factory.GetSprite("red")
+	assert.NoError(t, err)
+	_, err = sprite.Animate("idle")
+	assert.NoError(t, err)
+
+	// Shared recolored sheets are let go of along with their sheet
+	cache := assets.NewManager()
+	factory, err = render.NewSpriteFactoryFromManifests(fsys, "sheets.csv", "sprites.csv", cache)
+	assert.NoError(t, err)
+	factory.SetShaders(shaders)
+	_, err = factory.GetSprite("red")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, cache.Evict())
+	assert.NoError(t, factory.Release("red"))
+	assert.Equal(t, 2, cache.Evict(), "the sheet and its recolored copy")
+}
@@ -0,0 +1,220 @@
+package render
+
+import (
+	"image"
+	"math"
+	"sort"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Layers, drawn in this order. Within a layer, lower Z draws first. Every layer but the UI is in
+// the world, and drawn through the graph's camera if it has one.
+const (
+	LayerBackground Layer = iota
+	LayerPawns
+	LayerEffects
+	LayerUI
+)
+
+type (
+	// Layer : A band of nodes drawn together, as in everything in the UI drawing over every pawn
+	Layer int
+	// SceneGraph : Draws a scene's nodes back to front, skipping hidden and off-screen ones
+	SceneGraph struct {
+		nodes []*Node
+		// dirty : nodes need sorting before the next draw
+		dirty bool
+		// added : how many nodes have ever been added, to keep ties in the order they were added
+		added int
+		// camera : the view world layers are drawn through, if any
+		camera *Camera
+	}
+	// Node : Something drawn by a SceneGraph
+	Node struct {
+		Drawer
+		graph  *SceneGraph
+		layer  Layer
+		z      int
+		hidden bool
+		order  int
+	}
+	// Bounder : Knows where it draws on screen. Nodes that don't are never culled.
+	Bounder interface {
+		// Bounds : The area the next Draw covers, before any camera
+		Bounds() image.Rectangle
+	}
+	// DrawFunc : A Drawer with no state of its own, as in text or a UI widget drawn by its scene
+	DrawFunc func(screen *ebiten.Image)
+)
+
+// Add : Start drawing d on a layer, at depth z within it
+func (g *SceneGraph) Add(d Drawer, layer Layer, z int) *Node {
+	n := &Node{Drawer: d, graph: g, layer: layer, z: z, order: g.added}
+	g.added++
+	g.nodes = append(g.nodes, n)
+	g.dirty = true
+	return n
+}
+
+// Remove : Stop drawing a node. Does nothing if it was already removed.
+func (g *SceneGraph) Remove(n *Node) {
+	for i, node := range g.nodes {
+		if node == n {
+			g.nodes = append(g.nodes[:i], g.nodes[i+1:]...)
+			n.graph = nil
+			return
+		}
+	}
+}
+
+// Clear : Remove every node
+func (g *SceneGraph) Clear() {
+	for _, n := range g.nodes {
+		n.graph = nil
+	}
+	g.nodes = nil
+}
+
+// Len : How many nodes are in the graph, hidden or not
+func (g *SceneGraph) Len() int {
+	return len(g.nodes)
+}
+
+// SetCamera : Draw world layers through c, or in screen space if nil. The graph doesn't update
+// the camera.
+func (g *SceneGraph) SetCamera(c *Camera) {
+	g.camera = c
+}
+
+// Camera : The camera world layers are drawn through, if any
+func (g *SceneGraph) Camera() *Camera {
+	return g.camera
+}
+
+// Update : Update every node, visible or not, in draw order
+func (g *SceneGraph) Update() error {
+	g.sort()
+	for _, n := range g.nodes {
+		if err := n.Update(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Draw : Draw every visible, on-screen node, back to front. Nodes that share a sheet and sit next
+// to each other in draw order are batched into one draw call by ebiten, so keeping a layer's
+// sprites at the same Z lets them batch.
+func (g *SceneGraph) Draw(screen *ebiten.Image) {
+	g.sort()
+	visible := screen.Bounds()
+	var view ebiten.GeoM
+	if g.camera != nil {
+		view = g.camera.GeoM()
+	}
+	for _, n := range g.nodes {
+		if n.hidden {
+			continue
+		}
+		vd, throughCamera := n.Drawer.(ViewDrawer)
+		throughCamera = throughCamera && g.camera != nil && n.layer != LayerUI
+		if b, ok := n.Drawer.(Bounder); ok {
+			bounds := b.Bounds()
+			if throughCamera {
+				bounds = transformRect(view, bounds)
+			}
+			if !bounds.Overlaps(visible) {
+				continue
+			}
+		}
+		if throughCamera {
+			vd.DrawView(screen, view)
+			continue
+		}
+		n.Draw(screen)
+	}
+}
+
+func (g *SceneGraph) sort() {
+	if !g.dirty {
+		return
+	}
+	sort.Slice(g.nodes, func(i, j int) bool {
+		a, b := g.nodes[i], g.nodes[j]
+		if a.layer != b.layer {
+			return a.layer < b.layer
+		}
+		if a.z != b.z {
+			return a.z < b.z
+		}
+		return a.order < b.order
+	})
+	g.dirty = false
+}
+
+// Layer : The layer the node draws in
+func (n *Node) Layer() Layer {
+	return n.layer
+}
+
+// SetLayer : Move the node to another layer
+func (n *Node) SetLayer(l Layer) {
+	n.layer = l
+	n.changed()
+}
+
+// Z : The node's depth within its layer
+func (n *Node) Z() int {
+	return n.z
+}
+
+// SetZ : Set the node's depth within its layer. Higher draws over lower.
+func (n *Node) SetZ(z int) {
+	n.z = z
+	n.changed()
+}
+
+// Visible : Is the node drawn?
+func (n *Node) Visible() bool {
+	return !n.hidden
+}
+
+// SetVisible : Show or hide the node. Hidden nodes are still updated.
+func (n *Node) SetVisible(v bool) {
+	n.hidden = !v
+}
+
+func (n *Node) changed() {
+	if n.graph != nil {
+		n.graph.dirty = true
+	}
+}
+
+// Draw : Engine Draw hook
+func (f DrawFunc) Draw(screen *ebiten.Image) {
+	f(screen)
+}
+
+// Update : Engine Update hook
+func (f DrawFunc) Update() error { return nil }
+
+// Bounds : The area the tile covers, after its transform and before any camera. Empty if it has no image yet.
+func (t *Tile) Bounds() image.Rectangle {
+	if t.image == nil {
+		return image.Rectangle{}
+	}
+	return transformRect(t.geoM(), image.Rectangle{Max: t.image.Bounds().Size()})
+}
+
+// transformRect : The smallest rectangle holding r after g
+func transformRect(g ebiten.GeoM, r image.Rectangle) image.Rectangle {
+	minX, minY := g.Apply(float64(r.Min.X), float64(r.Min.Y))
+	maxX, maxY := minX, minY
+	for _, c := range []image.Point{{r.Max.X, r.Min.Y}, {r.Min.X, r.Max.Y}, r.Max} {
+		x, y := g.Apply(float64(c.X), float64(c.Y))
+		minX, maxX = math.Min(minX, x), math.Max(maxX, x)
+		minY, maxY = math.Min(minY, y), math.Max(maxY, y)
+	}
+	return image.Rect(int(math.Floor(minX)), int(math.Floor(minY)), int(math.Ceil(maxX)), int(math.Ceil(maxY)))
+}
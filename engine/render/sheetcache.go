@@ -0,0 +1,157 @@
+package render
+
+import (
+	"github.com/jessdwitch/spiders/engine/assets"
+)
+
+type (
+	// CachedSheets : A SpriteSheetGetter that decodes each sheet once, and shares it through an
+	//	asset manager
+	CachedSheets struct {
+		source SpriteSheetGetter
+		cache  *assets.Manager
+	}
+	// SheetCache : A SpriteSheetGetter which can load sheets ahead of time, and be told when a
+	//	sheet is no longer in use
+	SheetCache interface {
+		SpriteSheetGetter
+		// PreloadSheets : Load sheets in the background. See assets.Manager.Preload
+		PreloadSheets(ids []SourceImageID, progress assets.Progress) <-chan error
+		// ReleaseSheet : Drop a reference taken by GetSpriteSheet
+		ReleaseSheet(id SourceImageID)
+	}
+)
+
+// NewCachedSheets : Cache sheets from source in the given asset manager
+func NewCachedSheets(source SpriteSheetGetter, cache *assets.Manager) *CachedSheets {
+	return &CachedSheets{source: source, cache: cache}
+}
+
+func sheetAssetID(id SourceImageID) assets.ID {
+	return assets.ID("sheet/" + id)
+}
+
+func (c *CachedSheets) loader(id SourceImageID) assets.Loader {
+	return func() (interface{}, error) {
+		return c.source.GetSpriteSheet(id)
+	}
+}
+
+// GetSpriteSheet : Get a sheet, decoding it only if it isn't cached. Each call holds a reference
+// until ReleaseSheet.
+func (c *CachedSheets) GetSpriteSheet(id SourceImageID) (*SpriteSheet, error) {
+	sheet, err := c.cache.Acquire(sheetAssetID(id), c.loader(id))
+	if err != nil {
+		return nil, err
+	}
+	return sheet.(*SpriteSheet), nil
+}
+
+// ReleaseSheet : Drop a reference taken by GetSpriteSheet
+func (c *CachedSheets) ReleaseSheet(id SourceImageID) {
+	c.cache.Release(sheetAssetID(id))
+}
+
+func variantAssetID(v paletteVariant) assets.ID {
+	return assets.ID("variant/" + string(v.sheet) + "/" + v.palette)
+}
+
+// getVariant : Get a recolored sheet, painting it only if it isn't cached. Like GetSpriteSheet,
+// each call holds a reference until releaseVariant.
+func (c *CachedSheets) getVariant(
+	key paletteVariant,
+	paint func() (*SpriteSheet, error),
+) (*SpriteSheet, error) {
+	sheet, err := c.cache.Acquire(variantAssetID(key), func() (interface{}, error) {
+		return paint()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sheet.(*SpriteSheet), nil
+}
+
+// releaseVariant : Drop a reference taken by getVariant
+func (c *CachedSheets) releaseVariant(key paletteVariant) {
+	c.cache.Release(variantAssetID(key))
+}
+
+// InvalidateSheet : Drop a cached sheet, so it's decoded again next time it's asked for
+func (c *CachedSheets) InvalidateSheet(id SourceImageID) {
+	c.cache.Invalidate(sheetAssetID(id))
+}
+
+// invalidateVariant : Drop a cached recolored sheet, as when its sheet is reloaded
+func (c *CachedSheets) invalidateVariant(key paletteVariant) {
+	c.cache.Invalidate(variantAssetID(key))
+}
+
+// PreloadSheets : Decode sheets in the background
+func (c *CachedSheets) PreloadSheets(ids []SourceImageID, progress assets.Progress) <-chan error {
+	reqs := make([]assets.Request, len(ids))
+	for i, id := range ids {
+		reqs[i] = assets.Request{ID: sheetAssetID(id), Load: c.loader(id)}
+	}
+	return c.cache.Preload(reqs, progress)
+}
+
+// sheetsFor : The unique sheets used by a set of sprites
+func (s *SpriteFactory) sheetsFor(ids []SpriteID) ([]SourceImageID, error) {
+	seen := map[SourceImageID]bool{}
+	result := []SourceImageID{}
+	for _, id := range ids {
+		meta, err := s.spriteMetaGetter.GetSpriteMeta(id)
+		if err != nil {
+			return nil, err
+		}
+		for _, anim := range meta.Anims {
+			if !seen[anim.Source] {
+				seen[anim.Source] = true
+				result = append(result, anim.Source)
+			}
+		}
+	}
+	return result, nil
+}
+
+// Preload : Decode every sheet the given sprites need in the background, so a later GetSprite
+// doesn't stall. Without a SheetCache, this finishes immediately.
+func (s *SpriteFactory) Preload(ids []SpriteID, progress assets.Progress) <-chan error {
+	sheets, err := s.sheetsFor(ids)
+	cache, ok := s.sourceImageGetter.(SheetCache)
+	if err != nil || !ok {
+		result := make(chan error, 1)
+		if err != nil {
+			result <- err
+		} else if progress != nil {
+			progress(len(ids), len(ids))
+		}
+		close(result)
+		return result
+	}
+	return cache.PreloadSheets(sheets, progress)
+}
+
+// Release : Let go of the sheets held by a sprite from GetSprite, and any recolored copies of
+// them, so they can be evicted once nothing else uses them
+func (s *SpriteFactory) Release(id SpriteID) error {
+	if _, ok := s.sourceImageGetter.(SheetCache); !ok {
+		return nil
+	}
+	meta, err := s.spriteMetaGetter.GetSpriteMeta(id)
+	if err != nil {
+		return err
+	}
+	sheets, err := s.sheetsFor([]SpriteID{id})
+	if err != nil {
+		return err
+	}
+	variants := []paletteVariant{}
+	if s.shaders != nil && !meta.Palette.IsZero() {
+		for _, sheet := range sheets {
+			variants = append(variants, paletteVariant{sheet, meta.Palette.String()})
+		}
+	}
+	releaseSheets(s.sourceImageGetter, sheets, variants)
+	return nil
+}
@@ -0,0 +1,216 @@
+package spritedata
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"strconv"
+	"strings"
+)
+
+const (
+	// AsepriteDefaultMode : The mode given to every frame of an export with no tags
+	AsepriteDefaultMode = "idle"
+	// asepriteMarkerPrefix : Tags named like "@hit" mark their first frame instead of being a mode
+	asepriteMarkerPrefix = "@"
+)
+
+type (
+	// AsepriteSprite : An Aseprite JSON export, checked and with its tags turned into animations
+	AsepriteSprite struct {
+		// Image : The sheet image, relative to the JSON file
+		Image  string
+		Frames []AsepriteFrame
+		Anims  []AsepriteAnim
+		// Markers : Marker names by frame index
+		Markers map[int]string
+	}
+	// AsepriteAnim : A tag's frames in the order they play, and what to do after the last one
+	AsepriteAnim struct {
+		Mode     string
+		Sequence []int
+		Playback PlaybackMode
+	}
+	// AsepriteFrame : One frame of an export
+	AsepriteFrame struct {
+		Frame            AsepriteRect `json:"frame"`
+		Rotated          bool         `json:"rotated"`
+		SpriteSourceSize AsepriteRect `json:"spriteSourceSize"`
+		SourceSize       AsepriteRect `json:"sourceSize"`
+		// Duration : milliseconds
+		Duration int `json:"duration"`
+	}
+	// AsepriteRect : A rectangle as Aseprite writes them
+	AsepriteRect struct {
+		X int `json:"x"`
+		Y int `json:"y"`
+		W int `json:"w"`
+		H int `json:"h"`
+	}
+
+	asepriteExport struct {
+		// Frames : Either an array of frames, or an object of them keyed by name. Aseprite can
+		//	export either.
+		Frames json.RawMessage `json:"frames"`
+		Meta   struct {
+			Image     string        `json:"image"`
+			FrameTags []asepriteTag `json:"frameTags"`
+		} `json:"meta"`
+	}
+	asepriteTag struct {
+		Name      string `json:"name"`
+		From      int    `json:"from"`
+		To        int    `json:"to"`
+		Direction string `json:"direction"`
+		// Repeat : How many times to play, or empty for forever
+		Repeat string `json:"repeat"`
+	}
+)
+
+// Rectangle : The rect as an image.Rectangle
+func (r AsepriteRect) Rectangle() image.Rectangle {
+	return image.Rect(r.X, r.Y, r.X+r.W, r.Y+r.H)
+}
+
+// ParseAseprite : Read an Aseprite JSON export. Each tag is an animation mode, played in the
+// tag's direction, as many times as the tag repeats. Tags starting with @ mark frames. An export
+// with no tags gets one AsepriteDefaultMode of every frame.
+func ParseAseprite(b []byte) (AsepriteSprite, error) {
+	export := asepriteExport{}
+	if err := json.Unmarshal(b, &export); err != nil {
+		return AsepriteSprite{}, err
+	}
+	frames, err := asepriteFrames(export.Frames)
+	if err != nil {
+		return AsepriteSprite{}, err
+	}
+	if len(frames) == 0 {
+		return AsepriteSprite{}, errors.New("export has no frames")
+	}
+	for i, f := range frames {
+		if f.Rotated {
+			return AsepriteSprite{}, fmt.Errorf("frame %d is rotated; export without rotation", i)
+		}
+	}
+	result := AsepriteSprite{Image: export.Meta.Image, Frames: frames, Markers: map[int]string{}}
+	tags := []asepriteTag{}
+	for _, tag := range export.Meta.FrameTags {
+		if tag.From < 0 || tag.To >= len(frames) || tag.From > tag.To {
+			return AsepriteSprite{}, fmt.Errorf("tag %s covers frames %d-%d of %d",
+				tag.Name, tag.From, tag.To, len(frames))
+		}
+		if strings.HasPrefix(tag.Name, asepriteMarkerPrefix) {
+			result.Markers[tag.From] = strings.TrimPrefix(tag.Name, asepriteMarkerPrefix)
+			continue
+		}
+		tags = append(tags, tag)
+	}
+	if len(tags) == 0 {
+		tags = []asepriteTag{{Name: AsepriteDefaultMode, To: len(frames) - 1}}
+	}
+	for _, tag := range tags {
+		seq, playback, err := tag.sequence()
+		if err != nil {
+			return AsepriteSprite{}, err
+		}
+		result.Anims = append(result.Anims, AsepriteAnim{Mode: tag.Name, Sequence: seq, Playback: playback})
+	}
+	return result, nil
+}
+
+// asepriteFrames : Decode frames from either export layout, keeping their order
+func asepriteFrames(raw json.RawMessage) ([]AsepriteFrame, error) {
+	result := []AsepriteFrame{}
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return result, nil
+	}
+	if raw[0] == '[' {
+		err := json.Unmarshal(raw, &result)
+		return result, err
+	}
+	// Object keys are in frame order, which a map would lose
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	for dec.More() {
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		f := AsepriteFrame{}
+		if err := dec.Decode(&f); err != nil {
+			return nil, err
+		}
+		result = append(result, f)
+	}
+	return result, nil
+}
+
+// repeats : How many times the tag plays, or 0 for forever
+func (t asepriteTag) repeats() (int, error) {
+	if t.Repeat == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(t.Repeat)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("tag %s has repeat %q; want a count, or empty for forever", t.Name, t.Repeat)
+	}
+	return n, nil
+}
+
+// sequence : The frame indices the tag plays, in order, and how to play them. A tag that repeats
+// a set number of times plays its whole ping-pong that many times, then stops.
+func (t asepriteTag) sequence() ([]int, PlaybackMode, error) {
+	repeats, err := t.repeats()
+	if err != nil {
+		return nil, PlayLoop, err
+	}
+	forward := []int{}
+	for i := t.From; i <= t.To; i++ {
+		forward = append(forward, i)
+	}
+	reverse := make([]int, len(forward))
+	for i, f := range forward {
+		reverse[len(forward)-1-i] = f
+	}
+	// Ping-pong doesn't repeat the end frames when it turns around
+	inner := func(s []int) []int {
+		if len(s) < 3 {
+			return nil
+		}
+		return s[1 : len(s)-1]
+	}
+	var once []int
+	switch t.Direction {
+	case "", "forward":
+		if repeats == 0 {
+			return forward, PlayLoop, nil
+		}
+		once = forward
+	case "reverse":
+		if repeats == 0 {
+			return reverse, PlayLoop, nil
+		}
+		once = reverse
+	case "pingpong":
+		if repeats == 0 {
+			return forward, PlayPingPong, nil
+		}
+		once = append(forward, inner(reverse)...)
+	case "pingpong_reverse":
+		if repeats == 0 {
+			return reverse, PlayPingPong, nil
+		}
+		once = append(reverse, inner(forward)...)
+	default:
+		return nil, PlayLoop, fmt.Errorf("tag %s has unknown direction %s", t.Name, t.Direction)
+	}
+	seq := make([]int, 0, len(once)*repeats)
+	for i := 0; i < repeats; i++ {
+		seq = append(seq, once...)
+	}
+	return seq, PlayOnce, nil
+}
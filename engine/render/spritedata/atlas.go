@@ -0,0 +1,56 @@
+package spritedata
+
+import (
+	"fmt"
+	"image"
+	"strconv"
+)
+
+type (
+	// AtlasRecord : One frame of one sheet, packed onto an atlas page
+	AtlasRecord struct {
+		Sheet string
+		Page  string
+		Frame int
+		Rect  image.Rectangle
+		// Origin : The pivot, relative to Rect.Min
+		Origin image.Point
+		// Duration : How long to hold the frame. Zero means use the animation's delay.
+		Duration int
+	}
+)
+
+// Atlas manifests have the frame rects, and optionally each frame's origin and duration
+const (
+	AtlasManifestColumns     = 7
+	AtlasManifestFullColumns = 10
+)
+
+// ParseAtlasRecord : Parse an atlas manifest record: sheet, page, frame, x, y, w, h[, originX,
+// originY, duration]
+func ParseAtlasRecord(record []string) (AtlasRecord, error) {
+	if len(record) != AtlasManifestColumns && len(record) != AtlasManifestFullColumns {
+		return AtlasRecord{}, fmt.Errorf("atlas manifest record %v has %d columns, want %d or %d",
+			record, len(record), AtlasManifestColumns, AtlasManifestFullColumns)
+	}
+	nums := make([]int, AtlasManifestFullColumns-2)
+	for i := range record[2:] {
+		var err error
+		if nums[i], err = strconv.Atoi(record[i+2]); err != nil {
+			return AtlasRecord{}, err
+		}
+	}
+	frame, x, y, w, h := nums[0], nums[1], nums[2], nums[3], nums[4]
+	if frame < 0 || w <= 0 || h <= 0 || nums[7] < 0 {
+		return AtlasRecord{}, fmt.Errorf(
+			"atlas manifest record %v has a bad frame index, size, or duration", record)
+	}
+	return AtlasRecord{
+		Sheet:    record[0],
+		Page:     record[1],
+		Frame:    frame,
+		Rect:     image.Rect(x, y, x+w, y+h),
+		Origin:   image.Pt(nums[5], nums[6]),
+		Duration: nums[7],
+	}, nil
+}
@@ -0,0 +1,94 @@
+package spritedata
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+// MaxPaletteColors : How many colors one palette swap can change
+const MaxPaletteColors = 8
+
+type (
+	// Palette : Colors to swap, each From color for the To color at the same index
+	Palette struct {
+		From []color.Color
+		To   []color.Color
+	}
+)
+
+// ParsePalette : Parse a sprite manifest palette: semicolon-separated from>to pairs of hex colors,
+// as in "#3e8948>#e43b44;#63c74d>#f6757a". Empty is no palette.
+func ParsePalette(field string) (Palette, error) {
+	p := Palette{}
+	if field == "" {
+		return p, nil
+	}
+	for _, pair := range strings.Split(field, ";") {
+		colors := strings.Split(pair, ">")
+		if len(colors) != 2 {
+			return Palette{}, fmt.Errorf("palette entry %q should be #from>#to", pair)
+		}
+		for i, c := range colors {
+			s, err := ParseColorScale(c)
+			if err != nil {
+				return Palette{}, err
+			}
+			if s[3] != 1 {
+				return Palette{}, fmt.Errorf("palette color %q can't be transparent", c)
+			}
+			clr := color.NRGBA{uint8(s[0]*0xff + 0.5), uint8(s[1]*0xff + 0.5), uint8(s[2]*0xff + 0.5), 0xff}
+			if i == 0 {
+				p.From = append(p.From, clr)
+			} else {
+				p.To = append(p.To, clr)
+			}
+		}
+	}
+	if len(p.From) > MaxPaletteColors {
+		return Palette{}, fmt.Errorf("palette swaps %d colors, max %d", len(p.From), MaxPaletteColors)
+	}
+	return p, nil
+}
+
+// IsZero : Does the palette swap nothing?
+func (p Palette) IsZero() bool {
+	return len(p.From) == 0
+}
+
+// String : The palette as a manifest would have it
+func (p Palette) String() string {
+	pairs := make([]string, len(p.From))
+	for i := range p.From {
+		pairs[i] = hexColor(p.From[i]) + ">" + hexColor(p.To[i])
+	}
+	return strings.Join(pairs, ";")
+}
+
+func hexColor(c color.Color) string {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return fmt.Sprintf("#%02x%02x%02x", n.R, n.G, n.B)
+}
+
+// ParseColorScale : Parse a hex color, as in "#ff8800" or "#ff880080", into color scales from 0
+// to 1. Alpha is 1 if not given.
+func ParseColorScale(s string) ([4]float64, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) != 6 && len(hex) != 8 {
+		return [4]float64{}, fmt.Errorf("color %q should be #rrggbb or #rrggbbaa", s)
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return [4]float64{}, fmt.Errorf("color %q: %w", s, err)
+	}
+	return [4]float64{
+		float64(v>>24&0xff) / 0xff,
+		float64(v>>16&0xff) / 0xff,
+		float64(v>>8&0xff) / 0xff,
+		float64(v&0xff) / 0xff,
+	}, nil
+}
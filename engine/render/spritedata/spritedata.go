@@ -0,0 +1,48 @@
+// Package spritedata : Sprite manifest formats, parsed without touching the GPU, so tools like
+// contentlint can check content the same way the game reads it. The render package builds on
+// these types.
+package spritedata
+
+import (
+	"fmt"
+)
+
+type (
+	// PlaybackMode : What an animation does when it runs out of frames
+	PlaybackMode int
+)
+
+// Playback modes
+const (
+	// PlayLoop : Start over from the first frame
+	PlayLoop PlaybackMode = iota
+	// PlayOnce : Stop at the end, then play whatever's queued, or else go back to the last looping
+	//	animation
+	PlayOnce
+	// PlayPingPong : Play back to the first frame, then forward again
+	PlayPingPong
+	// PlayHoldLast : Stay on the last frame until told otherwise, as in a death
+	PlayHoldLast
+)
+
+var playbackNames = map[string]PlaybackMode{
+	"":         PlayLoop,
+	"loop":     PlayLoop,
+	"once":     PlayOnce,
+	"pingpong": PlayPingPong,
+	"hold":     PlayHoldLast,
+}
+
+// ParsePlaybackMode : Get a playback mode from its manifest name: loop, once, pingpong, or hold.
+// Empty means loop.
+func ParsePlaybackMode(name string) (PlaybackMode, error) {
+	if mode, ok := playbackNames[name]; ok {
+		return mode, nil
+	}
+	return PlayLoop, fmt.Errorf("unknown playback mode %q", name)
+}
+
+// Loops : Does the animation go on forever?
+func (p PlaybackMode) Loops() bool {
+	return p == PlayLoop || p == PlayPingPong
+}
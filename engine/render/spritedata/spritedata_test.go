@@ -0,0 +1,68 @@
+package spritedata_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jessdwitch/spiders/engine/render/spritedata"
+	"github.com/stretchr/testify/assert"
+)
+
+const sampleFrames = `"frames": [
+    {"frame": {"x": 0, "y": 0, "w": 8, "h": 8}, "sourceSize": {"w": 8, "h": 8}, "duration": 100},
+    {"frame": {"x": 8, "y": 0, "w": 8, "h": 8}, "sourceSize": {"w": 8, "h": 8}, "duration": 100},
+    {"frame": {"x": 16, "y": 0, "w": 8, "h": 8}, "sourceSize": {"w": 8, "h": 8}, "duration": 100}
+  ]`
+
+func TestParseAsepriteRepeat(t *testing.T) {
+	ase, err := spritedata.ParseAseprite([]byte(`{` + sampleFrames + `, "meta": {"image": "a.png",
+	  "frameTags": [
+	    {"name": "idle", "from": 0, "to": 2, "direction": "forward"},
+	    {"name": "hit", "from": 0, "to": 1, "direction": "reverse", "repeat": "2"},
+	    {"name": "hop", "from": 0, "to": 2, "direction": "pingpong", "repeat": "1"},
+	    {"name": "@land", "from": 2, "to": 2}
+	  ]}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "a.png", ase.Image)
+	assert.Equal(t, []spritedata.AsepriteAnim{
+		{Mode: "idle", Sequence: []int{0, 1, 2}, Playback: spritedata.PlayLoop},
+		{Mode: "hit", Sequence: []int{1, 0, 1, 0}, Playback: spritedata.PlayOnce},
+		{Mode: "hop", Sequence: []int{0, 1, 2, 1}, Playback: spritedata.PlayOnce},
+	}, ase.Anims)
+	assert.Equal(t, map[int]string{2: "land"}, ase.Markers)
+
+	for _, repeat := range []string{"-1", "twice"} {
+		_, err = spritedata.ParseAseprite([]byte(`{` + sampleFrames + `, "meta": {"frameTags": [
+		  {"name": "idle", "from": 0, "to": 2, "repeat": "` + repeat + `"}]}}`))
+		assert.Error(t, err, repeat)
+	}
+}
+
+func TestParseAsepriteNoTags(t *testing.T) {
+	ase, err := spritedata.ParseAseprite([]byte(`{` + sampleFrames + `, "meta": {}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []spritedata.AsepriteAnim{
+		{Mode: spritedata.AsepriteDefaultMode, Sequence: []int{0, 1, 2}, Playback: spritedata.PlayLoop},
+	}, ase.Anims)
+
+	_, err = spritedata.ParseAseprite([]byte(`{` + sampleFrames + `, "meta": {"frameTags": [
+	  {"name": "idle", "from": 1, "to": 3}]}}`))
+	assert.Error(t, err)
+}
+
+func TestParsePalette(t *testing.T) {
+	p, err := spritedata.ParsePalette("#3E8948>#e43b44;63c74d>#f6757aff")
+	assert.NoError(t, err)
+	assert.Len(t, p.From, 2)
+	assert.Equal(t, "#3e8948>#e43b44;#63c74d>#f6757a", p.String())
+
+	p, err = spritedata.ParsePalette("")
+	assert.NoError(t, err)
+	assert.True(t, p.IsZero())
+
+	nine := strings.Repeat("#000000>#ffffff;", spritedata.MaxPaletteColors) + "#000000>#ffffff"
+	for _, bad := range []string{"#3e8948", "#3e8948>#fff", "#3e894800>#000000", nine} {
+		_, err = spritedata.ParsePalette(bad)
+		assert.Error(t, err, bad)
+	}
+}
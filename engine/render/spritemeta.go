@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"strconv"
+	"strings"
 )
 
 type (
@@ -12,6 +13,8 @@ type (
 	SpriteMeta struct {
 		InitialDims Point
 		Anims       []AnimMeta
+		// Palette : Colors swapped in every animation's sheet, as in a red slime from a green one
+		Palette Palette
 	}
 	// SpriteMetaManager :
 	SpriteMetaManager map[SpriteID]SpriteMeta
@@ -45,20 +48,24 @@ func NewSpriteMetaManager(manifest *csv.Reader) (SpriteMetaManager, error) {
 }
 
 func (s SpriteMetaManager) processManifestCsvRecord(record []string) error {
-	// record: name, sheet, mode, start, nFrames, dimX, dimY, delay
+	// record: name, sheet, mode, start, nFrames, dimX, dimY, delay[, playback[, markers[, palette]]]
 	var err error
+	if len(record) < 8 || len(record) > 11 {
+		return fmt.Errorf("sprite manifest record %v has %d columns, want 8 to 11", record, len(record))
+	}
 	meta, ok := s[SpriteID(record[0])]
 	if !ok {
 		// TODO: Rescale for screen size
 		dims := Point{}
-		dims.X, err = strconv.ParseFloat(record[5], 64)
-		dims.Y, err = strconv.ParseFloat(record[6], 64)
-		if err != nil {
+		if dims.X, err = strconv.ParseFloat(record[5], 64); err != nil {
+			return err
+		}
+		if dims.Y, err = strconv.ParseFloat(record[6], 64); err != nil {
 			return err
 		}
 		meta = SpriteMeta{
-			dims,
-			[]AnimMeta{},
+			InitialDims: dims,
+			Anims:       []AnimMeta{},
 		}
 	}
 	start, err := strconv.Atoi(record[3])
@@ -69,9 +76,9 @@ func (s SpriteMetaManager) processManifestCsvRecord(record []string) error {
 	if err != nil {
 		return err
 	}
-	delay, err := strconv.Atoi(record[7])
+	delay, durations, err := parseDelays(record[7], nFrames)
 	if err != nil {
-		return err
+		return fmt.Errorf("sprite %s: %w", record[0], err)
 	}
 	anim := AnimMeta{
 		Mode:       AnimationMode(record[2]),
@@ -79,6 +86,28 @@ func (s SpriteMetaManager) processManifestCsvRecord(record []string) error {
 		Start:      start,
 		NFrames:    nFrames,
 		FrameDelay: delay,
+		Durations:  durations,
+	}
+	if len(record) > 8 {
+		if anim.Playback, err = ParsePlaybackMode(record[8]); err != nil {
+			return fmt.Errorf("sprite %s: %w", record[0], err)
+		}
+	}
+	if len(record) > 9 {
+		if anim.Markers, err = parseMarkers(record[9]); err != nil {
+			return fmt.Errorf("sprite %s: %w", record[0], err)
+		}
+	}
+	if len(record) > 10 && record[10] != "" {
+		// Any of the sprite's rows may give its palette, but they can't disagree
+		palette, err := ParsePalette(record[10])
+		if err != nil {
+			return fmt.Errorf("sprite %s: %w", record[0], err)
+		}
+		if !meta.Palette.IsZero() && meta.Palette.String() != palette.String() {
+			return fmt.Errorf("sprite %s has two palettes", record[0])
+		}
+		meta.Palette = palette
 	}
 	meta.Anims = append(meta.Anims, anim)
 	s[SpriteID(record[0])] = meta
@@ -90,5 +119,52 @@ func (s SpriteMetaManager) GetSpriteMeta(id SpriteID) (SpriteMeta, error) {
 	if meta, ok := s[id]; ok {
 		return meta, nil
 	}
-	return SpriteMeta{}, fmt.Errorf("id %s not found in sprite metas", id)
+	return SpriteMeta{}, fmt.Errorf("id %s: %w", id, ErrSpriteNotFound)
+}
+
+// parseDelays : Parse a sprite manifest delay: either one delay for every frame, or a
+// semicolon-separated delay per frame (as in "5;5;10;5"). For a list, delay is the first entry
+// and durations has them all.
+func parseDelays(field string, nFrames int) (delay int, durations []int, err error) {
+	parts := strings.Split(field, ";")
+	if len(parts) > 1 && len(parts) != nFrames {
+		return 0, nil, fmt.Errorf("%d delays given for %d frames", len(parts), nFrames)
+	}
+	values := make([]int, len(parts))
+	for i, p := range parts {
+		if values[i], err = strconv.Atoi(p); err != nil {
+			return 0, nil, err
+		}
+		if values[i] < 0 {
+			return 0, nil, fmt.Errorf("delay %d is negative", values[i])
+		}
+	}
+	if len(values) > 1 {
+		durations = values
+	}
+	return values[0], durations, nil
+}
+
+// parseMarkers : Parse sprite manifest markers: semicolon-separated name@frame pairs, as in
+// "hit@2;land@4". Frames count from 0 within the animation.
+func parseMarkers(field string) (map[int]string, error) {
+	if field == "" {
+		return nil, nil
+	}
+	result := map[int]string{}
+	for _, m := range strings.Split(field, ";") {
+		i := strings.LastIndex(m, "@")
+		if i <= 0 {
+			return nil, fmt.Errorf("marker %q should be name@frame", m)
+		}
+		frame, err := strconv.Atoi(m[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("marker %q: %w", m, err)
+		}
+		if _, ok := result[frame]; ok {
+			return nil, fmt.Errorf("frame %d is marked twice", frame)
+		}
+		result[frame] = m[:i]
+	}
+	return result, nil
 }
@@ -0,0 +1,161 @@
+package render
+
+import (
+	"errors"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// ErrTransformCycle : A transform can't be its own ancestor
+var ErrTransformCycle = errors.New("transform would be its own parent")
+
+// Transform : A full 2D transform. Applied to an image, it moves the origin to (0, 0), then scales,
+// skews, and rotates about it, then moves it to the position. A parent's transform is applied
+// after, so children follow their parents.
+type Transform struct {
+	position Point
+	scale    Point
+	// rotation : radians, clockwise on screen
+	rotation float64
+	// skew : radians along each axis
+	skew Point
+	// origin : The pivot, in untransformed pixels from the top-left
+	origin Point
+	parent *Transform
+}
+
+// NewTransform : An identity transform at the given position
+func NewTransform(x, y float64) *Transform {
+	return &Transform{position: Point{x, y}, scale: Point{1, 1}}
+}
+
+// Translate : Move the transform by an offset
+func (t *Transform) Translate(x, y float64) {
+	t.position.X += x
+	t.position.Y += y
+}
+
+// SetPosition : Move the transform to a position, relative to its parent
+func (t *Transform) SetPosition(x, y float64) {
+	t.position = Point{x, y}
+}
+
+// GetPosition : Where the origin is drawn, relative to the parent
+func (t *Transform) GetPosition() Point {
+	return t.position
+}
+
+// Scale : Multiply the current scale by factors
+func (t *Transform) Scale(x, y float64) {
+	t.scale.X *= x
+	t.scale.Y *= y
+}
+
+// SetScale : Set the scale outright
+func (t *Transform) SetScale(x, y float64) {
+	t.scale = Point{x, y}
+}
+
+// GetScale : The current scale factors
+func (t *Transform) GetScale() Point {
+	return t.scale
+}
+
+// Rotate : Rotate about the origin by theta radians, clockwise on screen
+func (t *Transform) Rotate(theta float64) {
+	t.rotation += theta
+}
+
+// SetRotation : Set the rotation outright, in radians
+func (t *Transform) SetRotation(theta float64) {
+	t.rotation = theta
+}
+
+// GetRotation : The current rotation in radians
+func (t *Transform) GetRotation() float64 {
+	return t.rotation
+}
+
+// Skew : Shear along the x and y axes by angles in radians, adding to the current skew
+func (t *Transform) Skew(x, y float64) {
+	t.skew.X += x
+	t.skew.Y += y
+}
+
+// SetSkew : Set the skew outright, in radians
+func (t *Transform) SetSkew(x, y float64) {
+	t.skew = Point{x, y}
+}
+
+// GetSkew : The current skew in radians
+func (t *Transform) GetSkew() Point {
+	return t.skew
+}
+
+// SetOrigin : Set the pivot that scale, skew, and rotation happen about, and that is drawn at the
+// position. In untransformed pixels from the top-left, as in the middle of a 32x32 image is
+// (16, 16).
+func (t *Transform) SetOrigin(x, y float64) {
+	t.origin = Point{x, y}
+}
+
+// GetOrigin : The current pivot
+func (t *Transform) GetOrigin() Point {
+	return t.origin
+}
+
+// SetParent : Make t follow a parent, so t's position, scale, and rotation are relative to it. A
+// nil parent detaches t.
+func (t *Transform) SetParent(parent *Transform) error {
+	for p := parent; p != nil; p = p.parent {
+		if p == t {
+			return ErrTransformCycle
+		}
+	}
+	t.parent = parent
+	return nil
+}
+
+// GetParent : The transform t follows, if any
+func (t *Transform) GetParent() *Transform {
+	return t.parent
+}
+
+// GetTransform : The transform itself, as in for a child's SetParent
+func (t *Transform) GetTransform() *Transform {
+	return t
+}
+
+// GeoM : The local matrix, then the parents'. What the image is drawn with.
+func (t *Transform) GeoM() ebiten.GeoM {
+	g := t.local()
+	for p := t.parent; p != nil; p = p.parent {
+		g.Concat(p.local())
+	}
+	return g
+}
+
+// WorldPosition : Where the origin ends up on screen, after every parent
+func (t *Transform) WorldPosition() Point {
+	if t.parent == nil {
+		return t.position
+	}
+	g := t.parent.GeoM()
+	x, y := g.Apply(t.position.X, t.position.Y)
+	return Point{x, y}
+}
+
+func (t *Transform) local() ebiten.GeoM {
+	g := ebiten.GeoM{}
+	g.Translate(-t.origin.X, -t.origin.Y)
+	g.Scale(t.scale.X, t.scale.Y)
+	if t.skew != (Point{}) {
+		g.Skew(t.skew.X, t.skew.Y)
+	}
+	if math.Mod(t.rotation, 2*math.Pi) != 0 {
+		g.Rotate(t.rotation)
+	}
+	g.Translate(t.position.X, t.position.Y)
+	return g
+}
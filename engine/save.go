@@ -0,0 +1,110 @@
+// Persist and restore game progress
+
+package engine
+
+import (
+	"encoding/gob"
+	"os"
+	"path/filepath"
+
+	"github.com/jessdwitch/spiders/deck"
+)
+
+const (
+	// userDirName : The directory under the OS config dir where user files live
+	userDirName  = "spiders"
+	saveFileName = "save.gob"
+	modsDirName  = "mods"
+)
+
+type (
+	// SaveData : Everything from GameState that outlives a play session
+	SaveData struct {
+		PlayerParty PlayerParty
+		Collection  deck.Collection
+		DeckList    deck.DeckList
+	}
+)
+
+// userDir : Get the directory for user files, creating it if need be
+func userDir() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(base, userDirName)
+	if err = os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// SavePath : The location of the save file
+func SavePath() (string, error) {
+	dir, err := userDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, saveFileName), nil
+}
+
+// ModsDir : The directory mod folders are loaded from. Each mod mirrors the layout of content/.
+func ModsDir() (string, error) {
+	dir, err := userDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, modsDirName), nil
+}
+
+// HasSave : Is there a save file to continue from?
+func HasSave() bool {
+	fp, err := SavePath()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(fp)
+	return err == nil
+}
+
+// Save : Write the persistent parts of the GameState to the save file
+func (g *GameState) Save() error {
+	fp, err := SavePath()
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(fp)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return gob.NewEncoder(f).Encode(SaveData{
+		PlayerParty: g.PlayerParty,
+		Collection:  g.Collection,
+		DeckList:    g.DeckList,
+	})
+}
+
+// Load : Restore the GameState from the save file
+func (g *GameState) Load() error {
+	fp, err := SavePath()
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(fp)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	data := SaveData{}
+	if err = gob.NewDecoder(f).Decode(&data); err != nil {
+		return err
+	}
+	g.PlayerParty = data.PlayerParty
+	// Saves from before deck building keep the starter cards
+	if data.Collection != nil {
+		g.Collection = data.Collection
+		g.DeckList = data.DeckList
+	}
+	return nil
+}
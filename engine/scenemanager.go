@@ -3,12 +3,12 @@
 package engine
 
 import (
-	"github.com/hajimehoshi/ebiten/v2"
-)
+	"errors"
+	"image"
+
+	"github.com/jessdwitch/spiders/engine/audio"
 
-var (
-	transitionFrom = ebiten.NewImage(1280, 960) //(ScreenWidth, ScreenHeight)
-	transitionTo   = ebiten.NewImage(1280, 960) //(ScreenWidth, ScreenHeight)
+	"github.com/hajimehoshi/ebiten/v2"
 )
 
 const transitionMaxCount = 20
@@ -18,10 +18,27 @@ type (
 		Update(state *GameState) error
 		Draw(screen *ebiten.Image)
 	}
+	// Scored : A Scene with background music. Scenes without it keep whatever was playing.
+	Scored interface {
+		Music() audio.ClipID
+	}
+	// Leaver : A Scene with something to clean up once it's been left, as in releasing sprites.
+	//	Scenes which can be gone back to shouldn't let go of what they still draw.
+	Leaver interface {
+		Leave(state *GameState) error
+	}
 	SceneManager struct {
 		current         Scene
 		next            Scene
 		transitionCount int
+		// cued : The last scene whose music was started
+		cued Scene
+		// size : The logical screen size, which scenes are drawn at
+		size image.Point
+		// transitionFrom, transitionTo : The scenes being left and gone to are drawn to these, then
+		//	blended. Made at size when first needed.
+		transitionFrom *ebiten.Image
+		transitionTo   *ebiten.Image
 	}
 )
 
@@ -35,17 +52,37 @@ func NewSceneManager(initialScene Scene) *SceneManager {
 // Update : Call the current Scene's Update, or do nothing if in transition
 func (s *SceneManager) Update(state *GameState) error {
 	if s.transitionCount == 0 {
+		if s.cued != s.current {
+			if err := s.cueMusic(state, s.current, 0); err != nil {
+				return err
+			}
+		}
 		return s.current.Update(state)
 	}
+	if s.transitionCount == transitionMaxCount {
+		// Crossfade alongside the visual transition
+		if err := s.cueMusic(state, s.next, transitionMaxCount); err != nil {
+			return err
+		}
+	}
 
 	s.transitionCount--
 	if s.transitionCount > 0 {
 		return nil
 	}
 
+	left := s.current
 	s.current = s.next
 	s.next = nil
-
+	if l, ok := left.(Leaver); ok {
+		if err := l.Leave(state); err != nil {
+			return err
+		}
+	}
+	// A loading scene's preloads aren't held by anything until its next scene is built
+	if _, loading := s.current.(*LoadingScene); !loading && state.Assets != nil {
+		state.Assets.Evict()
+	}
 	return nil
 }
 
@@ -56,18 +93,46 @@ func (s *SceneManager) Draw(r *ebiten.Image) {
 		return
 	}
 
-	transitionFrom.Clear()
-	s.current.Draw(transitionFrom)
+	if s.transitionFrom == nil {
+		size := s.size
+		if size.X <= 0 || size.Y <= 0 {
+			size = r.Bounds().Size()
+		}
+		s.transitionFrom = ebiten.NewImage(size.X, size.Y)
+		s.transitionTo = ebiten.NewImage(size.X, size.Y)
+	}
+	s.transitionFrom.Clear()
+	s.current.Draw(s.transitionFrom)
 
-	transitionTo.Clear()
-	s.next.Draw(transitionTo)
+	s.transitionTo.Clear()
+	s.next.Draw(s.transitionTo)
 
-	r.DrawImage(transitionFrom, nil)
+	r.DrawImage(s.transitionFrom, nil)
 
 	alpha := 1 - float64(s.transitionCount)/float64(transitionMaxCount)
 	op := &ebiten.DrawImageOptions{}
 	op.ColorM.Scale(1, 1, 1, alpha)
-	r.DrawImage(transitionTo, op)
+	r.DrawImage(s.transitionTo, op)
+}
+
+// SetSize : Set the logical screen size scenes are drawn at, as from Config. Transitions are
+// drawn at the new size from then on.
+func (s *SceneManager) SetSize(width, height int) {
+	size := image.Pt(width, height)
+	if size == s.size {
+		return
+	}
+	s.size = size
+	if s.transitionFrom != nil {
+		s.transitionFrom.Dispose()
+		s.transitionTo.Dispose()
+		s.transitionFrom, s.transitionTo = nil, nil
+	}
+}
+
+// Current : The scene being played, or the one being left if in transition
+func (s *SceneManager) Current() Scene {
+	return s.current
 }
 
 // GoTo : Initiate a scene transition to the given Scene
@@ -79,3 +144,16 @@ func (s *SceneManager) GoTo(scene Scene) {
 		s.transitionCount = transitionMaxCount
 	}
 }
+
+func (s *SceneManager) cueMusic(state *GameState, scene Scene, fadeTicks int) error {
+	s.cued = scene
+	scored, ok := scene.(Scored)
+	if !ok || state.Audio == nil {
+		return nil
+	}
+	// Like sound effects, music is optional content
+	if err := state.Audio.PlayMusic(scored.Music(), fadeTicks); err != nil && !errors.Is(err, audio.ErrClipNotFound) {
+		return err
+	}
+	return nil
+}
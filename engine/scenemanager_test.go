@@ -0,0 +1,40 @@
+package engine_test
+
+import (
+	"image"
+	"testing"
+
+	"github.com/jessdwitch/spiders/engine"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// sizeScene : Remembers the size of the last image it was drawn to
+type sizeScene struct {
+	drawn image.Point
+}
+
+func (s *sizeScene) Update(_ *engine.GameState) error { return nil }
+
+func (s *sizeScene) Draw(screen *ebiten.Image) {
+	s.drawn = screen.Bounds().Size()
+}
+
+func TestTransitionSize(t *testing.T) {
+	from, to := &sizeScene{}, &sizeScene{}
+	state := engine.NewGameState(from)
+	c := state.Config
+	logical := image.Pt(c.ScreenWidth, c.ScreenHeight)
+	screen := ebiten.NewImage(logical.X, logical.Y)
+
+	state.SceneManager.GoTo(to)
+	state.SceneManager.Draw(screen)
+	assert.Equal(t, logical, from.drawn, "the scene being left")
+	assert.Equal(t, logical, to.drawn, "the scene being gone to")
+
+	state.SceneManager.SetSize(320, 240)
+	state.SceneManager.Draw(ebiten.NewImage(320, 240))
+	assert.Equal(t, image.Pt(320, 240), from.drawn)
+	assert.Equal(t, image.Pt(320, 240), to.drawn)
+}
@@ -0,0 +1,54 @@
+// Copy in the player's language
+
+package engine
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"strings"
+)
+
+type (
+	// Text : The copy for one language, by text ID. Read from content/text/<language>.txt.
+	Text struct {
+		Language string
+		copy     map[string]string
+	}
+)
+
+// LoadText : Read the copy for a language. Each line is id=text; blank lines and lines starting
+// with # are skipped.
+func LoadText(fsys fs.FS, language string) (*Text, error) {
+	file := "text/" + language + ".txt"
+	f, err := fsys.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("no text for language %q: %w", language, err)
+	}
+	defer f.Close()
+	t := &Text{Language: language, copy: map[string]string{}}
+	s := bufio.NewScanner(f)
+	for line := 1; s.Scan(); line++ {
+		l := strings.TrimSpace(s.Text())
+		if l == "" || strings.HasPrefix(l, "#") {
+			continue
+		}
+		i := strings.Index(l, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("%s:%d: expected id=text", file, line)
+		}
+		t.copy[strings.TrimSpace(l[:i])] = strings.TrimSpace(l[i+1:])
+	}
+	return t, s.Err()
+}
+
+// Get : The copy for id, or fallback if there's no text loaded or it doesn't have id
+func (t *Text) Get(id, fallback string) string {
+	if t == nil {
+		return fallback
+	}
+	if s, ok := t.copy[id]; ok {
+		return s
+	}
+	return fallback
+}
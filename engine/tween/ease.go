@@ -0,0 +1,55 @@
+package tween
+
+import (
+	"math"
+)
+
+// Ease : Maps progress through a tween, from 0 to 1, to how far along the value should be. May
+// overshoot 0 or 1, as Back and Elastic do.
+type Ease func(t float64) float64
+
+// Easing functions. In eases start slowly, Out eases end slowly, and InOut eases do both.
+var (
+	Linear Ease = func(t float64) float64 { return t }
+
+	InQuad    Ease = func(t float64) float64 { return t * t }
+	OutQuad        = Out(InQuad)
+	InOutQuad      = InOut(InQuad)
+
+	InCubic    Ease = func(t float64) float64 { return t * t * t }
+	OutCubic        = Out(InCubic)
+	InOutCubic      = InOut(InCubic)
+
+	// InBack : Pull back a little before going, as in a wind-up
+	InBack Ease = func(t float64) float64 {
+		const s = 1.70158
+		return t * t * ((s+1)*t - s)
+	}
+	OutBack   = Out(InBack)
+	InOutBack = InOut(InBack)
+
+	// InElastic : Wobble with growing swings, then snap to the end
+	InElastic Ease = func(t float64) float64 {
+		if t == 0 || t == 1 {
+			return t
+		}
+		return -math.Pow(2, 10*(t-1)) * math.Sin((t-1.075)*2*math.Pi/0.3)
+	}
+	OutElastic   = Out(InElastic)
+	InOutElastic = InOut(InElastic)
+)
+
+// Out : Reverse an In ease, so it ends slowly instead of starting slowly
+func Out(in Ease) Ease {
+	return func(t float64) float64 { return 1 - in(1-t) }
+}
+
+// InOut : Ease in for the first half, and out for the second
+func InOut(in Ease) Ease {
+	return func(t float64) float64 {
+		if t < 0.5 {
+			return in(2*t) / 2
+		}
+		return 1 - in(2-2*t)/2
+	}
+}
@@ -0,0 +1,85 @@
+package tween
+
+import (
+	"image/color"
+)
+
+type (
+	// Mover : Can be moved by an offset, as render.Transformer can
+	Mover interface {
+		Translate(x, y float64)
+	}
+	// Scaler : Can be scaled by a factor, as render.Transformer can
+	Scaler interface {
+		Scale(x, y float64)
+	}
+	// Rotator : Can be rotated by an angle in radians
+	Rotator interface {
+		Rotate(theta float64)
+	}
+	// Fader : Has an opacity from 0 to 1, as render.Colorer does
+	Fader interface {
+		Alpha() float64
+		SetAlpha(a float64)
+	}
+	// Tinter : Has color scales, as render.Colorer does
+	Tinter interface {
+		ColorScale() (r, g, b, a float64)
+		SetColorScale(r, g, b, a float64)
+	}
+)
+
+// Move : Move m by (dx, dy) over ticks. Moves are relative, so they stack with other motion.
+func Move(m Mover, dx, dy float64, ticks int, ease Ease) Tween {
+	return Parallel(
+		By(dx, ticks, ease, func(d float64) { m.Translate(d, 0) }),
+		By(dy, ticks, ease, func(d float64) { m.Translate(0, d) }),
+	)
+}
+
+// ScaleBy : Scale s by factors (fx, fy) over ticks
+func ScaleBy(s Scaler, fx, fy float64, ticks int, ease Ease) Tween {
+	lastX, lastY := 1.0, 1.0
+	return Parallel(
+		To(1, fx, ticks, ease, func(x float64) {
+			s.Scale(x/lastX, 1)
+			lastX = x
+		}),
+		To(1, fy, ticks, ease, func(y float64) {
+			s.Scale(1, y/lastY)
+			lastY = y
+		}),
+	)
+}
+
+// Rotate : Rotate r by theta radians over ticks
+func Rotate(r Rotator, theta float64, ticks int, ease Ease) Tween {
+	return By(theta, ticks, ease, r.Rotate)
+}
+
+// FadeTo : Fade f to an opacity over ticks, from whatever it is when the tween starts
+func FadeTo(f Fader, alpha float64, ticks int, ease Ease) Tween {
+	return From(f.Alpha, alpha, ticks, ease, f.SetAlpha)
+}
+
+// TintTo : Shift t's color scales towards c over ticks, from whatever they are when the tween
+// starts. White is no tint.
+func TintTo(t Tinter, c color.Color, ticks int, ease Ease) Tween {
+	r, g, b, a := c.RGBA()
+	target := [4]float64{float64(r) / 0xffff, float64(g) / 0xffff, float64(b) / 0xffff, float64(a) / 0xffff}
+	from := [4]float64{}
+	current := [4]float64{}
+	return From(
+		func() float64 {
+			from[0], from[1], from[2], from[3] = t.ColorScale()
+			return 0
+		},
+		1, ticks, ease,
+		func(progress float64) {
+			for i := range current {
+				current[i] = from[i] + (target[i]-from[i])*progress
+			}
+			t.SetColorScale(current[0], current[1], current[2], current[3])
+		},
+	)
+}
@@ -0,0 +1,206 @@
+// Tweens: values eased over a number of ticks, and ways to combine them
+
+package tween
+
+type (
+	// Tween : Something that changes over a number of ticks
+	Tween interface {
+		// Update : Hook for the engine's tick function. Moves the tween along one tick.
+		Update() error
+		// Done : Has the tween finished?
+		Done() bool
+	}
+	// Value : Eases a number from one value to another
+	Value struct {
+		from, to float64
+		// start : reads from when the tween first updates, if set, so it starts from wherever the
+		//	value is by then
+		start func() float64
+		ticks int
+		tick  int
+		ease  Ease
+		set   func(float64)
+		// relative : set gets the change since the last tick, rather than the value
+		relative bool
+		last     float64
+	}
+	sequence struct {
+		tweens []Tween
+		i      int
+	}
+	parallel struct {
+		tweens []Tween
+	}
+	delay struct {
+		ticks int
+		tick  int
+	}
+	call struct {
+		f    func() error
+		done bool
+	}
+	// Runner : Updates a set of tweens until each finishes
+	Runner struct {
+		active []Tween
+	}
+)
+
+// To : Ease from one value to another over ticks, calling set with each new value
+func To(from, to float64, ticks int, ease Ease, set func(float64)) *Value {
+	return &Value{from: from, to: to, ticks: ticks, ease: ease, set: set}
+}
+
+// From : Ease to a value over ticks, starting from whatever get returns when the tween starts
+func From(get func() float64, to float64, ticks int, ease Ease, set func(float64)) *Value {
+	return &Value{start: get, to: to, ticks: ticks, ease: ease, set: set}
+}
+
+// By : Change something by a total amount over ticks. apply gets the change since the last tick,
+// so the tween adds to whatever else is changing the same thing.
+func By(change float64, ticks int, ease Ease, apply func(delta float64)) *Value {
+	return &Value{to: change, ticks: ticks, ease: ease, set: apply, relative: true}
+}
+
+// Update : Hook for the engine's tick function
+func (v *Value) Update() error {
+	if v.Done() {
+		return nil
+	}
+	if v.tick == 0 && v.start != nil {
+		v.from = v.start()
+	}
+	v.tick++
+	progress := 1.0
+	if v.ticks > 0 {
+		progress = v.ease(float64(v.tick) / float64(v.ticks))
+	}
+	value := v.from + (v.to-v.from)*progress
+	if v.relative {
+		value, v.last = value-v.last, value
+	}
+	v.set(value)
+	return nil
+}
+
+// Done : Has the value reached its end?
+func (v *Value) Done() bool {
+	return v.tick > 0 && v.tick >= v.ticks
+}
+
+// Sequence : Play tweens one after another
+func Sequence(tweens ...Tween) Tween {
+	return &sequence{tweens: tweens}
+}
+
+func (s *sequence) Update() error {
+	// Calls don't take a tick, so they run alongside whatever is before or after them
+	ticked := false
+	for s.i < len(s.tweens) {
+		t := s.tweens[s.i]
+		_, instant := t.(*call)
+		if ticked && !instant {
+			return nil
+		}
+		if !t.Done() {
+			if err := t.Update(); err != nil {
+				return err
+			}
+			ticked = ticked || !instant
+		}
+		if !t.Done() {
+			return nil
+		}
+		s.i++
+	}
+	return nil
+}
+
+func (s *sequence) Done() bool {
+	return s.i >= len(s.tweens)
+}
+
+// Parallel : Play tweens at the same time, finishing when they all have
+func Parallel(tweens ...Tween) Tween {
+	return &parallel{tweens: tweens}
+}
+
+func (p *parallel) Update() error {
+	for _, t := range p.tweens {
+		if t.Done() {
+			continue
+		}
+		if err := t.Update(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *parallel) Done() bool {
+	for _, t := range p.tweens {
+		if !t.Done() {
+			return false
+		}
+	}
+	return true
+}
+
+// Delay : Wait some ticks, as a gap in a Sequence
+func Delay(ticks int) Tween {
+	return &delay{ticks: ticks}
+}
+
+func (d *delay) Update() error {
+	d.tick++
+	return nil
+}
+
+func (d *delay) Done() bool {
+	return d.tick >= d.ticks
+}
+
+// Call : Run f once, without taking a tick, as in dealing damage when a card lands
+func Call(f func() error) Tween {
+	return &call{f: f}
+}
+
+func (c *call) Update() error {
+	c.done = true
+	return c.f()
+}
+
+func (c *call) Done() bool {
+	return c.done
+}
+
+// Add : Start playing a tween
+func (r *Runner) Add(t Tween) {
+	r.active = append(r.active, t)
+}
+
+// Update : Hook for the engine's tick function. Moves every tween along, and drops finished ones.
+func (r *Runner) Update() error {
+	// Tweens may Add more as they run; those start next tick
+	running := r.active
+	r.active = nil
+	var err error
+	for _, t := range running {
+		if err == nil && !t.Done() {
+			err = t.Update()
+		}
+		if !t.Done() {
+			r.active = append(r.active, t)
+		}
+	}
+	return err
+}
+
+// Busy : Are any tweens still playing?
+func (r *Runner) Busy() bool {
+	return len(r.active) > 0
+}
+
+// Clear : Stop every tween where it is
+func (r *Runner) Clear() {
+	r.active = nil
+}
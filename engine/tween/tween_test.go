@@ -0,0 +1,139 @@
+package tween_test
+
+import (
+	"errors"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/jessdwitch/spiders/engine/tween"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type target struct {
+	x, y, sx, sy, theta float64
+	color               [4]float64
+}
+
+func newTarget() *target {
+	return &target{sx: 1, sy: 1, color: [4]float64{1, 1, 1, 1}}
+}
+
+func (t *target) Translate(x, y float64) { t.x += x; t.y += y }
+func (t *target) Scale(x, y float64)     { t.sx *= x; t.sy *= y }
+func (t *target) Rotate(theta float64)   { t.theta += theta }
+func (t *target) Alpha() float64         { return t.color[3] }
+func (t *target) SetAlpha(a float64)     { t.color[3] = a }
+func (t *target) ColorScale() (r, g, b, a float64) {
+	return t.color[0], t.color[1], t.color[2], t.color[3]
+}
+func (t *target) SetColorScale(r, g, b, a float64) { t.color = [4]float64{r, g, b, a} }
+
+func run(t *testing.T, tw tween.Tween) int {
+	ticks := 0
+	for !tw.Done() {
+		assert.NoError(t, tw.Update())
+		ticks++
+		if ticks > 1000 {
+			t.Fatal("tween never finished")
+		}
+	}
+	return ticks
+}
+
+func TestEases(t *testing.T) {
+	eases := map[string]tween.Ease{
+		"Linear": tween.Linear, "InQuad": tween.InQuad, "OutQuad": tween.OutQuad,
+		"InOutQuad": tween.InOutQuad, "InCubic": tween.InCubic, "OutCubic": tween.OutCubic,
+		"InOutCubic": tween.InOutCubic, "InBack": tween.InBack, "OutBack": tween.OutBack,
+		"InOutBack": tween.InOutBack, "InElastic": tween.InElastic, "OutElastic": tween.OutElastic,
+		"InOutElastic": tween.InOutElastic,
+	}
+	for name, ease := range eases {
+		assert.InDelta(t, 0, ease(0), 1e-9, name)
+		assert.InDelta(t, 1, ease(1), 1e-9, name)
+	}
+	assert.InDelta(t, 0.25, tween.InQuad(0.5), 1e-9)
+	assert.InDelta(t, 0.75, tween.OutQuad(0.5), 1e-9)
+	assert.InDelta(t, 0.5, tween.InOutCubic(0.5), 1e-9)
+	// Back winds up below zero before going
+	assert.Less(t, tween.InBack(0.2), 0.0)
+}
+
+func TestValue(t *testing.T) {
+	values := []float64{}
+	v := tween.To(10, 20, 4, tween.Linear, func(x float64) { values = append(values, x) })
+	assert.Equal(t, 4, run(t, v))
+	assert.Equal(t, []float64{12.5, 15, 17.5, 20}, values)
+
+	// From reads its start when it starts, not when it's made
+	x := 0.0
+	from := tween.From(func() float64 { return x }, 10, 2, tween.Linear, func(v float64) { x = v })
+	x = 6
+	run(t, from)
+	assert.Equal(t, 10.0, x)
+
+	// No ticks jumps straight to the end
+	x = 0
+	assert.Equal(t, 1, run(t, tween.To(0, 5, 0, tween.Linear, func(v float64) { x = v })))
+	assert.Equal(t, 5.0, x)
+}
+
+func TestTransforms(t *testing.T) {
+	tg := newTarget()
+	tg.Translate(100, 0)
+	run(t, tween.Parallel(
+		tween.Move(tg, 10, -20, 5, tween.OutQuad),
+		tween.ScaleBy(tg, 2, 3, 3, tween.Linear),
+		tween.Rotate(tg, math.Pi, 4, tween.InOutCubic),
+		tween.FadeTo(tg, 0.5, 2, tween.Linear),
+	))
+	assert.InDelta(t, 110, tg.x, 1e-9)
+	assert.InDelta(t, -20, tg.y, 1e-9)
+	assert.InDelta(t, 2, tg.sx, 1e-9)
+	assert.InDelta(t, 3, tg.sy, 1e-9)
+	assert.InDelta(t, math.Pi, tg.theta, 1e-9)
+	assert.InDelta(t, 0.5, tg.color[3], 1e-9)
+
+	run(t, tween.TintTo(tg, color.RGBA{0xff, 0, 0, 0xff}, 3, tween.Linear))
+	assert.InDeltaSlice(t, []float64{1, 0, 0, 1}, tg.color[:], 1e-9)
+}
+
+func TestSequence(t *testing.T) {
+	log := []string{}
+	note := func(s string) tween.Tween {
+		return tween.Call(func() error { log = append(log, s); return nil })
+	}
+	x := 0.0
+	seq := tween.Sequence(
+		note("start"),
+		tween.To(0, 1, 2, tween.Linear, func(v float64) { x = v }),
+		note("moved"),
+		tween.Delay(3),
+		note("waited"),
+	)
+	// Calls don't take a tick of their own
+	assert.Equal(t, 5, run(t, seq))
+	assert.Equal(t, []string{"start", "moved", "waited"}, log)
+	assert.Equal(t, 1.0, x)
+
+	boom := errors.New("boom")
+	failing := tween.Sequence(tween.Call(func() error { return boom }))
+	assert.Equal(t, boom, failing.Update())
+}
+
+func TestRunner(t *testing.T) {
+	r := &tween.Runner{}
+	x := 0.0
+	r.Add(tween.To(0, 1, 2, tween.Linear, func(v float64) { x = v }))
+	r.Add(tween.Delay(3))
+	assert.True(t, r.Busy())
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, r.Update())
+	}
+	assert.Equal(t, 1.0, x)
+	assert.True(t, r.Busy())
+	assert.NoError(t, r.Update())
+	assert.False(t, r.Busy())
+}
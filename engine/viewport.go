@@ -0,0 +1,100 @@
+// Fitting the game's fixed logical screen into whatever size the window is
+
+package engine
+
+import (
+	"fmt"
+	"image"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+type (
+	// ScaleMode : How the logical screen is fitted to the window
+	ScaleMode int
+	// Viewport : Where the logical screen is drawn in the window. Scenes draw at the logical size
+	//	from Config, and the game scales that up to the window, letterboxing what's left over.
+	Viewport struct {
+		// Logical : The screen size scenes draw at
+		Logical image.Point
+		// Outside : The window size
+		Outside image.Point
+		Mode    ScaleMode
+	}
+)
+
+const (
+	// ScaleInteger : Scale by the largest whole number that fits, so every pixel is the same size.
+	//	Falls back to ScaleAspect if the window is smaller than the logical screen.
+	ScaleInteger ScaleMode = iota
+	// ScaleAspect : Scale as large as fits, keeping the shape of the screen
+	ScaleAspect
+	// ScaleStretch : Fill the window, stretching the screen to its shape
+	ScaleStretch
+)
+
+var scaleModeNames = map[ScaleMode]string{
+	ScaleInteger: "Pixel Perfect",
+	ScaleAspect:  "Fit",
+	ScaleStretch: "Stretch",
+}
+
+func (m ScaleMode) String() string {
+	if name, ok := scaleModeNames[m]; ok {
+		return name
+	}
+	return fmt.Sprintf("ScaleMode(%d)", int(m))
+}
+
+// Scale : How much the logical screen is scaled by on each axis
+func (v Viewport) Scale() (x, y float64) {
+	if v.Logical.X <= 0 || v.Logical.Y <= 0 {
+		return 1, 1
+	}
+	x = float64(v.Outside.X) / float64(v.Logical.X)
+	y = float64(v.Outside.Y) / float64(v.Logical.Y)
+	if v.Mode == ScaleStretch {
+		return x, y
+	}
+	s := math.Min(x, y)
+	if v.Mode == ScaleInteger && s >= 1 {
+		s = math.Floor(s)
+	}
+	return s, s
+}
+
+// Offset : Where the top-left of the logical screen is in the window. The letterbox is the rest.
+func (v Viewport) Offset() (x, y float64) {
+	sx, sy := v.Scale()
+	return (float64(v.Outside.X) - float64(v.Logical.X)*sx) / 2,
+		(float64(v.Outside.Y) - float64(v.Logical.Y)*sy) / 2
+}
+
+// GeoM : The logical screen to window transform
+func (v Viewport) GeoM() ebiten.GeoM {
+	g := ebiten.GeoM{}
+	g.Scale(v.Scale())
+	g.Translate(v.Offset())
+	return g
+}
+
+// Filter : Whole number scales stay sharp with nearest neighbour filtering. Anything else
+// smooths, so pixels don't come out uneven.
+func (v Viewport) Filter() ebiten.Filter {
+	sx, sy := v.Scale()
+	if sx == math.Floor(sx) && sy == math.Floor(sy) {
+		return ebiten.FilterNearest
+	}
+	return ebiten.FilterLinear
+}
+
+// ToLogical : Map a window position, as in the cursor's, to the logical screen. ok is false if
+// it's in the letterbox.
+func (v Viewport) ToLogical(x, y int) (lx, ly int, ok bool) {
+	sx, sy := v.Scale()
+	ox, oy := v.Offset()
+	lx = int(math.Floor((float64(x) - ox) / sx))
+	ly = int(math.Floor((float64(y) - oy) / sy))
+	return lx, ly, image.Pt(lx, ly).In(image.Rectangle{Max: v.Logical})
+}
@@ -0,0 +1,64 @@
+package engine_test
+
+import (
+	"image"
+	"testing"
+
+	"github.com/jessdwitch/spiders/engine"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestViewportScale(t *testing.T) {
+	logical := image.Pt(640, 480)
+	for _, tc := range []struct {
+		name             string
+		mode             engine.ScaleMode
+		outside          image.Point
+		scaleX, scaleY   float64
+		offsetX, offsetY float64
+	}{
+		{"integer exact", engine.ScaleInteger, image.Pt(1280, 960), 2, 2, 0, 0},
+		{"integer letterboxed", engine.ScaleInteger, image.Pt(1400, 1000), 2, 2, 60, 20},
+		{"integer smaller than logical", engine.ScaleInteger, image.Pt(320, 240), 0.5, 0.5, 0, 0},
+		{"aspect", engine.ScaleAspect, image.Pt(1400, 1000),
+			1000.0 / 480, 1000.0 / 480, (1400 - 640*1000.0/480) / 2, 0},
+		{"aspect pillarboxed", engine.ScaleAspect, image.Pt(1280, 480), 1, 1, 320, 0},
+		{"stretch", engine.ScaleStretch, image.Pt(1280, 480), 2, 1, 0, 0},
+	} {
+		v := engine.Viewport{Logical: logical, Outside: tc.outside, Mode: tc.mode}
+		sx, sy := v.Scale()
+		assert.InDelta(t, tc.scaleX, sx, 1e-9, tc.name)
+		assert.InDelta(t, tc.scaleY, sy, 1e-9, tc.name)
+		ox, oy := v.Offset()
+		assert.InDelta(t, tc.offsetX, ox, 1e-9, tc.name)
+		assert.InDelta(t, tc.offsetY, oy, 1e-9, tc.name)
+	}
+
+	// Before the first layout, there's no logical size to scale
+	sx, sy := engine.Viewport{Outside: image.Pt(800, 600)}.Scale()
+	assert.Equal(t, []float64{1, 1}, []float64{sx, sy})
+}
+
+func TestViewportToLogical(t *testing.T) {
+	// Scaled by 2, with 60px bars on the sides and 20px bars top and bottom
+	v := engine.Viewport{
+		Logical: image.Pt(640, 480), Outside: image.Pt(1400, 1000), Mode: engine.ScaleInteger,
+	}
+	for _, tc := range []struct {
+		x, y   int
+		lx, ly int
+		ok     bool
+	}{
+		{60, 20, 0, 0, true},
+		{700, 500, 320, 240, true},
+		{1339, 979, 639, 479, true},
+		{59, 20, -1, 0, false},
+		{1340, 500, 640, 240, false},
+		{700, 19, 320, -1, false},
+	} {
+		lx, ly, ok := v.ToLogical(tc.x, tc.y)
+		assert.Equal(t, []int{tc.lx, tc.ly}, []int{lx, ly}, "(%d, %d)", tc.x, tc.y)
+		assert.Equal(t, tc.ok, ok, "(%d, %d)", tc.x, tc.y)
+	}
+}
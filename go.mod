@@ -1,9 +1,10 @@
 module github.com/jessdwitch/spiders
 
-go 1.15
+go 1.16
 
 require (
 	github.com/hajimehoshi/ebiten v1.12.3 // indirect
 	github.com/hajimehoshi/ebiten/v2 v2.0.0
 	github.com/stretchr/testify v1.6.1
+	golang.org/x/image v0.0.0-20200927104501-e162460cd6b5
 )
@@ -1,9 +1,26 @@
 package main
 
 import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"image/color"
+	"io/fs"
 	"log"
+	"path"
+	"strings"
 
+	"github.com/jessdwitch/spiders/battle"
+	"github.com/jessdwitch/spiders/content"
+	"github.com/jessdwitch/spiders/deck"
+	"github.com/jessdwitch/spiders/deckedit"
 	"github.com/jessdwitch/spiders/demo"
+	"github.com/jessdwitch/spiders/engine"
+	"github.com/jessdwitch/spiders/engine/assets"
+	"github.com/jessdwitch/spiders/engine/audio"
+	"github.com/jessdwitch/spiders/engine/render"
+	"github.com/jessdwitch/spiders/options"
+	"github.com/jessdwitch/spiders/title"
 
 	"github.com/hajimehoshi/ebiten/v2"
 
@@ -11,14 +28,216 @@ import (
 )
 
 const (
-	screenWidth  = 640
-	screenHeight = 480
+	sampleRate     = 44100
+	sheetManifest  = "sprite/sheets.csv"
+	atlasManifest  = "sprite/atlas.csv"
+	spriteManifest = "sprite/sprites.csv"
+	// asepriteExports : Sprites exported straight from Aseprite, with their sheet images alongside
+	asepriteExports = "img/aseprite/*.json"
+	emitterManifest = "particles/emitters.csv"
+	cardManifest    = "cards/data.csv"
+	pawnManifest    = "battle/pawns.csv"
+)
+
+var (
+	// openingEnemies : The AI pawns in the first battle
+	openingEnemies = []int{0, 1}
+	// battleBackground : Fills the battlefield until there's background art
+	battleBackground = color.RGBA{0x3a, 0x44, 0x66, 0xff}
 )
 
 func main() {
-	ebiten.SetWindowSize(screenWidth, screenHeight)
 	ebiten.SetWindowTitle("Tacocat")
-	if err := ebiten.RunGame(demo.RenderDemo()); err != nil {
+	g, err := newGame()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ebiten.RunGame(g); err != nil && !errors.Is(err, engine.ErrQuit) {
 		log.Fatal(err)
 	}
 }
+
+func newGame() (*engine.Game, error) {
+	g, err := engine.NewGame(nil)
+	if err != nil {
+		return nil, err
+	}
+	modsDir, err := engine.ModsDir()
+	if err != nil {
+		return nil, err
+	}
+	fsys, err := content.WithMods(content.FS(), modsDir)
+	if err != nil {
+		return nil, err
+	}
+	g.GameState.Content = fsys
+	sheets := sheetsFor(fsys)
+	sprites, err := render.NewSpriteFactoryFromManifests(
+		fsys, sheets, spriteManifest, g.GameState.Assets)
+	if err != nil {
+		return nil, err
+	}
+	if err = sprites.AddAseprite(fsys, asepriteExports); err != nil {
+		return nil, err
+	}
+	g.SpriteGetter = sprites
+	if g.GameState.Particles, err = newParticles(fsys, sprites); err != nil {
+		return nil, err
+	}
+	if g.GameState.Cards, err = deck.LoadCardTable(fsys, cardManifest); err != nil {
+		return nil, err
+	}
+	enemies, err := battle.LoadEnemyTable(fsys, pawnManifest)
+	if err != nil {
+		return nil, err
+	}
+	if content.Dev {
+		if err = watchContent(g, sheets, sprites, enemies); err != nil {
+			return nil, err
+		}
+	}
+	if g.GameState.Audio, err = newMixer(fsys, g.GameState.Assets); err != nil {
+		return nil, err
+	}
+	if g.GameState.Shaders, err = render.NewShaderLibrary(); err != nil {
+		// Effects are cosmetic, so the game runs without them. Sprites with palettes keep their
+		// sheet's colors.
+		log.Printf("running without shader effects: %v", err)
+	} else if g.GameState.PostProcess, err = newPostProcess(g.GameState.Shaders); err != nil {
+		return nil, err
+	}
+	sprites.SetShaders(g.GameState.Shaders)
+	if g.GameState.Config, err = engine.LoadConfig(); err != nil {
+		log.Printf("falling back to default settings: %v", err)
+		g.GameState.Config = engine.DefaultConfig()
+	}
+	if err = g.GameState.ApplyConfig(); err != nil {
+		// As in a language whose mod has since been removed
+		log.Printf("falling back to default settings: %v", err)
+		g.GameState.Config = engine.DefaultConfig()
+		if err = g.GameState.ApplyConfig(); err != nil {
+			return nil, err
+		}
+	}
+	languages, err := availableLanguages(fsys)
+	if err != nil {
+		return nil, err
+	}
+	// loading : Preload sprites behind a loading screen, then build the scene that uses them
+	loading := func(ids func() []render.SpriteID, build title.SceneBuilder) title.SceneBuilder {
+		return func(_ *engine.GameState) (engine.Scene, error) {
+			preload := func(progress assets.Progress) <-chan error {
+				return sprites.Preload(ids(), progress)
+			}
+			return engine.NewLoadingScene(build, preload), nil
+		}
+	}
+	var scene *title.TitleScene
+	newGame := loading(func() []render.SpriteID { return enemies.Sprites(openingEnemies) },
+		func(state *engine.GameState) (engine.Scene, error) {
+			c := state.Config
+			background := ebiten.NewImage(c.ScreenWidth, c.ScreenHeight)
+			background.Fill(battleBackground)
+			// Once the battle's over, back to the title
+			return battle.NewBattleScene(
+				state, enemies, sprites, background, openingEnemies, true, state.DeckList, scene)
+		})
+	renderDemo := loading(func() []render.SpriteID { return demo.RenderDemoSprites },
+		func(_ *engine.GameState) (engine.Scene, error) {
+			return demo.NewRenderDemoScene(sprites)
+		})
+	deckEditor := func(state *engine.GameState) (engine.Scene, error) {
+		return deckedit.NewDeckEditScene(state, scene, deck.DefaultRules), nil
+	}
+	optionsMenu := func(state *engine.GameState) (engine.Scene, error) {
+		return options.NewOptionsScene(state, scene, languages), nil
+	}
+	scene, err = title.NewTitleScene(g.GameState, sprites, title.MenuActions{
+		NewGame: newGame,
+		Continue: func(state *engine.GameState) (engine.Scene, error) {
+			// Shown on the title screen, which stays put
+			if err := state.Load(); err != nil {
+				return nil, fmt.Errorf("can't continue: %w", err)
+			}
+			return newGame(state)
+		},
+		Deck:    deckEditor,
+		Options: optionsMenu,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if content.Dev {
+		addConsole(g, map[string]func(*engine.GameState) (engine.Scene, error){
+			"title":   func(*engine.GameState) (engine.Scene, error) { return scene, nil },
+			"deck":    deckEditor,
+			"options": optionsMenu,
+			"demo":    renderDemo,
+			"battle":  newGame,
+		})
+	}
+	g.GameState.SceneManager.GoTo(scene)
+	return g, nil
+}
+
+// sheetsFor : The sheet manifest to load sprites from. A packed atlas, if there is one, is used in
+// place of the loose sheets.
+func sheetsFor(fsys fs.FS) string {
+	if _, err := fs.Stat(fsys, atlasManifest); err == nil {
+		return atlasManifest
+	}
+	return sheetManifest
+}
+
+func newParticles(fsys fs.FS, sprites *render.SpriteFactory) (*render.ParticleFactory, error) {
+	f, err := fsys.Open(emitterManifest)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	emitters, err := render.NewEmitterMetaManager(csv.NewReader(f))
+	if err != nil {
+		return nil, err
+	}
+	return sprites.Particles(emitters), nil
+}
+
+func newMixer(fsys fs.FS, cache *assets.Manager) (*audio.Mixer, error) {
+	f, err := fsys.Open("audio/audio.csv")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	manifest, err := audio.NewManifest(csv.NewReader(f))
+	if err != nil {
+		return nil, err
+	}
+	return audio.NewMixer(audio.NewEbitenBackend(fsys, sampleRate, cache), manifest), nil
+}
+
+// availableLanguages : Every language with a copy file in text/
+func availableLanguages(fsys fs.FS) ([]string, error) {
+	files, err := fs.Glob(fsys, "text/*.txt")
+	if err != nil {
+		return nil, err
+	}
+	result := make([]string, len(files))
+	for i, f := range files {
+		result[i] = strings.TrimSuffix(path.Base(f), ".txt")
+	}
+	return result, nil
+}
+
+// newPostProcess : The screen effects settings can turn on, in the order they're applied
+func newPostProcess(shaders render.ShaderLibrary) (*render.PostProcess, error) {
+	p := render.NewPostProcess()
+	for _, id := range []render.ShaderID{render.ShaderVignette, render.ShaderCRT} {
+		e, err := shaders.NewEffect(id)
+		if err != nil {
+			return nil, err
+		}
+		e.SetEnabled(false)
+		p.Add(e)
+	}
+	return p, nil
+}
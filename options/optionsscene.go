@@ -0,0 +1,262 @@
+package options
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+
+	"github.com/jessdwitch/spiders/engine"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font/basicfont"
+)
+
+const (
+	maxWindowScale = 4
+	volumeStep     = 0.1
+	menuLeft       = 80
+	menuTop        = 60
+	menuLineSpace  = 18
+	valueColumn    = 320
+)
+
+var (
+	labelColor  color.Color = color.White
+	cursorColor color.Color = color.RGBA{0xf0, 0xb1, 0xb1, 0xff}
+	errorColor  color.Color = color.RGBA{0xff, 0x40, 0x40, 0xff}
+)
+
+type (
+	// OptionsScene : Edit the user settings. Changes apply as they're made, and are saved on exit.
+	OptionsScene struct {
+		back      engine.Scene
+		config    *engine.Config
+		languages []string
+		entries   []optionEntry
+		cursor    int
+		// rebinding : Waiting on a key press for the selected binding
+		rebinding bool
+		// lastErr : Shown to the player when a setting can't be applied or saved
+		lastErr error
+		// saveFailed : The last try at leaving couldn't save, so the next leaves without saving
+		saveFailed bool
+	}
+	optionEntry struct {
+		label string
+		value func(c *engine.Config) string
+		// adjust : Step the setting left (-1) or right (+1)
+		adjust func(c *engine.Config, dir int)
+		// binding : If set, confirm starts rebinding this button
+		binding *engine.AbstractButton
+	}
+)
+
+// NewOptionsScene : Make an options menu which returns to back when closed
+func NewOptionsScene(state *engine.GameState, back engine.Scene, languages []string) *OptionsScene {
+	s := &OptionsScene{back: back, config: &state.Config, languages: languages}
+	s.entries = []optionEntry{
+		{
+			label: "Window Scale",
+			value: func(c *engine.Config) string { return fmt.Sprintf("%dx", c.WindowScale) },
+			adjust: func(c *engine.Config, dir int) {
+				c.WindowScale = clampInt(c.WindowScale+dir, 1, maxWindowScale)
+			},
+		},
+		{
+			label: "Scaling",
+			value: func(c *engine.Config) string { return c.ScaleMode.String() },
+			adjust: func(c *engine.Config, dir int) {
+				c.ScaleMode = engine.ScaleMode(clampInt(int(c.ScaleMode)+dir, int(engine.ScaleInteger), int(engine.ScaleStretch)))
+			},
+		},
+		{
+			label:  "Fullscreen",
+			value:  func(c *engine.Config) string { return onOff(c.Fullscreen) },
+			adjust: func(c *engine.Config, _ int) { c.Fullscreen = !c.Fullscreen },
+		},
+		{
+			label:  "VSync",
+			value:  func(c *engine.Config) string { return onOff(c.Vsync) },
+			adjust: func(c *engine.Config, _ int) { c.Vsync = !c.Vsync },
+		},
+		{
+			label:  "Vignette",
+			value:  func(c *engine.Config) string { return onOff(c.Vignette) },
+			adjust: func(c *engine.Config, _ int) { c.Vignette = !c.Vignette },
+		},
+		{
+			label:  "CRT Filter",
+			value:  func(c *engine.Config) string { return onOff(c.CRTFilter) },
+			adjust: func(c *engine.Config, _ int) { c.CRTFilter = !c.CRTFilter },
+		},
+		volumeEntry("Master Volume", func(c *engine.Config) *float64 { return &c.MasterVolume }),
+		volumeEntry("Music Volume", func(c *engine.Config) *float64 { return &c.MusicVolume }),
+		volumeEntry("SFX Volume", func(c *engine.Config) *float64 { return &c.SFXVolume }),
+		{
+			label: "Text Speed",
+			value: func(c *engine.Config) string { return c.TextSpeed.String() },
+			adjust: func(c *engine.Config, dir int) {
+				c.TextSpeed = engine.TextSpeed(clampInt(int(c.TextSpeed)+dir, int(engine.TextSlow), int(engine.TextInstant)))
+			},
+		},
+		{
+			label: "Language",
+			value: func(c *engine.Config) string { return c.Language },
+			adjust: func(c *engine.Config, dir int) {
+				if len(s.languages) == 0 {
+					return
+				}
+				i := 0
+				for j, l := range s.languages {
+					if l == c.Language {
+						i = j
+					}
+				}
+				c.Language = s.languages[(i+dir+len(s.languages))%len(s.languages)]
+			},
+		},
+	}
+	for _, b := range engine.AbstractButtons {
+		b := b
+		s.entries = append(s.entries, optionEntry{
+			label: "Key: " + b.String(),
+			value: func(c *engine.Config) string {
+				return strings.Join(c.KeyBindings[b.String()], ", ")
+			},
+			binding: &b,
+		})
+	}
+	s.entries = append(s.entries, optionEntry{
+		label: "Reset to Defaults",
+		value: func(*engine.Config) string { return "" },
+		adjust: func(c *engine.Config, _ int) {
+			*c = engine.DefaultConfig()
+		},
+	})
+	return s
+}
+
+func volumeEntry(label string, bus func(c *engine.Config) *float64) optionEntry {
+	return optionEntry{
+		label: label,
+		value: func(c *engine.Config) string {
+			return fmt.Sprintf("%d%%", int(*bus(c)*100+0.5))
+		},
+		adjust: func(c *engine.Config, dir int) {
+			v := *bus(c) + float64(dir)*volumeStep
+			if v < 0 {
+				v = 0
+			}
+			if v > 1 {
+				v = 1
+			}
+			*bus(c) = v
+		},
+	}
+}
+
+func onOff(b bool) string {
+	if b {
+		return "On"
+	}
+	return "Off"
+}
+
+func clampInt(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+// change : Apply an edit to the settings, rolling back if it can't be applied
+func (s *OptionsScene) change(state *engine.GameState, edit func(c *engine.Config)) {
+	prev := state.Config
+	// Copy the bindings so an edit can't reach through to prev
+	state.Config.KeyBindings = make(map[string][]string, len(prev.KeyBindings))
+	for k, v := range prev.KeyBindings {
+		state.Config.KeyBindings[k] = v
+	}
+	edit(&state.Config)
+	if s.lastErr = state.ApplyConfig(); s.lastErr != nil {
+		state.Config = prev
+		_ = state.ApplyConfig()
+	}
+}
+
+func (s *OptionsScene) Update(state *engine.GameState) error {
+	entry := s.entries[s.cursor]
+	if s.rebinding {
+		k, ok := state.Input.JustPressedKey()
+		if !ok {
+			return nil
+		}
+		s.rebinding = false
+		s.change(state, func(c *engine.Config) {
+			c.KeyBindings[entry.binding.String()] = []string{k.String()}
+		})
+		return nil
+	}
+	switch {
+	case state.Input.IsJustPressed(engine.ButtonUp):
+		s.cursor = (s.cursor - 1 + len(s.entries)) % len(s.entries)
+		return state.PlaySFX(engine.SFXMove)
+	case state.Input.IsJustPressed(engine.ButtonDown):
+		s.cursor = (s.cursor + 1) % len(s.entries)
+		return state.PlaySFX(engine.SFXMove)
+	case state.Input.IsJustPressed(engine.ButtonLeft) && entry.adjust != nil:
+		s.change(state, func(c *engine.Config) { entry.adjust(c, -1) })
+		return state.PlaySFX(engine.SFXMove)
+	case state.Input.IsJustPressed(engine.ButtonRight) && entry.adjust != nil:
+		s.change(state, func(c *engine.Config) { entry.adjust(c, 1) })
+		return state.PlaySFX(engine.SFXMove)
+	case state.Input.IsJustPressed(engine.ButtonConfirm):
+		if entry.binding != nil {
+			s.rebinding = true
+		} else if entry.adjust != nil {
+			s.change(state, func(c *engine.Config) { entry.adjust(c, 1) })
+		}
+		return state.PlaySFX(engine.SFXConfirm)
+	case state.Input.IsJustPressed(engine.ButtonCancel), state.Input.IsJustPressed(engine.ButtonMenu):
+		if err := state.PlaySFX(engine.SFXCancel); err != nil {
+			return err
+		}
+		if err := state.Config.Save(); err != nil && !s.saveFailed {
+			s.lastErr = fmt.Errorf("settings not saved (back again to leave anyway): %w", err)
+			s.saveFailed = true
+			return nil
+		}
+		s.saveFailed = false
+		state.SceneManager.GoTo(s.back)
+	}
+	return nil
+}
+
+func (s *OptionsScene) Draw(r *ebiten.Image) {
+	face := basicfont.Face7x13
+	text.Draw(r, "Options", face, menuLeft, menuTop-menuLineSpace, labelColor)
+	for i, e := range s.entries {
+		y := menuTop + (i+1)*menuLineSpace
+		clr := labelColor
+		if i == s.cursor {
+			clr = cursorColor
+			text.Draw(r, ">", face, menuLeft-2*face.Advance, y, cursorColor)
+		}
+		text.Draw(r, e.label, face, menuLeft, y, clr)
+		value := ""
+		if i == s.cursor && s.rebinding {
+			value = "press a key..."
+		} else {
+			value = e.value(s.config)
+		}
+		text.Draw(r, value, face, valueColumn, y, clr)
+	}
+	if s.lastErr != nil {
+		y := menuTop + (len(s.entries)+2)*menuLineSpace
+		text.Draw(r, s.lastErr.Error(), face, menuLeft, y, errorColor)
+	}
+}
@@ -1,23 +1,216 @@
 package title
 
 import (
+	"errors"
+	"image/color"
+
 	"github.com/jessdwitch/spiders/engine"
+	"github.com/jessdwitch/spiders/engine/audio"
+	"github.com/jessdwitch/spiders/engine/render"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font/basicfont"
+)
+
+const (
+	// LogoSpriteID : The sprite shown above the title menu
+	LogoSpriteID render.SpriteID = "title_logo"
+	// MusicID : Background music for the title screen
+	MusicID audio.ClipID = "title_theme"
+)
+
+const (
+	logoScale = 4
+	// logoDim : Width and height of the logo sprite's frames, before scaling
+	logoDim       = 32
+	logoTop       = 60
+	menuLineSpace = 20
+	// blinkPeriod : Ticks per on/off cycle of the cursor
+	blinkPeriod = 40
+	// continueEntry : Where Continue sits in the menu
+	continueEntry = 1
+)
+
+var (
+	menuColor         color.Color = color.White
+	menuDisabledColor color.Color = color.Gray{Y: 0x60}
+	menuCursorColor   color.Color = color.RGBA{0xf0, 0xb1, 0xb1, 0xff}
+	errorColor        color.Color = color.RGBA{0xff, 0x40, 0x40, 0xff}
+)
+
+type (
+	// TitleScene : The first thing the player sees. Shows the logo and the main menu. The zero
+	//	value is an empty screen, for standing in as a scene to go back to.
+	TitleScene struct {
+		logo    render.Sprite
+		entries []menuEntry
+		cursor  int
+		count   int
+		// lastErr : Shown to the player when a menu entry can't be followed, as in a bad save
+		lastErr error
+	}
+	// SceneBuilder : Build the scene a menu entry leads to
+	SceneBuilder func(state *engine.GameState) (engine.Scene, error)
+	// MenuActions : Where each of the title menu entries lead
+	MenuActions struct {
+		NewGame  SceneBuilder
+		Continue SceneBuilder
+		// Deck : The deck editor
+		Deck    SceneBuilder
+		Options SceneBuilder
+	}
+	menuEntry struct {
+		// textID : The label's copy, with label as the fallback
+		textID string
+		label  string
+		// text : The label in the current language
+		text    string
+		enabled bool
+		// choose : Returns the scene to go to, or nil to stay put
+		choose SceneBuilder
+	}
 )
 
-type TitleScene struct {
-	count int
+// NewTitleScene : Make a title screen. Continue is only available if a save exists
+func NewTitleScene(
+	state *engine.GameState,
+	sprites render.SpriteGetter,
+	actions MenuActions,
+) (*TitleScene, error) {
+	logo, err := sprites.GetSprite(LogoSpriteID)
+	if err != nil {
+		return nil, err
+	}
+	logo.Scale(logoScale, logoScale)
+	logo.Translate(float64(state.Config.ScreenWidth-logoDim*logoScale)/2, logoTop)
+	if _, err = logo.Animate("idle"); err != nil {
+		return nil, err
+	}
+	s := &TitleScene{
+		logo: logo,
+		entries: []menuEntry{
+			{textID: "title.new_game", label: "New Game", enabled: actions.NewGame != nil, choose: actions.NewGame},
+			{textID: "title.continue", label: "Continue", choose: actions.Continue},
+			{textID: "title.deck", label: "Deck", enabled: actions.Deck != nil, choose: actions.Deck},
+			{textID: "title.options", label: "Options", enabled: actions.Options != nil, choose: actions.Options},
+			{textID: "title.quit", label: "Quit", enabled: true, choose: quit},
+		},
+	}
+	s.checkSave()
+	return s, nil
+}
+
+func quit(_ *engine.GameState) (engine.Scene, error) {
+	return nil, engine.ErrQuit
 }
 
 func anyGamepadAbstractButtonPressed(i *engine.Input) bool {
-	return false // TODO
+	return i.AnyJustPressed()
+}
+
+// checkSave : Only offer Continue while there's a save. One can be made while the title is
+// left for another scene, as in the deck editor, so this is checked again every update.
+func (s *TitleScene) checkSave() {
+	e := &s.entries[continueEntry]
+	e.enabled = e.choose != nil && engine.HasSave()
+	if !s.entries[s.cursor].enabled {
+		s.moveCursor(1)
+	}
+}
+
+// moveCursor : Step the cursor by dir, skipping disabled entries and wrapping around
+func (s *TitleScene) moveCursor(dir int) {
+	for range s.entries {
+		s.cursor = (s.cursor + dir + len(s.entries)) % len(s.entries)
+		if s.entries[s.cursor].enabled {
+			return
+		}
+	}
 }
 
 func (s *TitleScene) Update(state *engine.GameState) error {
-	return nil // TODO
+	s.count++
+	if s.logo != nil {
+		if err := s.logo.Update(); err != nil {
+			return err
+		}
+	}
+	if len(s.entries) == 0 {
+		return nil
+	}
+	// Picks up a change of language in the options
+	for i := range s.entries {
+		s.entries[i].text = state.Text.Get(s.entries[i].textID, s.entries[i].label)
+	}
+	s.checkSave()
+	if !anyGamepadAbstractButtonPressed(state.Input) {
+		return nil
+	}
+	switch {
+	case state.Input.IsJustPressed(engine.ButtonUp):
+		s.moveCursor(-1)
+		s.count = 0
+		return state.PlaySFX(engine.SFXMove)
+	case state.Input.IsJustPressed(engine.ButtonDown):
+		s.moveCursor(1)
+		s.count = 0
+		return state.PlaySFX(engine.SFXMove)
+	case state.Input.IsJustPressed(engine.ButtonConfirm):
+		entry := s.entries[s.cursor]
+		if !entry.enabled {
+			return nil
+		}
+		if err := state.PlaySFX(engine.SFXConfirm); err != nil {
+			return err
+		}
+		next, err := entry.choose(state)
+		if errors.Is(err, engine.ErrQuit) {
+			return err
+		}
+		if s.lastErr = err; err != nil {
+			return nil
+		}
+		if next != nil {
+			state.SceneManager.GoTo(next)
+		}
+	}
+	return nil
+}
+
+// Music : Title theme
+func (s *TitleScene) Music() audio.ClipID {
+	return MusicID
 }
 
 func (s *TitleScene) Draw(r *ebiten.Image) {
+	w, h := r.Size()
+	face := basicfont.Face7x13
+
+	if s.logo != nil {
+		s.logo.Draw(r)
+	}
 
+	menuTop := h / 2
+	for i, e := range s.entries {
+		clr := menuColor
+		if !e.enabled {
+			clr = menuDisabledColor
+		}
+		label := e.text
+		if label == "" {
+			label = e.label
+		}
+		x := w/2 - len(label)*face.Advance/2
+		y := menuTop + i*menuLineSpace
+		text.Draw(r, label, face, x, y, clr)
+		if i == s.cursor && s.count%blinkPeriod < blinkPeriod/2 {
+			text.Draw(r, ">", face, x-2*face.Advance, y, menuCursorColor)
+		}
+	}
+	if s.lastErr != nil {
+		msg := s.lastErr.Error()
+		y := menuTop + (len(s.entries)+1)*menuLineSpace
+		text.Draw(r, msg, face, w/2-len(msg)*face.Advance/2, y, errorColor)
+	}
 }